@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rokoss21/ububu/internal/modules"
+)
+
+// boot - состояние просмотра скорости загрузки
+type boot struct {
+	report      modules.BootReport
+	suggestions map[string]modules.BootSuggestion
+	chain       map[string]bool
+	// deltas - заметные изменения времени запуска с прошлой загрузки
+	deltas  map[string]time.Duration
+	cursor  int
+	loading bool
+	message string
+}
+
+type bootAnalyzedMsg struct {
+	report modules.BootReport
+	err    error
+}
+
+func analyzeBoot() tea.Msg {
+	report, err := modules.AnalyzeBoot()
+	return bootAnalyzedMsg{report: report, err: err}
+}
+
+func (m model) openBoot() (tea.Model, tea.Cmd) {
+	m.boot = boot{loading: true}
+	m.phase = "boot"
+	return m, analyzeBoot
+}
+
+func (b *boot) handleAnalyzed(msg bootAnalyzedMsg) {
+	b.loading = false
+	if msg.err != nil {
+		b.message = fmt.Sprintf("❌ %v", msg.err)
+		return
+	}
+	b.report = msg.report
+	b.suggestions = make(map[string]modules.BootSuggestion)
+	for _, suggestion := range msg.report.Suggestions() {
+		b.suggestions[suggestion.Unit] = suggestion
+	}
+	b.chain = make(map[string]bool)
+	for _, link := range msg.report.Chain {
+		b.chain[link.Unit] = true
+	}
+	b.deltas = make(map[string]time.Duration)
+	for _, change := range msg.report.Changes() {
+		b.deltas[change.Unit] = change.Delta()
+	}
+	if msg.report.Previous == nil {
+		b.message = "First measurement saved, the next run will compare against it"
+	}
+}
+
+func (m model) updateBoot(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := &m.boot
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc":
+		m.phase = "select"
+		return m, nil
+	}
+
+	if b.loading {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if b.cursor > 0 {
+			b.cursor--
+		}
+	case "down", "j":
+		if b.cursor < len(b.report.Blame)-1 {
+			b.cursor++
+		}
+	}
+
+	return m, nil
+}
+
+func (m model) renderBoot() string {
+	state := m.boot
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("🚀 Boot Performance") + "\n\n")
+
+	switch {
+	case state.loading:
+		b.WriteString(fmt.Sprintf("%s Running systemd-analyze...\n", m.spinner.View()))
+	case len(state.report.Blame) > 0:
+		b.WriteString(state.report.Summary() + "\n")
+		b.WriteString(logStyle.Render(fmt.Sprintf("  %d units suggested to disable or delay • ⛓ on the critical chain",
+			len(state.suggestions))) + "\n\n")
+
+		// Юниты от самых медленных, у выбранного под ним рекомендация
+		var rows []string
+		cursorRow := 0
+		for i, unit := range state.report.Blame {
+			cursor := " "
+			if i == state.cursor {
+				cursor = ">"
+				cursorRow = len(rows)
+			}
+			marks := ""
+			if state.chain[unit.Unit] {
+				marks += " ⛓"
+			}
+			if _, ok := state.suggestions[unit.Unit]; ok {
+				marks += " 💡"
+			}
+			row := fmt.Sprintf("%s %8s  %s%s", cursor, unit.Duration.Round(10*time.Millisecond), unit.Unit, marks)
+			if delta, ok := state.deltas[unit.Unit]; ok {
+				row += logStyle.Render(fmt.Sprintf("  %+.1fs vs previous", delta.Seconds()))
+			}
+			rows = append(rows, row)
+			if i == state.cursor {
+				if suggestion, ok := state.suggestions[unit.Unit]; ok {
+					rows = append(rows, logStyle.Render(fmt.Sprintf("    💡 %s: %s", suggestion.Action, suggestion.Reason)))
+				}
+			}
+		}
+
+		start, end := visibleRange(cursorRow, len(rows), m.explorerHeight()-3)
+		for _, row := range rows[start:end] {
+			b.WriteString(row + "\n")
+		}
+	}
+
+	if state.message != "" {
+		b.WriteString("\n" + logStyle.Render(state.message) + "\n")
+	}
+
+	b.WriteString("\n" + headerStyle.Render("Controls:") + " ↑/↓ Navigate • q Back\n")
+
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rokoss21/ububu/internal/modules"
+)
+
+// Минимальный размер файлов, которые сравниваются в интерактивном поиске
+const duplicatesMinSize = 1024 * 1024
+
+// duplicates - состояние интерактивного просмотра дубликатов
+type duplicates struct {
+	groups []modules.DuplicateGroup
+	// keep - какую копию оставить в каждой группе
+	keep []string
+	// group и index - позиция курсора: группа и файл в ней
+	group   int
+	index   int
+	loading bool
+	// resolving - группа обрабатывается, индексы групп пока менять нельзя
+	resolving bool
+	cancel    context.CancelFunc
+	message   string
+}
+
+type duplicatesFoundMsg struct {
+	groups []modules.DuplicateGroup
+	err    error
+}
+
+type duplicatesResolvedMsg struct {
+	group int
+	freed int64
+	err   error
+}
+
+func (m model) openDuplicates() (tea.Model, tea.Cmd) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		m.addLog("ERROR", fmt.Sprintf("Cannot find home directory: %v", err))
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.duplicates = duplicates{loading: true, cancel: cancel}
+	m.phase = "duplicates"
+
+	return m, func() tea.Msg {
+		groups, err := modules.FindDuplicates(ctx, []string{homeDir}, duplicatesMinSize)
+		return duplicatesFoundMsg{groups: groups, err: err}
+	}
+}
+
+func (d *duplicates) handleFound(msg duplicatesFoundMsg) {
+	if msg.err == context.Canceled {
+		return
+	}
+	d.loading = false
+	if msg.err != nil {
+		d.message = fmt.Sprintf("❌ %v", msg.err)
+		return
+	}
+	d.groups = msg.groups
+	d.keep = make([]string, len(msg.groups))
+	for i, group := range msg.groups {
+		d.keep[i] = modules.OldestCopy(group)
+	}
+}
+
+func (d *duplicates) handleResolved(msg duplicatesResolvedMsg) {
+	// Просмотр могли закрыть и открыть заново, пока группа обрабатывалась
+	if !d.resolving {
+		return
+	}
+	d.resolving = false
+	d.message = fmt.Sprintf("🗑️  %s moved to quarantine", formatSize(msg.freed))
+	if msg.err != nil {
+		d.message += fmt.Sprintf(" • %v", msg.err)
+	}
+
+	// Группа обработана, убираем ее из списка
+	d.groups = append(d.groups[:msg.group], d.groups[msg.group+1:]...)
+	d.keep = append(d.keep[:msg.group], d.keep[msg.group+1:]...)
+	if d.group >= len(d.groups) && d.group > 0 {
+		d.group--
+	}
+	d.index = 0
+}
+
+func (m model) updateDuplicates(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	d := &m.duplicates
+
+	switch msg.String() {
+	case "ctrl+c":
+		if d.cancel != nil {
+			d.cancel()
+		}
+		return m, tea.Quit
+	case "q", "esc":
+		if d.cancel != nil {
+			d.cancel()
+		}
+		m.phase = "select"
+		return m, nil
+	}
+
+	if d.loading || d.resolving || len(d.groups) == 0 {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if d.index > 0 {
+			d.index--
+		} else if d.group > 0 {
+			d.group--
+			d.index = len(d.groups[d.group].Paths) - 1
+		}
+	case "down", "j":
+		if d.index < len(d.groups[d.group].Paths)-1 {
+			d.index++
+		} else if d.group < len(d.groups)-1 {
+			d.group++
+			d.index = 0
+		}
+	case " ":
+		d.keep[d.group] = d.groups[d.group].Paths[d.index]
+	case "x", "L":
+		quarantine, err := modules.DefaultQuarantine()
+		if err != nil {
+			d.message = fmt.Sprintf("❌ %v", err)
+			return m, nil
+		}
+		index, group, keep := d.group, d.groups[d.group], d.keep[d.group]
+		hardlink := msg.String() == "L"
+		d.resolving = true
+		d.message = "Resolving duplicates..."
+		return m, func() tea.Msg {
+			freed, err := modules.ResolveDuplicates(quarantine, group, keep, hardlink)
+			return duplicatesResolvedMsg{group: index, freed: freed, err: err}
+		}
+	}
+
+	return m, nil
+}
+
+func (m model) renderDuplicates() string {
+	d := m.duplicates
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("👯 Duplicate Files") + "\n\n")
+
+	switch {
+	case d.loading:
+		b.WriteString(fmt.Sprintf("%s Comparing files in your home directory...\n", m.spinner.View()))
+	case len(d.groups) == 0:
+		b.WriteString(logStyle.Render("  No duplicates found") + "\n")
+	default:
+		var wasted int64
+		for _, group := range d.groups {
+			wasted += group.Wasted()
+		}
+		b.WriteString(fmt.Sprintf("%d groups • %s wasted\n\n", len(d.groups), formatSize(wasted)))
+
+		// Строки всех групп, курсор ищется по номеру строки
+		var rows []string
+		cursorRow := 0
+		for g, group := range d.groups {
+			rows = append(rows, headerStyle.Render(fmt.Sprintf("  %d × %s (%s wasted)",
+				len(group.Paths), formatSize(group.Size), formatSize(group.Wasted()))))
+			for i, path := range group.Paths {
+				cursor := " "
+				if g == d.group && i == d.index {
+					cursor = ">"
+					cursorRow = len(rows)
+				}
+				action := "  remove"
+				if path == d.keep[g] {
+					action = "✔ keep  "
+				}
+				rows = append(rows, fmt.Sprintf("%s   %s %s", cursor, action, path))
+			}
+		}
+
+		start, end := visibleRange(cursorRow, len(rows), m.explorerHeight())
+		for _, row := range rows[start:end] {
+			b.WriteString(row + "\n")
+		}
+	}
+
+	if d.message != "" {
+		b.WriteString("\n" + logStyle.Render(d.message) + "\n")
+	}
+
+	b.WriteString("\n" + headerStyle.Render("Controls:") +
+		" ↑/↓ Navigate • Space Keep this copy • x Quarantine others • L Hardlink others • q Back\n")
+
+	return b.String()
+}
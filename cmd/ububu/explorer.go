@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rokoss21/ububu/internal/modules"
+)
+
+// Вкладки обозревателя диска
+const (
+	explorerDirs  = "dirs"
+	explorerFiles = "files"
+	explorerTypes = "types"
+)
+
+// Сколько крупнейших файлов показывать
+const explorerTopFiles = 50
+
+// explorer - состояние интерактивного обозревателя диска
+type explorer struct {
+	scanner    *modules.Scanner
+	quarantine *modules.Quarantine
+
+	path    string
+	view    string
+	cursor  int
+	entries []modules.ExplorerEntry
+	stats   *modules.FileStats
+	// statsPath - для какого каталога посчитаны stats
+	statsPath string
+
+	marked map[string]modules.ExplorerEntry
+	// listing и collecting - идет сканирование каталога или сбор статистики по файлам
+	listing    bool
+	collecting bool
+	scanned    *atomic.Int64
+	cancel     context.CancelFunc
+	message    string
+}
+
+type explorerListMsg struct {
+	path    string
+	entries []modules.ExplorerEntry
+	err     error
+}
+
+type explorerStatsMsg struct {
+	path  string
+	stats modules.FileStats
+	err   error
+}
+
+type explorerQuarantineMsg struct {
+	moved  int
+	freed  int64
+	errors []string
+}
+
+func newExplorer() explorer {
+	scanned := &atomic.Int64{}
+	scanner := modules.NewScanner()
+	scanner.OnProgress = func(progress modules.ScanProgress) {
+		scanned.Store(progress.Files)
+	}
+
+	path, err := os.UserHomeDir()
+	if err != nil {
+		path = "/"
+	}
+
+	e := explorer{
+		scanner: scanner,
+		path:    path,
+		view:    explorerDirs,
+		marked:  make(map[string]modules.ExplorerEntry),
+		scanned: scanned,
+	}
+	if quarantine, err := modules.DefaultQuarantine(); err == nil {
+		e.quarantine = quarantine
+	}
+	return e
+}
+
+func (m model) openExplorer() (tea.Model, tea.Cmd) {
+	if m.explorer.scanner == nil {
+		m.explorer = newExplorer()
+	}
+	m.phase = "explore"
+	return m, m.explorer.load()
+}
+
+// load начинает асинхронное сканирование текущего каталога,
+// отменяя предыдущее, если оно еще идет
+func (e *explorer) load() tea.Cmd {
+	ctx := e.restartScan()
+	scanner, path := e.scanner, e.path
+
+	e.listing = true
+	listCmd := func() tea.Msg {
+		entries, err := modules.ListDir(ctx, scanner, path)
+		return explorerListMsg{path: path, entries: entries, err: err}
+	}
+	if e.view == explorerDirs || e.statsPath == path {
+		return listCmd
+	}
+	return tea.Batch(listCmd, e.loadStats(ctx))
+}
+
+func (e *explorer) loadStats(ctx context.Context) tea.Cmd {
+	path := e.path
+	e.collecting = true
+	return func() tea.Msg {
+		stats, err := modules.CollectFileStats(ctx, path, explorerTopFiles)
+		return explorerStatsMsg{path: path, stats: stats, err: err}
+	}
+}
+
+func (e *explorer) restartScan() context.Context {
+	e.stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.scanned.Store(0)
+	return ctx
+}
+
+func (e *explorer) stop() {
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+	e.listing = false
+	e.collecting = false
+}
+
+func (e explorer) loading() bool {
+	return e.listing || e.collecting
+}
+
+func (e *explorer) open(path string) tea.Cmd {
+	e.path = path
+	e.cursor = 0
+	e.message = ""
+	return e.load()
+}
+
+func (m model) updateExplorer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := &m.explorer
+
+	switch msg.String() {
+	case "ctrl+c":
+		e.stop()
+		return m, tea.Quit
+	case "q", "esc":
+		e.stop()
+		m.phase = "select"
+	case "up", "k":
+		if e.cursor > 0 {
+			e.cursor--
+		}
+	case "down", "j":
+		if e.cursor < e.rowCount()-1 {
+			e.cursor++
+		}
+	case "enter", "right", "l":
+		switch e.view {
+		case explorerDirs:
+			if e.cursor < len(e.entries) && e.entries[e.cursor].IsDir {
+				return m, e.open(e.entries[e.cursor].Path)
+			}
+		case explorerFiles:
+			// Переходим в каталог, где лежит файл
+			if e.stats != nil && e.cursor < len(e.stats.Largest) {
+				e.view = explorerDirs
+				return m, e.open(filepath.Dir(e.stats.Largest[e.cursor].Path))
+			}
+		}
+	case "backspace", "left", "h":
+		if parent := filepath.Dir(e.path); parent != e.path {
+			return m, e.open(parent)
+		}
+	case "tab":
+		switch e.view {
+		case explorerDirs:
+			e.view = explorerFiles
+		case explorerFiles:
+			e.view = explorerTypes
+		default:
+			e.view = explorerDirs
+		}
+		e.cursor = 0
+		if e.view != explorerDirs && e.statsPath != e.path {
+			// Список каталога уже в кэше сканера, поэтому перезапуск дешев
+			return m, e.load()
+		}
+	case " ":
+		if entry, ok := e.selected(); ok {
+			if _, marked := e.marked[entry.Path]; marked {
+				delete(e.marked, entry.Path)
+			} else {
+				e.marked[entry.Path] = entry
+			}
+		}
+	case "x":
+		return m, e.quarantineMarked()
+	case "r":
+		e.scanner.Invalidate(e.path)
+		e.statsPath = ""
+		return m, e.load()
+	}
+
+	return m, nil
+}
+
+func (e *explorer) handleList(msg explorerListMsg) {
+	if msg.path != e.path || msg.err == context.Canceled {
+		return
+	}
+	e.listing = false
+	if msg.err != nil {
+		e.message = fmt.Sprintf("❌ %v", msg.err)
+		e.entries = nil
+		return
+	}
+	e.entries = msg.entries
+	if e.cursor >= len(e.entries) {
+		e.cursor = 0
+	}
+}
+
+func (e *explorer) handleStats(msg explorerStatsMsg) {
+	if msg.path != e.path || msg.err == context.Canceled {
+		return
+	}
+	e.collecting = false
+	if msg.err != nil {
+		e.message = fmt.Sprintf("❌ %v", msg.err)
+		return
+	}
+	e.stats = &msg.stats
+	e.statsPath = msg.path
+}
+
+// selected возвращает элемент под курсором, который можно отметить
+func (e *explorer) selected() (modules.ExplorerEntry, bool) {
+	switch e.view {
+	case explorerDirs:
+		if e.cursor < len(e.entries) {
+			return e.entries[e.cursor], true
+		}
+	case explorerFiles:
+		if e.stats != nil && e.cursor < len(e.stats.Largest) {
+			return e.stats.Largest[e.cursor], true
+		}
+	}
+	return modules.ExplorerEntry{}, false
+}
+
+func (e *explorer) rowCount() int {
+	switch e.view {
+	case explorerDirs:
+		return len(e.entries)
+	case explorerFiles:
+		if e.stats != nil {
+			return len(e.stats.Largest)
+		}
+	case explorerTypes:
+		if e.stats != nil {
+			return len(e.stats.Types)
+		}
+	}
+	return 0
+}
+
+// quarantineMarked переносит отмеченные элементы в карантин очистки
+func (e *explorer) quarantineMarked() tea.Cmd {
+	if len(e.marked) == 0 {
+		e.message = "Nothing marked. Press Space to mark entries"
+		return nil
+	}
+	if e.quarantine == nil {
+		e.message = "❌ Quarantine is not available"
+		return nil
+	}
+
+	quarantine := e.quarantine
+	var paths []string
+	for path := range e.marked {
+		paths = append(paths, path)
+		e.scanner.Invalidate(path)
+	}
+	sort.Strings(paths)
+
+	// Если отмечен и каталог, и что-то внутри него, переносим только каталог
+	var roots []string
+	for _, path := range paths {
+		if len(roots) == 0 || !strings.HasPrefix(path, roots[len(roots)-1]+string(os.PathSeparator)) {
+			roots = append(roots, path)
+		}
+	}
+	e.marked = make(map[string]modules.ExplorerEntry)
+	e.statsPath = ""
+
+	return func() tea.Msg {
+		var result explorerQuarantineMsg
+		for _, path := range roots {
+			entry, err := quarantine.Add(path)
+			if err != nil {
+				result.errors = append(result.errors, err.Error())
+				continue
+			}
+			result.moved++
+			result.freed += entry.Size
+		}
+		return result
+	}
+}
+
+func (e *explorer) handleQuarantine(msg explorerQuarantineMsg) tea.Cmd {
+	e.message = fmt.Sprintf("🗑️  %d item(s), %s moved to quarantine", msg.moved, formatSize(msg.freed))
+	if len(msg.errors) > 0 {
+		e.message += fmt.Sprintf(" • %d failed: %s", len(msg.errors), msg.errors[0])
+	}
+	return e.load()
+}
+
+func (m model) renderExplorer() string {
+	e := m.explorer
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("💾 Disk Explorer: "+e.path) + "\n")
+
+	tabs := []struct{ view, title string }{
+		{explorerDirs, "Directories"},
+		{explorerFiles, "Largest files"},
+		{explorerTypes, "File types"},
+	}
+	for _, tab := range tabs {
+		if tab.view == e.view {
+			b.WriteString(titleStyle.Render("["+tab.title+"]") + " ")
+		} else {
+			b.WriteString(logStyle.Render(" "+tab.title+" ") + " ")
+		}
+	}
+	b.WriteString("\n\n")
+
+	if e.loading() {
+		b.WriteString(fmt.Sprintf("%s Scanning... %d files\n", m.spinner.View(), e.scanned.Load()))
+	} else {
+		rows := e.rows()
+		if len(rows) == 0 {
+			b.WriteString(logStyle.Render("  (empty)") + "\n")
+		}
+		start, end := visibleRange(e.cursor, len(rows), m.explorerHeight())
+		for i := start; i < end; i++ {
+			cursor := " "
+			if i == e.cursor {
+				cursor = ">"
+			}
+			b.WriteString(cursor + rows[i] + "\n")
+		}
+	}
+
+	if len(e.marked) > 0 {
+		var total int64
+		for _, entry := range e.marked {
+			total += entry.Usage.Allocated
+		}
+		b.WriteString(fmt.Sprintf("\n● %d marked (%s)\n", len(e.marked), formatSize(total)))
+	}
+	if e.message != "" {
+		b.WriteString("\n" + logStyle.Render(e.message) + "\n")
+	}
+
+	b.WriteString("\n" + headerStyle.Render("Controls:") +
+		" ↑/↓ Navigate • Enter Open • ← Up • Tab View • Space Mark • x Quarantine • r Rescan • q Back\n")
+
+	return b.String()
+}
+
+// rows форматирует строки текущей вкладки
+func (e explorer) rows() []string {
+	var rows []string
+
+	switch e.view {
+	case explorerDirs:
+		var largest int64
+		if len(e.entries) > 0 {
+			largest = e.entries[0].Usage.Allocated
+		}
+		for _, entry := range e.entries {
+			name := entry.Name
+			if entry.IsDir {
+				name += "/"
+			}
+			if entry.Mount {
+				name += " (other filesystem)"
+			}
+			rows = append(rows, fmt.Sprintf("%s %9s %s %s",
+				e.markOf(entry.Path), formatSize(entry.Usage.Allocated), sizeBar(entry.Usage.Allocated, largest), name))
+		}
+	case explorerFiles:
+		if e.stats == nil {
+			break
+		}
+		for _, entry := range e.stats.Largest {
+			path := entry.Path
+			if rel, err := filepath.Rel(e.path, path); err == nil {
+				path = rel
+			}
+			rows = append(rows, fmt.Sprintf("%s %9s %s", e.markOf(entry.Path), formatSize(entry.Usage.Allocated), path))
+		}
+	case explorerTypes:
+		if e.stats == nil {
+			break
+		}
+		var largest int64
+		if len(e.stats.Types) > 0 {
+			largest = e.stats.Types[0].Allocated
+		}
+		for _, stat := range e.stats.Types {
+			rows = append(rows, fmt.Sprintf("  %9s %s %-16s %d files",
+				formatSize(stat.Allocated), sizeBar(stat.Allocated, largest), stat.Type, stat.Files))
+		}
+	}
+
+	return rows
+}
+
+func (e explorer) markOf(path string) string {
+	if _, ok := e.marked[path]; ok {
+		return "●"
+	}
+	return " "
+}
+
+// explorerHeight - сколько строк списка помещается на экране
+func (m model) explorerHeight() int {
+	if m.height > 16 {
+		return m.height - 14
+	}
+	return 10
+}
+
+// visibleRange возвращает окно строк, в котором виден курсор
+func visibleRange(cursor, total, height int) (int, int) {
+	if total <= height {
+		return 0, total
+	}
+	start := cursor - height/2
+	if start < 0 {
+		start = 0
+	}
+	if start+height > total {
+		start = total - height
+	}
+	return start, start + height
+}
+
+// sizeBar рисует полосу, пропорциональную доле size от largest
+func sizeBar(size, largest int64) string {
+	const width = 10
+	filled := 0
+	if largest > 0 {
+		filled = int(size * width / largest)
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat(" ", width-filled) + "]"
+}
+
+// formatSize переводит байты в человекочитаемый вид
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
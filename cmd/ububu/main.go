@@ -65,11 +65,16 @@ type model struct {
 	logs          []string
 	width         int
 	height        int
-	phase         string // "select", "running", "complete", "report"
+	phase         string // "select", "preview", "running", "complete", "report", "explore", "duplicates", "units", "boot"
 	totalTasks    int
 	completedTasks int
 	overallProgress float64
 	reportGenerated bool
+	explorer        explorer
+	duplicates      duplicates
+	units           units
+	boot            boot
+	preview         preview
 }
 
 type taskCompleteMsg struct {
@@ -110,6 +115,20 @@ func initialModel() model {
 			Module:      &modules.CleanupModule{},
 			Selected:    true,
 		},
+		{
+			Name:        "Containers",
+			Description: "Docker & Podman cleanup",
+			Icon:        "🐳",
+			Module:      &modules.ContainerModule{},
+			Selected:    false,
+		},
+		{
+			Name:        "Duplicates",
+			Description: "Find duplicate files",
+			Icon:        "👯",
+			Module:      &modules.DuplicatesModule{},
+			Selected:    false,
+		},
 		{
 			Name:        "Updates",
 			Description: "System & security updates",
@@ -187,6 +206,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case " ":
 				m.tasks[m.cursor].Selected = !m.tasks[m.cursor].Selected
 			case "enter":
+				// Перед очисткой показываем, что именно будет удалено
+				if cleanup, containers := m.previewModules(); cleanup != nil || containers != nil {
+					return m.openPreview(cleanup, containers)
+				}
 				return m.startTasks()
 			case "a":
 				for i := range m.tasks {
@@ -196,6 +219,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				for i := range m.tasks {
 					m.tasks[i].Selected = false
 				}
+			case "d":
+				return m.openExplorer()
+			case "f":
+				return m.openDuplicates()
+			case "u":
+				return m.openUnits()
+			case "b":
+				return m.openBoot()
 			}
 		case "running":
 			switch msg.String() {
@@ -216,6 +247,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "ctrl+c", "q", "enter":
 				return m, tea.Quit
 			}
+		case "preview":
+			return m.updatePreview(msg)
+		case "explore":
+			return m.updateExplorer(msg)
+		case "duplicates":
+			return m.updateDuplicates(msg)
+		case "units":
+			return m.updateUnits(msg)
+		case "boot":
+			return m.updateBoot(msg)
 		}
 
 	case spinner.TickMsg:
@@ -273,6 +314,40 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case logMsg:
 		m.addLog(msg.level, msg.message)
 		return m, nil
+
+	case explorerListMsg:
+		m.explorer.handleList(msg)
+		return m, nil
+
+	case explorerStatsMsg:
+		m.explorer.handleStats(msg)
+		return m, nil
+
+	case explorerQuarantineMsg:
+		return m, m.explorer.handleQuarantine(msg)
+
+	case duplicatesFoundMsg:
+		m.duplicates.handleFound(msg)
+		return m, nil
+
+	case duplicatesResolvedMsg:
+		m.duplicates.handleResolved(msg)
+		return m, nil
+
+	case unitsLoadedMsg:
+		m.units.handleLoaded(msg)
+		return m, nil
+
+	case unitRestartedMsg:
+		return m, m.units.handleRestarted(msg)
+
+	case bootAnalyzedMsg:
+		m.boot.handleAnalyzed(msg)
+		return m, nil
+
+	case previewLoadedMsg:
+		m.preview.handleLoaded(msg)
+		return m, nil
 	}
 
 	return m, cmd
@@ -378,12 +453,22 @@ func (m model) View() string {
 	switch m.phase {
 	case "select":
 		b.WriteString(m.renderTaskSelection())
+	case "preview":
+		b.WriteString(m.renderPreview())
 	case "running":
 		b.WriteString(m.renderRunning())
 	case "complete":
 		b.WriteString(m.renderComplete())
 	case "report":
 		b.WriteString(m.renderReport())
+	case "explore":
+		b.WriteString(m.renderExplorer())
+	case "duplicates":
+		b.WriteString(m.renderDuplicates())
+	case "units":
+		b.WriteString(m.renderUnits())
+	case "boot":
+		b.WriteString(m.renderBoot())
 	}
 
 	return b.String()
@@ -417,7 +502,7 @@ func (m model) renderTaskSelection() string {
 	}
 
 	// Компактные инструкции
-	b.WriteString("\n" + headerStyle.Render("Controls:") + " ↑/↓ Navigate • Space Toggle • Enter Start • q Quit\n")
+	b.WriteString("\n" + headerStyle.Render("Controls:") +  " ↑/↓ Navigate • Space Toggle • Enter Start • d Disk Explorer • f Duplicates • u Units • b Boot • q Quit\n")
 
 	return b.String()
 }
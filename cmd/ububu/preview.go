@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rokoss21/ububu/internal/modules"
+)
+
+// preview - что удалят очистка и очистка контейнеров, показывается перед запуском задач
+type preview struct {
+	// cleanup - модуль очистки, nil если задача Cleanup не выбрана
+	cleanup *modules.CleanupModule
+	items   []modules.CleanupItem
+	// purge - пакеты, выбранные для удаления. Остальные пакеты очистка не трогает
+	purge   map[string]bool
+	cursor  int
+	loading bool
+	message string
+	// cancel прерывает подсчет, если просмотр закрыли раньше
+	cancel context.CancelFunc
+}
+
+type previewLoadedMsg struct {
+	items []modules.CleanupItem
+	err   error
+}
+
+// previewModules возвращает модули очистки и контейнеров, если их задачи выбраны
+func (m model) previewModules() (*modules.CleanupModule, *modules.ContainerModule) {
+	var cleanup *modules.CleanupModule
+	var containers *modules.ContainerModule
+	for _, task := range m.tasks {
+		if !task.Selected {
+			continue
+		}
+		switch module := task.Module.(type) {
+		case *modules.CleanupModule:
+			cleanup = module
+		case *modules.ContainerModule:
+			containers = module
+		}
+	}
+	return cleanup, containers
+}
+
+func (m model) openPreview(cleanup *modules.CleanupModule, containers *modules.ContainerModule) (tea.Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.preview = preview{cleanup: cleanup, purge: make(map[string]bool), loading: true, cancel: cancel}
+	if cleanup != nil {
+		for _, name := range cleanup.PurgePackages {
+			m.preview.purge[name] = true
+		}
+	}
+	m.phase = "preview"
+	return m, func() tea.Msg {
+		var items []modules.CleanupItem
+		if cleanup != nil {
+			cleanupItems, err := cleanup.PreviewContext(ctx)
+			if err != nil {
+				return previewLoadedMsg{err: err}
+			}
+			items = cleanupItems
+		}
+		if containers != nil {
+			items = append(items, containerItems(containers.Preview())...)
+		}
+		return previewLoadedMsg{items: items}
+	}
+}
+
+// containerItems переводит объем по сокетам движков в позиции предпросмотра
+func containerItems(usage map[string]modules.ContainerUsage) []modules.CleanupItem {
+	sockets := make([]string, 0, len(usage))
+	for socket := range usage {
+		sockets = append(sockets, socket)
+	}
+	sort.Strings(sockets)
+
+	var items []modules.CleanupItem
+	for _, socket := range sockets {
+		category := "Containers via " + socket
+		for _, part := range []struct {
+			name string
+			size int64
+		}{
+			{"dangling images", usage[socket].Images},
+			{"stopped containers", usage[socket].Containers},
+			{"unused volumes", usage[socket].Volumes},
+			{"build cache", usage[socket].BuildCache},
+		} {
+			if part.size > 0 {
+				items = append(items, modules.CleanupItem{Category: category, Path: part.name, Size: part.size})
+			}
+		}
+	}
+	return items
+}
+
+func (p *preview) handleLoaded(msg previewLoadedMsg) {
+	// Просмотр могли закрыть, пока шел подсчет
+	if !p.loading {
+		return
+	}
+	p.loading = false
+	if msg.err != nil {
+		p.message = fmt.Sprintf("❌ %v", msg.err)
+		return
+	}
+	// Группируем по категориям в порядке их первого появления: у нескольких
+	// пользователей одна категория встречается несколько раз
+	order := make(map[string]int)
+	for _, item := range msg.items {
+		if _, ok := order[item.Category]; !ok {
+			order[item.Category] = len(order)
+		}
+	}
+	p.items = msg.items
+	sort.SliceStable(p.items, func(i, j int) bool {
+		return order[p.items[i].Category] < order[p.items[j].Category]
+	})
+}
+
+func (m model) updatePreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.preview
+
+	switch msg.String() {
+	case "ctrl+c":
+		if p.cancel != nil {
+			p.cancel()
+		}
+		return m, tea.Quit
+	case "q", "esc":
+		if p.cancel != nil {
+			p.cancel()
+		}
+		p.loading = false
+		m.phase = "select"
+		return m, nil
+	}
+
+	if p.loading {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "j":
+		if p.cursor < len(p.items)-1 {
+			p.cursor++
+		}
+	case " ":
+		if len(p.items) == 0 {
+			return m, nil
+		}
+		item := p.items[p.cursor]
+		if !item.Optional {
+			p.message = "Only packages can be deselected, everything else is always cleaned"
+			return m, nil
+		}
+		p.purge[item.Path] = !p.purge[item.Path]
+		p.message = ""
+	case "enter":
+		var names []string
+		for name, selected := range p.purge {
+			if selected {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		if p.cleanup != nil {
+			p.cleanup.PurgePackages = names
+		}
+		return m.startTasks()
+	}
+
+	return m, nil
+}
+
+// shortenPath обрезает начало пути, чтобы строка поместилась в width символов
+func shortenPath(path string, width int) string {
+	runes := []rune(path)
+	if width < 10 || len(runes) <= width {
+		return path
+	}
+	return "…" + string(runes[len(runes)-width+1:])
+}
+
+func (m model) renderPreview() string {
+	p := m.preview
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("🧹 Cleanup Preview") + "\n\n")
+
+	switch {
+	case p.loading:
+		b.WriteString(fmt.Sprintf("%s Estimating what will be removed...\n", m.spinner.View()))
+	case len(p.items) == 0:
+		b.WriteString(logStyle.Render("  Nothing to preview, caches and temporary files are still cleaned") + "\n")
+	default:
+		var total int64
+		for _, item := range p.items {
+			if !item.Optional || p.purge[item.Path] {
+				total += item.Size
+			}
+		}
+		b.WriteString(fmt.Sprintf("%d items • about %s\n\n", len(p.items), formatSize(total)))
+
+		// Над каждой категорией ее итог
+		categories := make(map[string]int64)
+		counts := make(map[string]int)
+		for _, item := range p.items {
+			categories[item.Category] += item.Size
+			counts[item.Category]++
+		}
+
+		pathWidth := 50
+		if m.width > 40 {
+			pathWidth = m.width - 20
+		}
+
+		var rows []string
+		cursorRow := 0
+		for i, item := range p.items {
+			if i == 0 || p.items[i-1].Category != item.Category {
+				rows = append(rows, headerStyle.Render(fmt.Sprintf("  %s: %d × %s",
+					item.Category, counts[item.Category], formatSize(categories[item.Category]))))
+			}
+			cursor := " "
+			if i == p.cursor {
+				cursor = ">"
+				cursorRow = len(rows)
+			}
+			// Пакеты удаляются только по выбору, у остальных позиций флажка нет
+			checkbox := " "
+			if item.Optional {
+				checkbox = "☐"
+				if p.purge[item.Path] {
+					checkbox = "☑"
+				}
+			}
+			rows = append(rows, fmt.Sprintf("%s %s %10s  %s", cursor, checkbox, formatSize(item.Size), shortenPath(item.Path, pathWidth)))
+			if i == p.cursor && item.Program != "" {
+				rows = append(rows, logStyle.Render(fmt.Sprintf("               %s crashed %s",
+					item.Program, item.Modified.Format("2006-01-02 15:04"))))
+			}
+		}
+
+		start, end := visibleRange(cursorRow, len(rows), m.explorerHeight())
+		for _, row := range rows[start:end] {
+			b.WriteString(row + "\n")
+		}
+	}
+
+	if p.message != "" {
+		b.WriteString("\n" + logStyle.Render(p.message) + "\n")
+	}
+
+	b.WriteString("\n" + headerStyle.Render("Controls:") + " ↑/↓ Navigate • Space Select package • Enter Run selected tasks • q Back\n")
+
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rokoss21/ububu/internal/modules"
+)
+
+// units - состояние просмотра проблемных юнитов systemd
+type units struct {
+	problems []modules.UnitProblem
+	cursor   int
+	loading  bool
+	// restarting - юнит перезапускается, список пока не меняется
+	restarting bool
+	message    string
+}
+
+type unitsLoadedMsg struct {
+	problems []modules.UnitProblem
+	err      error
+}
+
+type unitRestartedMsg struct {
+	unit string
+	err  error
+}
+
+func loadUnits() tea.Msg {
+	problems, err := modules.CheckUnits()
+	return unitsLoadedMsg{problems: problems, err: err}
+}
+
+func (m model) openUnits() (tea.Model, tea.Cmd) {
+	m.units = units{loading: true}
+	m.phase = "units"
+	return m, loadUnits
+}
+
+func (u *units) handleLoaded(msg unitsLoadedMsg) {
+	u.loading = false
+	if msg.err != nil {
+		u.message = fmt.Sprintf("❌ %v", msg.err)
+		return
+	}
+	u.problems = msg.problems
+	if u.cursor >= len(u.problems) {
+		u.cursor = 0
+	}
+}
+
+func (u *units) handleRestarted(msg unitRestartedMsg) tea.Cmd {
+	// Просмотр могли закрыть и открыть заново, пока юнит перезапускался
+	if !u.restarting {
+		return nil
+	}
+	u.restarting = false
+	if msg.err != nil {
+		u.message = fmt.Sprintf("❌ Failed to restart %s: %v", msg.unit, msg.err)
+		return nil
+	}
+	u.message = fmt.Sprintf("🔄 %s restarted", msg.unit)
+
+	// Перечитываем состояние: после перезапуска проблема могла исчезнуть
+	u.loading = true
+	return loadUnits
+}
+
+func (m model) updateUnits(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	u := &m.units
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "q", "esc":
+		m.phase = "select"
+		return m, nil
+	}
+
+	if u.loading || u.restarting || len(u.problems) == 0 {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if u.cursor > 0 {
+			u.cursor--
+		}
+	case "down", "j":
+		if u.cursor < len(u.problems)-1 {
+			u.cursor++
+		}
+	case "r":
+		problem := u.problems[u.cursor]
+		if !problem.Restartable() {
+			u.message = fmt.Sprintf("%s is masked, unmask it first", problem.Unit)
+			return m, nil
+		}
+		// У опоздавшего таймера запускается его служба, а не сам таймер
+		u.restarting = true
+		u.message = fmt.Sprintf("Restarting %s...", problem.Target)
+		return m, func() tea.Msg {
+			return unitRestartedMsg{unit: problem.Target, err: modules.RestartUnit(problem.Target)}
+		}
+	}
+
+	return m, nil
+}
+
+func (m model) renderUnits() string {
+	u := m.units
+	var b strings.Builder
+
+	b.WriteString(headerStyle.Render("⚙️  Systemd Units") + "\n\n")
+
+	switch {
+	case u.loading:
+		b.WriteString(fmt.Sprintf("%s Querying systemd...\n", m.spinner.View()))
+	case len(u.problems) == 0:
+		b.WriteString(logStyle.Render("  ✅ No failed, restarting or masked units") + "\n")
+	default:
+		// Строки всех юнитов, у выбранного под ним выдержка из журнала
+		var rows []string
+		cursorRow := 0
+		for i, problem := range u.problems {
+			cursor := " "
+			if i == u.cursor {
+				cursor = ">"
+				cursorRow = len(rows)
+			}
+			rows = append(rows, fmt.Sprintf("%s %s", cursor, problem.Finding()))
+			if i == u.cursor {
+				if problem.Description != "" {
+					rows = append(rows, logStyle.Render("    "+problem.Description))
+				}
+				for _, log := range problem.Logs {
+					rows = append(rows, logStyle.Render("    │ "+log))
+				}
+			}
+		}
+
+		start, end := visibleRange(cursorRow, len(rows), m.explorerHeight())
+		for _, row := range rows[start:end] {
+			b.WriteString(row + "\n")
+		}
+	}
+
+	if u.message != "" {
+		b.WriteString("\n" + logStyle.Render(u.message) + "\n")
+	}
+
+	b.WriteString("\n" + headerStyle.Render("Controls:") + " ↑/↓ Navigate • r Restart unit • q Back\n")
+
+	return b.String()
+}
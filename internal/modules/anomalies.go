@@ -0,0 +1,341 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// fdWarningRatio и fdCriticalRatio - доля занятых файловых дескрипторов
+	// от мягкого лимита процесса
+	fdWarningRatio  = 0.8
+	fdCriticalRatio = 0.95
+	// rssGrowthMin - насколько должна вырасти память за все замеры,
+	// чтобы рост считался утечкой, а не колебанием
+	rssGrowthMin = 32 * 1024 * 1024
+	// rssDropTolerance - на сколько память может откатиться между замерами,
+	// чтобы рост все еще считался устойчивым
+	rssDropTolerance = 8 * 1024 * 1024
+	// rssSampleInterval - как часто запоминается память процесса, rssSamples -
+	// сколько замеров хранится, rssGrowthMinSamples - сколько прошлых замеров
+	// нужно, чтобы судить о росте
+	rssSampleInterval   = 10 * time.Minute
+	rssSamples          = 6
+	rssGrowthMinSamples = 3
+	// rssGrowthMinWindow и rssGrowthMaxWindow - замеры должны охватывать не
+	// меньше получаса и быть не старше суток: за минуты растет любой
+	// прогревающийся процесс
+	rssGrowthMinWindow = 30 * time.Minute
+	rssGrowthMaxWindow = 24 * time.Hour
+)
+
+// ProcessAnomaly - процесс в подозрительном состоянии и что с ним делать
+type ProcessAnomaly struct {
+	PID      int
+	Command  string
+	Severity Severity
+	Problem  string
+	Action   string
+}
+
+// Finding описывает аномалию для отчета о здоровье системы
+func (a ProcessAnomaly) Finding() Finding {
+	return Finding{
+		Severity: a.Severity,
+		Message:  fmt.Sprintf("%s (pid %d): %s → %s", shorten(a.Command), a.PID, a.Problem, a.Action),
+	}
+}
+
+// findZombies группирует зомби по родителям: зомби исчезают, только когда
+// родитель заберет их код завершения, поэтому действовать нужно с родителем
+func findZombies(processes map[int]ProcessInfo) []ProcessAnomaly {
+	zombies := make(map[int][]int)
+	for pid, info := range processes {
+		if info.State == 'Z' {
+			zombies[info.PPID] = append(zombies[info.PPID], pid)
+		}
+	}
+
+	var anomalies []ProcessAnomaly
+	for ppid, children := range zombies {
+		sort.Ints(children)
+		parent := processes[ppid]
+		command := parent.Command
+		if command == "" {
+			command = "unknown parent"
+		}
+		severity := SeverityWarning
+		if len(children) >= 100 {
+			severity = SeverityCritical
+		}
+		anomalies = append(anomalies, ProcessAnomaly{
+			PID:      ppid,
+			Command:  command,
+			Severity: severity,
+			Problem:  fmt.Sprintf("%d zombie children (pid %s)", len(children), joinPIDs(children, 5)),
+			Action:   "parent does not reap its children, restart it",
+		})
+	}
+
+	sortAnomalies(anomalies)
+	return anomalies
+}
+
+// findStuckProcesses находит процессы, которые во всех снимках находились
+// в непрерываемом сне (D) - обычно они ждут зависший диск или сетевой ресурс
+func findStuckProcesses(snapshots []map[int]ProcessInfo, procRoot string) []ProcessAnomaly {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	var anomalies []ProcessAnomaly
+	for pid, info := range snapshots[len(snapshots)-1] {
+		stuck := true
+		for _, snapshot := range snapshots {
+			if snapshot[pid].State != 'D' {
+				stuck = false
+				break
+			}
+		}
+		if !stuck {
+			continue
+		}
+
+		problem := "stuck in uninterruptible sleep (D state)"
+		if wchan := readSysString(filepath.Join(procRoot, strconv.Itoa(pid), "wchan")); wchan != "" && wchan != "0" {
+			problem += " in " + wchan
+		}
+		anomalies = append(anomalies, ProcessAnomaly{
+			PID:      pid,
+			Command:  info.Command,
+			Severity: SeverityWarning,
+			Problem:  problem,
+			Action:   "it cannot be killed; check dmesg for disk or NFS errors",
+		})
+	}
+
+	sortAnomalies(anomalies)
+	return anomalies
+}
+
+// rssRecord - память процесса при одной из прошлых проверок
+type rssRecord struct {
+	RSS      int64     `json:"rss"`
+	Recorded time.Time `json:"recorded"`
+}
+
+// rssHistory - память процессов при прошлых проверках, от старых к новым.
+// Процессы различаются по PID и времени запуска, а после перезагрузки
+// история начинается заново
+type rssHistory struct {
+	BootID    string                 `json:"boot_id"`
+	Processes map[string][]rssRecord `json:"samples"`
+}
+
+func processKey(info ProcessInfo) string {
+	return fmt.Sprintf("%d:%d", info.PID, info.StartTicks)
+}
+
+// samples возвращает замеры процесса, с которыми можно сравнивать сейчас
+func (h rssHistory) samples(info ProcessInfo, bootID string, now time.Time) []rssRecord {
+	if h.BootID != bootID {
+		return nil
+	}
+	var samples []rssRecord
+	for _, record := range h.Processes[processKey(info)] {
+		if now.Sub(record.Recorded) <= rssGrowthMaxWindow {
+			samples = append(samples, record)
+		}
+	}
+	return samples
+}
+
+// steadyGrowth сообщает, растет ли память от замера к замеру. Небольшие
+// откаты в пределах rssDropTolerance допускаются: сборщики мусора и
+// аллокаторы возвращают память неравномерно
+func steadyGrowth(series []int64) bool {
+	for i := 1; i < len(series); i++ {
+		if series[i] < series[i-1]-rssDropTolerance {
+			return false
+		}
+	}
+	return series[len(series)-1]-series[0] >= rssGrowthMin
+}
+
+// findRSSGrowth находит процессы, чья память росла на протяжении не менее
+// rssGrowthMinSamples прошлых проверок и выросла в сумме на rssGrowthMin
+func findRSSGrowth(history rssHistory, bootID string, processes map[int]ProcessInfo, now time.Time) []ProcessAnomaly {
+	var anomalies []ProcessAnomaly
+	for pid, info := range processes {
+		samples := history.samples(info, bootID, now)
+		if len(samples) < rssGrowthMinSamples {
+			continue
+		}
+		window := now.Sub(samples[0].Recorded)
+		if window < rssGrowthMinWindow {
+			continue
+		}
+		series := make([]int64, 0, len(samples)+1)
+		for _, record := range samples {
+			series = append(series, record.RSS)
+		}
+		series = append(series, info.RSS)
+		if !steadyGrowth(series) {
+			continue
+		}
+
+		growth := info.RSS - samples[0].RSS
+		rate := float64(growth) / window.Minutes() / 1024 / 1024
+		anomalies = append(anomalies, ProcessAnomaly{
+			PID:      pid,
+			Command:  info.Command,
+			Severity: SeverityWarning,
+			Problem: fmt.Sprintf("memory grew steadily from %d to %d MB over %d checks in %.0f min, +%.1f MB/min",
+				samples[0].RSS/1024/1024, info.RSS/1024/1024, len(series), window.Minutes(), rate),
+			Action: "possible leak; restart it if growth continues",
+		})
+	}
+
+	sortAnomalies(anomalies)
+	return anomalies
+}
+
+// updateRSSHistory добавляет замеры текущих процессов. Новый замер пишется
+// не чаще rssSampleInterval, чтобы частые проверки не вытесняли историю,
+// хранятся последние rssSamples замеров, а завершившиеся процессы забываются
+func updateRSSHistory(history rssHistory, bootID string, processes map[int]ProcessInfo, now time.Time) rssHistory {
+	updated := rssHistory{BootID: bootID, Processes: make(map[string][]rssRecord, len(processes))}
+	for _, info := range processes {
+		samples := history.samples(info, bootID, now)
+		if len(samples) == 0 || now.Sub(samples[len(samples)-1].Recorded) >= rssSampleInterval {
+			samples = append(samples, rssRecord{RSS: info.RSS, Recorded: now})
+		}
+		if len(samples) > rssSamples {
+			samples = samples[len(samples)-rssSamples:]
+		}
+		updated.Processes[processKey(info)] = samples
+	}
+	return updated
+}
+
+// recordRSSGrowth сравнивает память процессов с прошлыми замерами
+// из $XDG_STATE_HOME/ububu/processes.json и добавляет к ним текущие
+func recordRSSGrowth(processes map[int]ProcessInfo) []ProcessAnomaly {
+	dir, err := stateDir()
+	if err != nil {
+		return nil
+	}
+	path := filepath.Join(dir, "processes.json")
+	bootID := readSysString(bootIDPath)
+	now := time.Now()
+
+	var history rssHistory
+	loadState(path, &history) // Испорченная история просто начинается заново
+	anomalies := findRSSGrowth(history, bootID, processes, now)
+	// История нужна только для сравнения, ошибка записи не мешает проверке
+	saveState(path, updateRSSHistory(history, bootID, processes, now))
+	return anomalies
+}
+
+// readFDUsage возвращает число открытых дескрипторов процесса и его мягкий
+// лимит из limits. Без прав root чужие процессы недоступны
+func readFDUsage(pidDir string) (int, int, bool) {
+	fds, err := os.ReadDir(filepath.Join(pidDir, "fd"))
+	if err != nil {
+		return 0, 0, false
+	}
+	file, err := os.Open(filepath.Join(pidDir, "limits"))
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		limit, err := strconv.Atoi(fields[0]) // "unlimited" не ограничивает
+		if err != nil {
+			break
+		}
+		return len(fds), limit, true
+	}
+	return 0, 0, false
+}
+
+// findFDExhaustion находит процессы, которые близки к лимиту открытых файлов
+func findFDExhaustion(processes map[int]ProcessInfo, procRoot string) []ProcessAnomaly {
+	var anomalies []ProcessAnomaly
+	for pid, info := range processes {
+		open, limit, ok := readFDUsage(filepath.Join(procRoot, strconv.Itoa(pid)))
+		if !ok || limit == 0 {
+			continue
+		}
+		ratio := float64(open) / float64(limit)
+		if ratio < fdWarningRatio {
+			continue
+		}
+		severity := SeverityWarning
+		if ratio >= fdCriticalRatio {
+			severity = SeverityCritical
+		}
+		anomalies = append(anomalies, ProcessAnomaly{
+			PID:      pid,
+			Command:  info.Command,
+			Severity: severity,
+			Problem:  fmt.Sprintf("%d of %d file descriptors open", open, limit),
+			Action:   "check for a descriptor leak or raise LimitNOFILE",
+		})
+	}
+
+	sortAnomalies(anomalies)
+	return anomalies
+}
+
+// FindProcessAnomalies ищет зомби, зависшие процессы, утечки памяти
+// и исчерпание файловых дескрипторов
+func FindProcessAnomalies(sample ProcessSample) []ProcessAnomaly {
+	latest := sample.Latest()
+
+	var anomalies []ProcessAnomaly
+	anomalies = append(anomalies, findZombies(latest)...)
+	anomalies = append(anomalies, findStuckProcesses(sample.Snapshots, procDir)...)
+	anomalies = append(anomalies, findFDExhaustion(latest, procDir)...)
+	anomalies = append(anomalies, recordRSSGrowth(latest)...)
+	return anomalies
+}
+
+// sortAnomalies ставит серьезные проблемы первыми
+func sortAnomalies(anomalies []ProcessAnomaly) {
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Severity != anomalies[j].Severity {
+			return anomalies[i].Severity > anomalies[j].Severity
+		}
+		return anomalies[i].PID < anomalies[j].PID
+	})
+}
+
+// joinPIDs перечисляет до limit идентификаторов процессов
+func joinPIDs(pids []int, limit int) string {
+	var parts []string
+	for i, pid := range pids {
+		if i == limit {
+			parts = append(parts, fmt.Sprintf("and %d more", len(pids)-limit))
+			break
+		}
+		parts = append(parts, strconv.Itoa(pid))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,152 @@
+package modules
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindZombies(t *testing.T) {
+	processes := map[int]ProcessInfo{
+		1:   {PID: 1, Command: "/sbin/init", State: 'S'},
+		500: {PID: 500, PPID: 1, Command: "buggy-daemon --serve", State: 'S'},
+		501: {PID: 501, PPID: 500, Command: "[worker]", State: 'Z'},
+		502: {PID: 502, PPID: 500, Command: "[worker]", State: 'Z'},
+		600: {PID: 600, PPID: 1, Command: "healthy", State: 'R'},
+	}
+
+	zombies := findZombies(processes)
+	if len(zombies) != 1 {
+		t.Fatalf("findZombies() = %+v, want one parent", zombies)
+	}
+	zombie := zombies[0]
+	if zombie.PID != 500 || zombie.Command != "buggy-daemon --serve" || !strings.Contains(zombie.Problem, "2 zombie children (pid 501, 502)") {
+		t.Errorf("Unexpected anomaly: %+v", zombie)
+	}
+	if message := zombie.Finding().String(); !strings.Contains(message, "buggy-daemon --serve (pid 500)") || !strings.Contains(message, "restart") {
+		t.Errorf("Finding() = %q, want command, pid and action", message)
+	}
+}
+
+func TestFindStuckProcesses(t *testing.T) {
+	procRoot := t.TempDir()
+	writeSysFiles(t, filepath.Join(procRoot, "42"), map[string]string{"wchan": "nfs_wait_bit_killable"})
+
+	snapshots := []map[int]ProcessInfo{
+		{42: {PID: 42, Command: "ls /mnt/nfs", State: 'D'}, 43: {PID: 43, Command: "cp", State: 'D'}},
+		{42: {PID: 42, Command: "ls /mnt/nfs", State: 'D'}, 43: {PID: 43, Command: "cp", State: 'R'}},
+		{42: {PID: 42, Command: "ls /mnt/nfs", State: 'D'}, 43: {PID: 43, Command: "cp", State: 'D'}},
+	}
+
+	stuck := findStuckProcesses(snapshots, procRoot)
+	if len(stuck) != 1 || stuck[0].PID != 42 {
+		t.Fatalf("findStuckProcesses() = %+v, want only pid 42", stuck)
+	}
+	if !strings.Contains(stuck[0].Problem, "nfs_wait_bit_killable") {
+		t.Errorf("Problem should mention the wait channel, got %q", stuck[0].Problem)
+	}
+}
+
+func TestFindRSSGrowth(t *testing.T) {
+	const mb = 1024 * 1024
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	// series - замеры раз в 10 минут, последний 10 минут назад
+	series := func(values ...int64) []rssRecord {
+		var records []rssRecord
+		for i, value := range values {
+			records = append(records, rssRecord{RSS: value * mb, Recorded: now.Add(-time.Duration(len(values)-i) * 10 * time.Minute)})
+		}
+		return records
+	}
+	history := rssHistory{BootID: "boot-1", Processes: map[string][]rssRecord{
+		"10:500": series(100, 115, 130),
+		"11:500": series(100, 140, 95),  // Скачок и откат
+		"12:500": series(100, 104, 100), // Колебания без роста
+		"13:500": series(100, 120, 140), // Другой процесс с тем же PID
+		"14:500": series(100, 140),      // Слишком мало замеров
+		"15:500": series(100, 125, 123), // Небольшой откат
+	}}
+	processes := map[int]ProcessInfo{
+		10: {PID: 10, StartTicks: 500, Command: "leaky", RSS: 145 * mb},
+		11: {PID: 11, StartTicks: 500, Command: "spiky", RSS: 200 * mb},
+		12: {PID: 12, StartTicks: 500, Command: "stable", RSS: 102 * mb},
+		13: {PID: 13, StartTicks: 900, Command: "reused pid", RSS: 300 * mb},
+		14: {PID: 14, StartTicks: 500, Command: "short history", RSS: 300 * mb},
+		15: {PID: 15, StartTicks: 500, Command: "leaky with gc", RSS: 150 * mb},
+		16: {PID: 16, StartTicks: 500, Command: "new", RSS: 300 * mb},
+	}
+
+	growing := findRSSGrowth(history, "boot-1", processes, now)
+	if len(growing) != 2 || growing[0].PID != 10 || growing[1].PID != 15 {
+		t.Fatalf("findRSSGrowth() = %+v, want the leaky processes", growing)
+	}
+	if !strings.Contains(growing[0].Problem, "from 100 to 145 MB over 4 checks in 30 min, +1.5 MB/min") {
+		t.Errorf("Unexpected problem: %q", growing[0].Problem)
+	}
+
+	// Замеры старше суток не учитываются
+	stale := rssHistory{BootID: "boot-1", Processes: map[string][]rssRecord{"10:500": history.Processes["10:500"]}}
+	if growing := findRSSGrowth(stale, "boot-1", processes, now.Add(24*time.Hour)); len(growing) != 0 {
+		t.Errorf("findRSSGrowth() with stale samples = %+v, want none", growing)
+	}
+	if growing := findRSSGrowth(history, "boot-2", processes, now); len(growing) != 0 {
+		t.Errorf("findRSSGrowth() after a reboot = %+v, want none", growing)
+	}
+}
+
+func TestUpdateRSSHistory(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	full := make([]rssRecord, rssSamples)
+	for i := range full {
+		full[i] = rssRecord{RSS: int64(i), Recorded: now.Add(-time.Duration(rssSamples-i) * time.Hour)}
+	}
+	history := rssHistory{BootID: "boot-1", Processes: map[string][]rssRecord{
+		"10:500": {{RSS: 100, Recorded: now.Add(-20 * time.Minute)}},
+		"11:500": {{RSS: 100, Recorded: now.Add(-48 * time.Hour)}},
+		"12:500": {{RSS: 100, Recorded: now.Add(-time.Minute)}}, // Процесс завершился
+		"14:500": {{RSS: 100, Recorded: now.Add(-time.Minute)}},
+		"15:500": full,
+	}}
+	processes := map[int]ProcessInfo{
+		10: {PID: 10, StartTicks: 500, RSS: 200},
+		11: {PID: 11, StartTicks: 500, RSS: 200},
+		13: {PID: 13, StartTicks: 700, RSS: 200},
+		14: {PID: 14, StartTicks: 500, RSS: 200},
+		15: {PID: 15, StartTicks: 500, RSS: 200},
+	}
+
+	updated := updateRSSHistory(history, "boot-1", processes, now)
+	want := map[string][]rssRecord{
+		"10:500": {{RSS: 100, Recorded: now.Add(-20 * time.Minute)}, {RSS: 200, Recorded: now}},
+		"11:500": {{RSS: 200, Recorded: now}}, // Замер старше суток забыт
+		"13:700": {{RSS: 200, Recorded: now}},
+		"14:500": {{RSS: 100, Recorded: now.Add(-time.Minute)}}, // Слишком рано для нового замера
+		"15:500": append(append([]rssRecord{}, full[1:]...), rssRecord{RSS: 200, Recorded: now}),
+	}
+	if updated.BootID != "boot-1" || !reflect.DeepEqual(updated.Processes, want) {
+		t.Errorf("updateRSSHistory() = %+v, want %+v", updated, want)
+	}
+
+	if rebooted := updateRSSHistory(history, "boot-2", processes, now); len(rebooted.Processes["10:500"]) != 1 {
+		t.Errorf("history after a reboot = %+v, want fresh records", rebooted)
+	}
+}
+
+func TestFindFDExhaustion(t *testing.T) {
+	procRoot := t.TempDir()
+	limits := "Limit                     Soft Limit           Hard Limit           Units     \n" +
+		"Max open files            10                   4096                 files     \n"
+	writeSysFiles(t, filepath.Join(procRoot, "7"), map[string]string{"limits": limits})
+	fds := map[string]string{}
+	for i := 0; i < 9; i++ {
+		fds[string(rune('0'+i))] = ""
+	}
+	writeSysFiles(t, filepath.Join(procRoot, "7", "fd"), fds)
+
+	anomalies := findFDExhaustion(map[int]ProcessInfo{7: {PID: 7, Command: "server"}}, procRoot)
+	if len(anomalies) != 1 || anomalies[0].Severity != SeverityWarning || !strings.Contains(anomalies[0].Problem, "9 of 10") {
+		t.Errorf("findFDExhaustion() = %+v, want a warning for 9 of 10 descriptors", anomalies)
+	}
+}
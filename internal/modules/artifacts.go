@@ -0,0 +1,247 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStaleProjectAge = 90 * 24 * time.Hour
+	// workspaceScanDepth ограничивает глубину поиска проектов в корнях
+	workspaceScanDepth = 5
+)
+
+// projectArtifacts сопоставляет файл-маркер проекта с каталогами,
+// которые сборка создает заново
+var projectArtifacts = map[string][]string{
+	"package.json":     {"node_modules"},
+	"Cargo.toml":       {"target"},
+	"pom.xml":          {"target"},
+	"pyproject.toml":   {".venv", ".tox"},
+	"requirements.txt": {".venv", ".tox"},
+	"setup.py":         {".venv", ".tox"},
+}
+
+// toolArtifacts - каталоги, которые генерирует инструмент из зависимостей
+// package.json. build/ и dist/ удаляются только у известных инструментов:
+// у других (например, electron-builder) там лежат исходники
+var toolArtifacts = map[string][]string{
+	"react-scripts":    {"build"},
+	"@docusaurus/core": {"build"},
+	"vite":             {"dist"},
+	"@angular/cli":     {"dist"},
+	"parcel":           {"dist"},
+	"next":             {".next"},
+	"nuxt":             {".nuxt", ".output"},
+}
+
+// staleProject - проект, который давно не менялся, и его артефакты сборки
+type staleProject struct {
+	Dir       string
+	Artifacts []string
+	Size      int64
+}
+
+// workspaceRoots возвращает корни поиска проектов для homeDir. Относительные
+// пути из WorkspaceRoots отсчитываются от каждого домашнего каталога, а
+// абсолютные общие для всех и обходятся только в проходе текущего пользователя
+func (m *CleanupModule) workspaceRoots(homeDir string) []string {
+	if len(m.WorkspaceRoots) == 0 {
+		var roots []string
+		for _, name := range []string{"projects", "src", "code", "work", "dev", "go/src"} {
+			roots = append(roots, filepath.Join(homeDir, name))
+		}
+		return roots
+	}
+
+	var roots []string
+	for _, root := range m.WorkspaceRoots {
+		root = strings.TrimPrefix(root, "~/")
+		if !filepath.IsAbs(root) {
+			roots = append(roots, filepath.Join(homeDir, root))
+		} else if isCurrentHome(homeDir) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+func (m *CleanupModule) staleProjectAge() time.Duration {
+	if m.StaleProjectAge > 0 {
+		return m.StaleProjectAge
+	}
+	return defaultStaleProjectAge
+}
+
+// findStaleProjects ищет в корнях проекты, не изменявшиеся дольше staleAfter,
+// у которых есть артефакты сборки
+func (m *CleanupModule) findStaleProjects(roots []string, staleAfter time.Duration) []staleProject {
+	cutoff := time.Now().Add(-staleAfter)
+	var projects []staleProject
+
+	for _, root := range roots {
+		rootDepth := strings.Count(filepath.Clean(root), string(os.PathSeparator))
+
+		filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+			if err != nil || !d.IsDir() {
+				return nil // Игнорируем ошибки доступа
+			}
+			if path != root && (strings.HasPrefix(d.Name(), ".") || isArtifactDir(d.Name())) {
+				return filepath.SkipDir
+			}
+			if strings.Count(path, string(os.PathSeparator))-rootDepth > workspaceScanDepth {
+				return filepath.SkipDir
+			}
+
+			artifacts := detectArtifacts(path)
+			if len(artifacts) == 0 || lastModified(path, artifacts).After(cutoff) {
+				return nil
+			}
+
+			project := staleProject{Dir: path}
+			for _, artifact := range artifacts {
+				if size, err := m.getDirSize(artifact); err == nil {
+					project.Size += size
+					project.Artifacts = append(project.Artifacts, artifact)
+				}
+			}
+			projects = append(projects, project)
+			return nil
+		})
+	}
+
+	return projects
+}
+
+// detectArtifacts возвращает существующие каталоги артефактов проекта в dir
+func detectArtifacts(dir string) []string {
+	var candidates []string
+	for marker, names := range projectArtifacts {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			candidates = append(candidates, names...)
+		}
+	}
+	for _, dependency := range packageDependencies(dir) {
+		candidates = append(candidates, toolArtifacts[dependency]...)
+	}
+
+	seen := make(map[string]bool)
+	var artifacts []string
+	for _, name := range candidates {
+		path := filepath.Join(dir, name)
+		if seen[path] {
+			continue
+		}
+		if info, err := os.Lstat(path); err == nil && info.IsDir() {
+			seen[path] = true
+			artifacts = append(artifacts, path)
+		}
+	}
+
+	return artifacts
+}
+
+// packageDependencies возвращает зависимости из package.json в dir
+func packageDependencies(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &manifest) != nil {
+		return nil
+	}
+
+	var dependencies []string
+	for name := range manifest.Dependencies {
+		dependencies = append(dependencies, name)
+	}
+	for name := range manifest.DevDependencies {
+		dependencies = append(dependencies, name)
+	}
+	return dependencies
+}
+
+// isArtifactDir сообщает, может ли каталог с таким именем быть артефактом
+// сборки. Такие каталоги не просматриваются при поиске проектов
+func isArtifactDir(name string) bool {
+	for _, table := range []map[string][]string{projectArtifacts, toolArtifacts} {
+		for _, names := range table {
+			for _, artifact := range names {
+				if name == artifact {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// lastModified возвращает время последнего изменения исходников проекта,
+// не заглядывая в его артефакты сборки и .git
+func lastModified(dir string, artifacts []string) time.Time {
+	var latest time.Time
+	skip := make(map[string]bool)
+	for _, artifact := range artifacts {
+		skip[artifact] = true
+	}
+
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && (d.Name() == ".git" || skip[path]) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() {
+			// Время каталога меняется и при пересоздании артефактов, поэтому смотрим только на файлы
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+
+	return latest
+}
+
+// previewStaleArtifacts возвращает по одной позиции предпросмотра на проект
+func (m *CleanupModule) previewStaleArtifacts(homeDir string) []CleanupItem {
+	var items []CleanupItem
+	for _, project := range m.findStaleProjects(m.workspaceRoots(homeDir), m.staleProjectAge()) {
+		items = append(items, CleanupItem{Category: "Stale build artifacts", Path: project.Dir, Size: project.Size})
+	}
+	return items
+}
+
+func (m *CleanupModule) cleanStaleArtifacts(report func(message string)) (int64, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return 0, err
+	}
+	return m.cleanStaleArtifactsFor(homeDir, report)
+}
+
+func (m *CleanupModule) cleanStaleArtifactsFor(homeDir string, report func(message string)) (int64, error) {
+	var totalFreed int64
+	for _, project := range m.findStaleProjects(m.workspaceRoots(homeDir), m.staleProjectAge()) {
+		var freed int64
+		for _, artifact := range project.Artifacts {
+			freed += m.removeMeasured(artifact, false)
+		}
+		if freed > 0 {
+			totalFreed += freed
+			report(fmt.Sprintf("%s: %d MB of build artifacts removed", project.Dir, freed/1024/1024))
+		}
+	}
+
+	return totalFreed, nil
+}
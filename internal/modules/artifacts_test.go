@@ -0,0 +1,156 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// makeProject создает проект с маркером и артефактом и выставляет время изменения файлов
+func makeProject(t *testing.T, dir, marker, artifact string, modTime time.Time) {
+	t.Helper()
+	artifactDir := filepath.Join(dir, artifact)
+	if err := os.MkdirAll(artifactDir, 0755); err != nil {
+		t.Fatalf("Failed to create project dir: %v", err)
+	}
+	files := []string{filepath.Join(dir, marker), filepath.Join(artifactDir, "output.bin")}
+	for _, file := range files {
+		if err := os.WriteFile(file, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		os.Chtimes(file, modTime, modTime)
+	}
+}
+
+func TestCleanupModule_FindStaleProjects(t *testing.T) {
+	module := &CleanupModule{}
+
+	root, err := os.MkdirTemp("", "ububu_workspace_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	old := time.Now().Add(-200 * 24 * time.Hour)
+	makeProject(t, filepath.Join(root, "old-web"), "package.json", "node_modules", old)
+	makeProject(t, filepath.Join(root, "group", "old-rust"), "Cargo.toml", "target", old)
+	makeProject(t, filepath.Join(root, "active"), "pom.xml", "target", time.Now())
+	// build/ и .venv в Go-проекте не являются артефактами и не должны трогаться
+	makeProject(t, filepath.Join(root, "old-go"), "go.mod", "build", old)
+	makeProject(t, filepath.Join(root, "old-go-venv"), "go.mod", ".venv", old)
+	makeProject(t, filepath.Join(root, "old-python"), "pyproject.toml", ".venv", old)
+
+	projects := module.findStaleProjects([]string{root}, 90*24*time.Hour)
+
+	found := make(map[string]staleProject)
+	for _, project := range projects {
+		found[filepath.Base(project.Dir)] = project
+	}
+
+	if len(found) != 3 {
+		t.Errorf("Expected 3 stale projects, got %d: %v", len(found), projects)
+	}
+	for _, name := range []string{"old-web", "old-rust", "old-python"} {
+		project, ok := found[name]
+		if !ok {
+			t.Errorf("Project %s should be detected as stale", name)
+			continue
+		}
+		if project.Size <= 0 {
+			t.Errorf("Project %s artifact size = %d, want > 0", name, project.Size)
+		}
+	}
+	if _, ok := found["active"]; ok {
+		t.Error("Recently modified project should not be detected as stale")
+	}
+}
+
+func TestCleanupModule_CleanStaleArtifacts(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_workspace_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	module := &CleanupModule{WorkspaceRoots: []string{root}, StaleProjectAge: 24 * time.Hour}
+
+	projectDir := filepath.Join(root, "legacy")
+	makeProject(t, projectDir, "package.json", "node_modules", time.Now().Add(-48*time.Hour))
+
+	var messages []string
+	freed, err := module.cleanStaleArtifacts(func(message string) {
+		messages = append(messages, message)
+	})
+	if err != nil {
+		t.Errorf("cleanStaleArtifacts() returned error: %v", err)
+	}
+	if freed <= 0 {
+		t.Errorf("cleanStaleArtifacts() freed = %d, want > 0", freed)
+	}
+	if len(messages) != 1 {
+		t.Errorf("Expected one message per project, got %v", messages)
+	}
+
+	if _, err := os.Stat(filepath.Join(projectDir, "node_modules")); !os.IsNotExist(err) {
+		t.Error("node_modules should have been removed")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, "package.json")); err != nil {
+		t.Error("Project sources should have been kept")
+	}
+}
+
+func TestDetectArtifacts_PackageTools(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     []string
+	}{
+		// electron-builder хранит в build/ исходные ресурсы
+		{"electron", `{"devDependencies": {"electron-builder": "^24.0.0"}}`, []string{"node_modules"}},
+		{"react", `{"dependencies": {"react-scripts": "5.0.1"}}`, []string{"node_modules", "build"}},
+		{"vite", `{"devDependencies": {"vite": "^5.0.0"}}`, []string{"node_modules", "dist"}},
+		{"broken", `{`, []string{"node_modules"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(root, tt.name)
+			for _, name := range []string{"node_modules", "build", "dist"} {
+				os.MkdirAll(filepath.Join(dir, name), 0755)
+			}
+			os.WriteFile(filepath.Join(dir, "package.json"), []byte(tt.manifest), 0644)
+
+			var got []string
+			for _, artifact := range detectArtifacts(dir) {
+				got = append(got, filepath.Base(artifact))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("detectArtifacts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanupModule_WorkspaceRoots(t *testing.T) {
+	current, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	module := &CleanupModule{WorkspaceRoots: []string{"/srv/projects", "~/work", "code"}}
+
+	// Абсолютный корень обходится один раз, в проходе текущего пользователя
+	want := []string{"/srv/projects", filepath.Join(current, "work"), filepath.Join(current, "code")}
+	if got := module.workspaceRoots(current); !reflect.DeepEqual(got, want) {
+		t.Errorf("workspaceRoots(current) = %v, want %v", got, want)
+	}
+
+	other := filepath.Join(t.TempDir(), "alice")
+	want = []string{filepath.Join(other, "work"), filepath.Join(other, "code")}
+	if got := module.workspaceRoots(other); !reflect.DeepEqual(got, want) {
+		t.Errorf("workspaceRoots(other) = %v, want %v", got, want)
+	}
+}
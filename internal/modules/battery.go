@@ -0,0 +1,346 @@
+package modules
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	powerSupplyDir = "/sys/class/power_supply"
+	// batteryWearWarning и batteryWearCritical - потеря емкости относительно
+	// заводской в процентах
+	batteryWearWarning  = 20
+	batteryWearCritical = 40
+	// batteryCycleWarning - типичный ресурс литий-ионного аккумулятора
+	batteryCycleWarning = 1000
+	// batteryHistoryLimit - сколько измерений хранится в истории
+	batteryHistoryLimit = 180
+	// batteryTrendMin - минимальный период, по которому тренд имеет смысл
+	batteryTrendMin = 7 * 24 * time.Hour
+)
+
+// Battery - аккумулятор из /sys/class/power_supply. Емкость в мкВт·ч или
+// мкА·ч в зависимости от того, что отдает драйвер (Unit)
+type Battery struct {
+	Name   string
+	Model  string
+	Serial string
+	Status string
+	// Charge - уровень заряда в процентах, -1 если неизвестен
+	Charge int
+	Full   float64
+	Design float64
+	Unit   string
+	// Cycles - число циклов заряда, -1 если драйвер его не сообщает
+	Cycles int
+	// StartThreshold и EndThreshold - пороги заряда, -1 если не поддерживаются
+	StartThreshold int
+	EndThreshold   int
+	// Power - текущая мощность заряда или разряда в ваттах
+	Power float64
+}
+
+// ID - устойчивый идентификатор аккумулятора для истории: имя BAT0
+// может достаться другому аккумулятору после замены
+func (b Battery) ID() string {
+	if b.Model != "" || b.Serial != "" {
+		return strings.TrimSpace(b.Model + " " + b.Serial)
+	}
+	return b.Name
+}
+
+// Wear возвращает потерю емкости относительно заводской в процентах,
+// -1 если емкость неизвестна
+func (b Battery) Wear() float64 {
+	if b.Design <= 0 || b.Full <= 0 {
+		return -1
+	}
+	wear := 100 - b.Full*100/b.Design
+	if wear < 0 {
+		return 0
+	}
+	return wear
+}
+
+// Findings возвращает проблемы аккумулятора
+func (b Battery) Findings(acOnline bool) []Finding {
+	var findings []Finding
+	add := func(severity Severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Message: b.Name + ": " + fmt.Sprintf(format, args...)})
+	}
+
+	switch wear := b.Wear(); {
+	case wear >= batteryWearCritical:
+		add(SeverityCritical, "%.0f%% of design capacity lost, replace the battery", wear)
+	case wear >= batteryWearWarning:
+		add(SeverityWarning, "%.0f%% of design capacity lost", wear)
+	}
+	if b.Cycles >= batteryCycleWarning {
+		add(SeverityWarning, "%d charge cycles, beyond the typical rated lifetime", b.Cycles)
+	}
+	// Постоянно подключенный к сети ноутбук быстрее изнашивает аккумулятор при 100%
+	if acOnline && b.EndThreshold == 100 {
+		add(SeverityInfo, "always charged to 100%%, an 80%% charge threshold slows wear")
+	}
+	return findings
+}
+
+// String описывает состояние аккумулятора одной строкой
+func (b Battery) String() string {
+	line := b.Name
+	if b.Model != "" {
+		line += " (" + b.Model + ")"
+	}
+	if b.Charge >= 0 {
+		line += fmt.Sprintf(" %d%%", b.Charge)
+	}
+	if b.Status != "" {
+		line += ", " + strings.ToLower(b.Status)
+	}
+	if b.Power > 0 {
+		line += fmt.Sprintf(" at %.1f W", b.Power)
+	}
+	return line
+}
+
+// Details описывает емкость, циклы и пороги заряда
+func (b Battery) Details() string {
+	var parts []string
+	if wear := b.Wear(); wear >= 0 {
+		parts = append(parts, fmt.Sprintf("capacity %.1f of %.1f %s (%.0f%% wear)",
+			b.Full/1e6, b.Design/1e6, b.Unit, wear))
+	}
+	if b.Cycles >= 0 {
+		parts = append(parts, fmt.Sprintf("%d cycles", b.Cycles))
+	}
+	switch {
+	case b.StartThreshold >= 0 && b.EndThreshold >= 0:
+		parts = append(parts, fmt.Sprintf("charge thresholds %d-%d%%", b.StartThreshold, b.EndThreshold))
+	case b.EndThreshold >= 0:
+		parts = append(parts, fmt.Sprintf("charge limit %d%%", b.EndThreshold))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// PowerStatus - аккумуляторы и состояние питания от сети
+type PowerStatus struct {
+	Batteries []Battery
+	// HasAC - у системы есть блок питания, о котором сообщает ядро
+	HasAC    bool
+	ACOnline bool
+}
+
+// readSysInt читает целое значение, -1 если файла нет
+func readSysInt(path string) int {
+	if value, ok := readSysValue(path); ok {
+		return int(value)
+	}
+	return -1
+}
+
+// readBattery читает аккумулятор. Драйверы сообщают либо энергию (energy_*,
+// power_now), либо заряд (charge_*, current_now) - поддерживаются оба варианта
+func readBattery(dir string) Battery {
+	battery := Battery{
+		Name:           filepath.Base(dir),
+		Model:          readSysString(filepath.Join(dir, "model_name")),
+		Serial:         readSysString(filepath.Join(dir, "serial_number")),
+		Status:         readSysString(filepath.Join(dir, "status")),
+		Charge:         readSysInt(filepath.Join(dir, "capacity")),
+		Cycles:         readSysInt(filepath.Join(dir, "cycle_count")),
+		StartThreshold: readSysInt(filepath.Join(dir, "charge_control_start_threshold")),
+		EndThreshold:   readSysInt(filepath.Join(dir, "charge_control_end_threshold")),
+	}
+	// Многие драйверы отдают 0, если счетчик циклов не поддерживается
+	if battery.Cycles == 0 {
+		battery.Cycles = -1
+	}
+
+	if full, ok := readSysValue(filepath.Join(dir, "energy_full")); ok {
+		battery.Unit = "Wh"
+		battery.Full = full
+		battery.Design, _ = readSysValue(filepath.Join(dir, "energy_full_design"))
+		if power, ok := readSysValue(filepath.Join(dir, "power_now")); ok {
+			battery.Power = power / 1e6
+		}
+	} else if full, ok := readSysValue(filepath.Join(dir, "charge_full")); ok {
+		battery.Unit = "Ah"
+		battery.Full = full
+		battery.Design, _ = readSysValue(filepath.Join(dir, "charge_full_design"))
+		current, okCurrent := readSysValue(filepath.Join(dir, "current_now"))
+		voltage, okVoltage := readSysValue(filepath.Join(dir, "voltage_now"))
+		if okCurrent && okVoltage {
+			battery.Power = current * voltage / 1e12
+		}
+	}
+	if battery.Power < 0 {
+		// Некоторые драйверы отдают ток разряда со знаком минус
+		battery.Power = -battery.Power
+	}
+	return battery
+}
+
+// readPowerSupply читает аккумуляторы и блоки питания. Аккумуляторы
+// периферии (scope=Device, например мыши) не учитываются
+func readPowerSupply(root string) PowerStatus {
+	var status PowerStatus
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return status
+	}
+
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		switch readSysString(filepath.Join(dir, "type")) {
+		case "Battery":
+			if readSysString(filepath.Join(dir, "scope")) == "Device" || readSysString(filepath.Join(dir, "present")) == "0" {
+				continue
+			}
+			status.Batteries = append(status.Batteries, readBattery(dir))
+		case "Mains", "USB":
+			if readSysString(filepath.Join(dir, "online")) == "" {
+				continue
+			}
+			status.HasAC = true
+			status.ACOnline = status.ACOnline || readSysString(filepath.Join(dir, "online")) == "1"
+		}
+	}
+
+	sort.Slice(status.Batteries, func(i, j int) bool {
+		return status.Batteries[i].Name < status.Batteries[j].Name
+	})
+	return status
+}
+
+// ReadPowerSupply возвращает состояние аккумуляторов и питания от сети
+func ReadPowerSupply() PowerStatus {
+	return readPowerSupply(powerSupplyDir)
+}
+
+// BatteryRecord - одно измерение аккумулятора в истории запусков
+type BatteryRecord struct {
+	Battery  string    `json:"battery"`
+	Recorded time.Time `json:"recorded"`
+	Full     float64   `json:"full"`
+	Design   float64   `json:"design"`
+	Cycles   int       `json:"cycles"`
+}
+
+// Wear возвращает износ на момент измерения
+func (r BatteryRecord) Wear() float64 {
+	return Battery{Full: r.Full, Design: r.Design}.Wear()
+}
+
+// BatteryTrend - изменение износа аккумулятора за период истории
+type BatteryTrend struct {
+	Days       int
+	WearChange float64
+	Cycles     int
+	// PerMonth - изменение износа за 30 дней
+	PerMonth float64
+}
+
+// String описывает тренд одной строкой
+func (t BatteryTrend) String() string {
+	line := fmt.Sprintf("wear %+.1f%% over %d days (%+.1f%%/month)", t.WearChange, t.Days, t.PerMonth)
+	if t.Cycles > 0 {
+		line += fmt.Sprintf(", %d cycles", t.Cycles)
+	}
+	return line
+}
+
+// BatteryHistory - измерения аккумуляторов при прошлых запусках
+type BatteryHistory struct {
+	Path string
+}
+
+// DefaultBatteryHistory возвращает историю текущего пользователя
+// в $XDG_STATE_HOME/ububu/battery.json
+func DefaultBatteryHistory() (*BatteryHistory, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &BatteryHistory{Path: filepath.Join(dir, "battery.json")}, nil
+}
+
+// Load возвращает сохраненные измерения, самые старые первыми
+func (h *BatteryHistory) Load() ([]BatteryRecord, error) {
+	var records []BatteryRecord
+	if err := loadState(h.Path, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Save добавляет измерение. За один день хранится одно измерение
+// каждого аккумулятора, чтобы частые запуски не вытесняли старую историю
+func (h *BatteryHistory) Save(record BatteryRecord) error {
+	records, err := h.Load()
+	if err != nil {
+		return err
+	}
+	kept := records[:0]
+	for _, existing := range records {
+		sameDay := existing.Recorded.Format("2006-01-02") == record.Recorded.Format("2006-01-02")
+		if existing.Battery != record.Battery || !sameDay {
+			kept = append(kept, existing)
+		}
+	}
+	records = append(kept, record)
+	if len(records) > batteryHistoryLimit {
+		records = records[len(records)-batteryHistoryLimit:]
+	}
+
+	return saveState(h.Path, records)
+}
+
+// batteryTrend сравнивает текущее измерение с самым старым измерением
+// того же аккумулятора. Возвращает false, если история слишком короткая
+func batteryTrend(records []BatteryRecord, current BatteryRecord) (BatteryTrend, bool) {
+	for _, record := range records {
+		if record.Battery != current.Battery || record.Wear() < 0 || current.Wear() < 0 {
+			continue
+		}
+		period := current.Recorded.Sub(record.Recorded)
+		if period < batteryTrendMin {
+			return BatteryTrend{}, false
+		}
+		trend := BatteryTrend{
+			Days:       int(math.Round(period.Hours() / 24)),
+			WearChange: current.Wear() - record.Wear(),
+		}
+		trend.PerMonth = trend.WearChange / period.Hours() * 24 * 30
+		if record.Cycles >= 0 && current.Cycles >= 0 {
+			trend.Cycles = current.Cycles - record.Cycles
+		}
+		return trend, true
+	}
+	return BatteryTrend{}, false
+}
+
+// RecordBattery сохраняет измерение в историю и возвращает тренд износа
+// по предыдущим запускам
+func RecordBattery(battery Battery) (BatteryTrend, bool) {
+	history, err := DefaultBatteryHistory()
+	if err != nil {
+		return BatteryTrend{}, false
+	}
+	current := BatteryRecord{
+		Battery:  battery.ID(),
+		Recorded: time.Now(),
+		Full:     battery.Full,
+		Design:   battery.Design,
+		Cycles:   battery.Cycles,
+	}
+	records, _ := history.Load()
+	trend, ok := batteryTrend(records, current)
+	// История нужна только для тренда, ошибка записи не мешает проверке
+	history.Save(current)
+	return trend, ok
+}
@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadPowerSupply(t *testing.T) {
+	root := t.TempDir()
+	writeSysFiles(t, filepath.Join(root, "BAT0"), map[string]string{
+		"type":                           "Battery",
+		"present":                        "1",
+		"status":                         "Discharging",
+		"model_name":                     "5B10W13930",
+		"serial_number":                  "1234",
+		"capacity":                       "76",
+		"energy_full":                    "45600000",
+		"energy_full_design":             "57000000",
+		"power_now":                      "9800000",
+		"cycle_count":                    "312",
+		"charge_control_start_threshold": "40",
+		"charge_control_end_threshold":   "80",
+	})
+	// Драйвер сообщает заряд, а не энергию, и ток со знаком минус
+	writeSysFiles(t, filepath.Join(root, "BAT1"), map[string]string{
+		"type":               "Battery",
+		"status":             "Discharging",
+		"capacity":           "50",
+		"charge_full":        "3000000",
+		"charge_full_design": "4000000",
+		"current_now":        "-1000000",
+		"voltage_now":        "11400000",
+		"cycle_count":        "0",
+	})
+	writeSysFiles(t, filepath.Join(root, "hidpp_battery_0"), map[string]string{
+		"type":  "Battery",
+		"scope": "Device",
+	})
+	writeSysFiles(t, filepath.Join(root, "AC"), map[string]string{
+		"type":   "Mains",
+		"online": "0",
+	})
+
+	status := readPowerSupply(root)
+	if !status.HasAC || status.ACOnline {
+		t.Errorf("AC = %v/%v, want present and offline", status.HasAC, status.ACOnline)
+	}
+	if len(status.Batteries) != 2 {
+		t.Fatalf("readPowerSupply() returned %d batteries, want 2 (peripherals skipped)", len(status.Batteries))
+	}
+
+	bat0 := status.Batteries[0]
+	if bat0.Unit != "Wh" || bat0.Cycles != 312 || bat0.Charge != 76 || bat0.StartThreshold != 40 || bat0.EndThreshold != 80 {
+		t.Errorf("BAT0 = %+v", bat0)
+	}
+	if math.Abs(bat0.Wear()-20) > 0.01 || math.Abs(bat0.Power-9.8) > 0.01 {
+		t.Errorf("BAT0 wear = %.2f, power = %.2f, want 20%% and 9.8 W", bat0.Wear(), bat0.Power)
+	}
+	if bat0.ID() != "5B10W13930 1234" {
+		t.Errorf("BAT0 ID = %q", bat0.ID())
+	}
+	if details := bat0.Details(); !strings.Contains(details, "45.6 of 57.0 Wh (20% wear)") || !strings.Contains(details, "thresholds 40-80%") {
+		t.Errorf("Details() = %q", details)
+	}
+
+	bat1 := status.Batteries[1]
+	if bat1.Unit != "Ah" || math.Abs(bat1.Power-11.4) > 0.01 || bat1.Cycles != -1 || bat1.EndThreshold != -1 {
+		t.Errorf("BAT1 = %+v", bat1)
+	}
+	if bat1.ID() != "BAT1" {
+		t.Errorf("BAT1 ID without model = %q", bat1.ID())
+	}
+}
+
+func TestBattery_Findings(t *testing.T) {
+	healthy := Battery{Name: "BAT0", Full: 55, Design: 57, Cycles: 100, EndThreshold: 80}
+	if findings := healthy.Findings(true); len(findings) != 0 {
+		t.Errorf("healthy battery produced findings: %v", findings)
+	}
+
+	worn := Battery{Name: "BAT0", Full: 30, Design: 57, Cycles: 1200, EndThreshold: 100}
+	findings := worn.Findings(true)
+	if worstSeverity(findings) != SeverityCritical || len(findings) != 3 {
+		t.Errorf("worn battery findings = %v", findings)
+	}
+	// Без сети порог заряда не важен
+	if findings := worn.Findings(false); len(findings) != 2 {
+		t.Errorf("findings on battery power = %v", findings)
+	}
+}
+
+func TestBatteryHistory(t *testing.T) {
+	history := &BatteryHistory{Path: filepath.Join(t.TempDir(), "battery.json")}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	history.Save(BatteryRecord{Battery: "X", Recorded: start, Full: 55, Design: 57, Cycles: 100})
+	history.Save(BatteryRecord{Battery: "Y", Recorded: start, Full: 40, Design: 50})
+	// Второе измерение за день заменяет первое
+	history.Save(BatteryRecord{Battery: "X", Recorded: start.Add(2 * time.Hour), Full: 54, Design: 57, Cycles: 101})
+
+	records, err := history.Load()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Load() = %+v, %v, want 2 records", records, err)
+	}
+	if records[1].Battery != "X" || records[1].Cycles != 101 {
+		t.Errorf("latest record = %+v", records[1])
+	}
+
+	current := BatteryRecord{Battery: "X", Recorded: start.Add(60 * 24 * time.Hour), Full: 51.3, Design: 57, Cycles: 161}
+	trend, ok := batteryTrend(records, current)
+	if !ok {
+		t.Fatal("batteryTrend() found no trend over 60 days")
+	}
+	if trend.Days != 60 || trend.Cycles != 60 || math.Abs(trend.WearChange-4.74) > 0.01 || math.Abs(trend.PerMonth-2.37) > 0.01 {
+		t.Errorf("batteryTrend() = %+v", trend)
+	}
+	if got := trend.String(); got != "wear +4.7% over 60 days (+2.4%/month), 60 cycles" {
+		t.Errorf("String() = %q", got)
+	}
+
+	if _, ok := batteryTrend(records, BatteryRecord{Battery: "X", Recorded: start.Add(24 * time.Hour), Full: 54, Design: 57}); ok {
+		t.Error("a one day history should not produce a trend")
+	}
+	if _, ok := batteryTrend(records, BatteryRecord{Battery: "Z", Recorded: current.Recorded, Full: 1, Design: 2}); ok {
+		t.Error("a new battery should not produce a trend")
+	}
+}
@@ -0,0 +1,480 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// bootSlowUnit - юниты, стартующие дольше, считаются кандидатами на оптимизацию
+	bootSlowUnit = 2 * time.Second
+	// bootChangeMin - изменения времени старта меньше этого считаются шумом
+	bootChangeMin = 500 * time.Millisecond
+	// bootHistoryLimit - сколько последних загрузок хранится в истории
+	bootHistoryLimit = 10
+	// bootIDPath - идентификатор текущей загрузки ядра
+	bootIDPath = "/proc/sys/kernel/random/boot_id"
+)
+
+// Действия, которые предлагаются для медленных юнитов
+const (
+	BootDisable = "disable"
+	BootDelay   = "delay"
+)
+
+// bootAdvice - что известно о юните, который часто замедляет загрузку
+type bootAdvice struct {
+	action string
+	reason string
+}
+
+// knownSlowUnits - юниты, которые обычно можно отключить или отложить
+// без вреда для десктопа
+var knownSlowUnits = map[string]bootAdvice{
+	"NetworkManager-wait-online.service":   {BootDisable, "blocks boot until the network is up, rarely needed on desktops"},
+	"systemd-networkd-wait-online.service": {BootDisable, "blocks boot until the network is up, rarely needed on desktops"},
+	"plymouth-quit-wait.service":           {BootDisable, "waits for the boot splash to finish"},
+	"apt-daily.service":                    {BootDelay, "package list refresh, its timer can run later"},
+	"apt-daily-upgrade.service":            {BootDelay, "unattended upgrades, its timer can run later"},
+	"man-db.service":                       {BootDelay, "rebuilds the man page index, its timer can run later"},
+	"fwupd-refresh.service":                {BootDelay, "firmware metadata refresh, its timer can run later"},
+	"snapd.seeded.service":                 {BootDisable, "waits for snapd to seed, not needed without snaps"},
+	"ModemManager.service":                 {BootDisable, "only needed for mobile broadband modems"},
+	"cups.service":                         {BootDelay, "printing can start on demand via cups.socket"},
+	"docker.service":                       {BootDelay, "can start on demand via docker.socket"},
+	"accounts-daemon.service":              {BootDelay, "starts on demand through D-Bus"},
+	"packagekit.service":                   {BootDelay, "starts on demand through D-Bus"},
+	"systemd-journal-flush.service":        {BootDelay, "slow when the journal is large, shrink it with journalctl --vacuum-size"},
+}
+
+// BootTimes - этапы загрузки из systemd-analyze time. Нулевое значение
+// означает, что этап не измерялся (например, firmware в виртуальной машине)
+type BootTimes struct {
+	Firmware  time.Duration
+	Loader    time.Duration
+	Kernel    time.Duration
+	Initrd    time.Duration
+	Userspace time.Duration
+	Total     time.Duration
+}
+
+// UnitTime - время запуска юнита из systemd-analyze blame
+type UnitTime struct {
+	Unit     string
+	Duration time.Duration
+}
+
+// ChainLink - звено критической цепочки: когда юнит стал активен
+// и сколько он запускался
+type ChainLink struct {
+	Unit string
+	At   time.Duration
+	Took time.Duration
+}
+
+// BootSuggestion - юнит, который стоит отключить или запускать позже
+type BootSuggestion struct {
+	Unit     string
+	Action   string
+	Duration time.Duration
+	Reason   string
+}
+
+// String описывает рекомендацию одной строкой
+func (s BootSuggestion) String() string {
+	return fmt.Sprintf("%s %s (%s): %s", s.Action, s.Unit, formatBootDuration(s.Duration), s.Reason)
+}
+
+// UnitChange - насколько изменилось время запуска юнита с прошлой загрузки
+type UnitChange struct {
+	Unit   string
+	Before time.Duration
+	After  time.Duration
+}
+
+// Delta возвращает изменение времени запуска
+func (c UnitChange) Delta() time.Duration {
+	return c.After - c.Before
+}
+
+// BootRecord - сохраненный результат анализа одной загрузки
+type BootRecord struct {
+	BootID   string                   `json:"boot_id"`
+	Recorded time.Time                `json:"recorded"`
+	Times    BootTimes                `json:"times"`
+	Units    map[string]time.Duration `json:"units"`
+}
+
+// BootReport - разобранный вывод systemd-analyze и сравнение с прошлой загрузкой
+type BootReport struct {
+	Times BootTimes
+	Blame []UnitTime
+	Chain []ChainLink
+	// Previous - предыдущая загрузка из истории, nil при первом запуске
+	Previous *BootRecord
+}
+
+var bootStagePattern = regexp.MustCompile(`([0-9][0-9a-z. ]*?) \((firmware|loader|kernel|initrd|userspace)\)`)
+
+// parseSystemdDuration разбирает длительность в формате systemd:
+// "845ms", "2.345s", "1min 2.345s", "1h 2min 3s"
+func parseSystemdDuration(value string) (time.Duration, bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	var total time.Duration
+	for _, field := range fields {
+		field = strings.Replace(field, "min", "m", 1)
+		duration, err := time.ParseDuration(field)
+		if err != nil {
+			return 0, false
+		}
+		total += duration
+	}
+	return total, true
+}
+
+// parseAnalyzeTime разбирает строку вида
+// "Startup finished in 5.1s (firmware) + 3.2s (loader) + 1.2s (kernel) + 10.1s (userspace) = 19.6s"
+func parseAnalyzeTime(output string) (BootTimes, error) {
+	var line string
+	for _, candidate := range strings.Split(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(candidate), "Startup finished in") {
+			line = strings.TrimSpace(candidate)
+			break
+		}
+	}
+	if line == "" {
+		return BootTimes{}, fmt.Errorf("unexpected systemd-analyze time output")
+	}
+
+	var times BootTimes
+	stages, total, _ := strings.Cut(strings.TrimPrefix(line, "Startup finished in"), "=")
+	for _, match := range bootStagePattern.FindAllStringSubmatch(stages, -1) {
+		duration, ok := parseSystemdDuration(match[1])
+		if !ok {
+			continue
+		}
+		switch match[2] {
+		case "firmware":
+			times.Firmware = duration
+		case "loader":
+			times.Loader = duration
+		case "kernel":
+			times.Kernel = duration
+		case "initrd":
+			times.Initrd = duration
+		case "userspace":
+			times.Userspace = duration
+		}
+	}
+
+	if duration, ok := parseSystemdDuration(total); ok {
+		times.Total = duration
+	} else {
+		times.Total = times.Firmware + times.Loader + times.Kernel + times.Initrd + times.Userspace
+	}
+	return times, nil
+}
+
+// parseBlame разбирает systemd-analyze blame: длительность, затем имя юнита
+func parseBlame(r io.Reader) []UnitTime {
+	var units []UnitTime
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		unit := fields[len(fields)-1]
+		duration, ok := parseSystemdDuration(strings.Join(fields[:len(fields)-1], " "))
+		if !ok {
+			continue
+		}
+		units = append(units, UnitTime{Unit: unit, Duration: duration})
+	}
+
+	sort.SliceStable(units, func(i, j int) bool {
+		return units[i].Duration > units[j].Duration
+	})
+	return units
+}
+
+// parseCriticalChain разбирает дерево systemd-analyze critical-chain,
+// например "└─snapd.seeded.service @8.1s +2.0s"
+func parseCriticalChain(r io.Reader) []ChainLink {
+	var chain []ChainLink
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " │├└─")
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], "@") {
+			continue
+		}
+
+		link := ChainLink{Unit: fields[0]}
+		// Время может состоять из нескольких слов: "@1min 2.5s +3s"
+		var at, took []string
+		target := &at
+		for _, field := range fields[1:] {
+			switch {
+			case strings.HasPrefix(field, "@"):
+				target = &at
+				field = strings.TrimPrefix(field, "@")
+			case strings.HasPrefix(field, "+"):
+				target = &took
+				field = strings.TrimPrefix(field, "+")
+			}
+			*target = append(*target, field)
+		}
+		link.At, _ = parseSystemdDuration(strings.Join(at, " "))
+		link.Took, _ = parseSystemdDuration(strings.Join(took, " "))
+		chain = append(chain, link)
+	}
+	return chain
+}
+
+// bootEssential возвращает true для юнитов, без которых система не загрузится:
+// их не стоит предлагать отключать, даже если они медленные
+func bootEssential(unit string) bool {
+	for _, suffix := range []string{".target", ".mount", ".swap", ".slice", ".socket"} {
+		if strings.HasSuffix(unit, suffix) {
+			return true
+		}
+	}
+	for _, prefix := range []string{"systemd-", "dev-", "sys-", "cryptsetup", "lvm2-", "dbus", "init"} {
+		if strings.HasPrefix(unit, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggestions предлагает, что отключить или отложить: медленные юниты из
+// списка известных и небазовые юниты на критической цепочке
+func (r BootReport) Suggestions() []BootSuggestion {
+	var suggestions []BootSuggestion
+	seen := make(map[string]bool)
+
+	for _, unit := range r.Blame {
+		advice, known := knownSlowUnits[unit.Unit]
+		if !known || unit.Duration < bootSlowUnit {
+			continue
+		}
+		seen[unit.Unit] = true
+		suggestions = append(suggestions, BootSuggestion{
+			Unit: unit.Unit, Action: advice.action, Duration: unit.Duration, Reason: advice.reason,
+		})
+	}
+
+	for _, link := range r.Chain {
+		if seen[link.Unit] || link.Took < bootSlowUnit || bootEssential(link.Unit) {
+			continue
+		}
+		seen[link.Unit] = true
+		suggestions = append(suggestions, BootSuggestion{
+			Unit: link.Unit, Action: BootDelay, Duration: link.Took,
+			Reason: "on the critical chain, the desktop waits for it",
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Duration > suggestions[j].Duration
+	})
+	return suggestions
+}
+
+// Changes возвращает юниты, время запуска которых заметно изменилось
+// с прошлой загрузки, самые большие изменения первыми
+func (r BootReport) Changes() []UnitChange {
+	if r.Previous == nil {
+		return nil
+	}
+	var changes []UnitChange
+	for _, unit := range r.Blame {
+		before, ok := r.Previous.Units[unit.Unit]
+		if !ok {
+			continue
+		}
+		change := UnitChange{Unit: unit.Unit, Before: before, After: unit.Duration}
+		if delta := change.Delta(); delta >= bootChangeMin || delta <= -bootChangeMin {
+			changes = append(changes, change)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return absDuration(changes[i].Delta()) > absDuration(changes[j].Delta())
+	})
+	return changes
+}
+
+// Summary описывает загрузку одной строкой со сравнением с прошлой
+func (r BootReport) Summary() string {
+	times := r.Times
+	var stages []string
+	for _, stage := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"firmware", times.Firmware}, {"loader", times.Loader}, {"kernel", times.Kernel},
+		{"initrd", times.Initrd}, {"userspace", times.Userspace},
+	} {
+		if stage.duration > 0 {
+			stages = append(stages, fmt.Sprintf("%s %s", stage.name, formatBootDuration(stage.duration)))
+		}
+	}
+
+	summary := fmt.Sprintf("Boot took %s (%s)", formatBootDuration(times.Total), strings.Join(stages, " + "))
+	if r.Previous != nil {
+		summary += fmt.Sprintf(", %s vs previous boot", formatBootDelta(times.Total-r.Previous.Times.Total))
+	}
+	return summary
+}
+
+// Record возвращает запись для истории загрузок
+func (r BootReport) Record(bootID string) BootRecord {
+	units := make(map[string]time.Duration, len(r.Blame))
+	for _, unit := range r.Blame {
+		units[unit.Unit] = unit.Duration
+	}
+	return BootRecord{BootID: bootID, Recorded: time.Now(), Times: r.Times, Units: units}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// formatBootDuration округляет длительность до удобной для чтения точности
+func formatBootDuration(d time.Duration) string {
+	if absDuration(d) < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// formatBootDelta показывает изменение со знаком: "+1.2s", "-300ms"
+func formatBootDelta(d time.Duration) string {
+	if d >= 0 {
+		return "+" + formatBootDuration(d)
+	}
+	return formatBootDuration(d)
+}
+
+// BootHistory - результаты анализа прошлых загрузок
+type BootHistory struct {
+	Path string
+}
+
+// DefaultBootHistory возвращает историю текущего пользователя
+// в $XDG_STATE_HOME/ububu/boot.json
+func DefaultBootHistory() (*BootHistory, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &BootHistory{Path: filepath.Join(dir, "boot.json")}, nil
+}
+
+// Load возвращает сохраненные загрузки, самые старые первыми
+func (h *BootHistory) Load() ([]BootRecord, error) {
+	var records []BootRecord
+	if err := loadState(h.Path, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Previous возвращает последнюю сохраненную загрузку, отличную от bootID
+func (h *BootHistory) Previous(bootID string) (*BootRecord, error) {
+	records, err := h.Load()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].BootID != bootID {
+			return &records[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Save добавляет запись в историю. Повторный анализ той же загрузки
+// заменяет прежнюю запись, а не добавляет новую
+func (h *BootHistory) Save(record BootRecord) error {
+	records, err := h.Load()
+	if err != nil {
+		return err
+	}
+	kept := records[:0]
+	for _, existing := range records {
+		if existing.BootID != record.BootID {
+			kept = append(kept, existing)
+		}
+	}
+	records = append(kept, record)
+	if len(records) > bootHistoryLimit {
+		records = records[len(records)-bootHistoryLimit:]
+	}
+
+	return saveState(h.Path, records)
+}
+
+// runAnalyze запускает systemd-analyze с аргументами и возвращает вывод
+func runAnalyze(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "systemd-analyze", append(args, "--no-pager")...).CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return "", fmt.Errorf("systemd-analyze %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("systemd-analyze %s: %v", args[0], err)
+	}
+	return string(output), nil
+}
+
+// AnalyzeBoot разбирает systemd-analyze time, blame и critical-chain,
+// сравнивает результат с прошлой загрузкой и сохраняет его в историю
+func AnalyzeBoot() (BootReport, error) {
+	output, err := runAnalyze("time")
+	if err != nil {
+		return BootReport{}, err
+	}
+	times, err := parseAnalyzeTime(output)
+	if err != nil {
+		return BootReport{}, err
+	}
+	report := BootReport{Times: times}
+
+	if output, err := runAnalyze("blame"); err == nil {
+		report.Blame = parseBlame(strings.NewReader(output))
+	}
+	if output, err := runAnalyze("critical-chain"); err == nil {
+		report.Chain = parseCriticalChain(strings.NewReader(output))
+	}
+
+	history, err := DefaultBootHistory()
+	if err != nil {
+		return report, nil
+	}
+	bootID := readSysString(bootIDPath)
+	if previous, err := history.Previous(bootID); err == nil {
+		report.Previous = previous
+	}
+	// История нужна только для сравнения, ошибка записи не мешает анализу
+	history.Save(report.Record(bootID))
+
+	return report, nil
+}
@@ -0,0 +1,208 @@
+package modules
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBlame = `1min 2.345s NetworkManager-wait-online.service
+     3.120s snapd.service
+     2.500s apt-daily.service
+      845ms systemd-journal-flush.service
+      120ms cups.service
+`
+
+const testCriticalChain = `The time when unit became active or started is printed after the "@" character.
+The time the unit took to start is printed after the "+" character.
+
+graphical.target @1min 12.400s
+└─multi-user.target @1min 12.399s
+  └─snapd.service @1min 9.200s +3.120s
+    └─basic.target @5.100s
+      └─sockets.target @5.099s
+        └─systemd-udev-settle.service @2.000s +3.000s
+          └─-.mount @1.100s
+`
+
+func TestParseSystemdDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"845ms", 845 * time.Millisecond},
+		{"2.345s", 2345 * time.Millisecond},
+		{"1min 2.5s", 62500 * time.Millisecond},
+		{"1h 2min 3s", time.Hour + 2*time.Minute + 3*time.Second},
+		{"250us", 250 * time.Microsecond},
+	}
+	for _, tt := range tests {
+		got, ok := parseSystemdDuration(tt.value)
+		if !ok || got != tt.want {
+			t.Errorf("parseSystemdDuration(%q) = %v, %v, want %v", tt.value, got, ok, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "n/a", "3 parsecs"} {
+		if _, ok := parseSystemdDuration(value); ok {
+			t.Errorf("parseSystemdDuration(%q) should fail", value)
+		}
+	}
+}
+
+func TestParseAnalyzeTime(t *testing.T) {
+	output := "Startup finished in 5.100s (firmware) + 3.200s (loader) + 1.234s (kernel) + 2.500s (initrd) + 1min 2.100s (userspace) = 1min 14.134s \n" +
+		"graphical.target reached after 1min 2.000s in userspace.\n"
+	times, err := parseAnalyzeTime(output)
+	if err != nil {
+		t.Fatalf("parseAnalyzeTime() error: %v", err)
+	}
+	want := BootTimes{
+		Firmware:  5100 * time.Millisecond,
+		Loader:    3200 * time.Millisecond,
+		Kernel:    1234 * time.Millisecond,
+		Initrd:    2500 * time.Millisecond,
+		Userspace: 62100 * time.Millisecond,
+		Total:     74134 * time.Millisecond,
+	}
+	if times != want {
+		t.Errorf("parseAnalyzeTime() = %+v, want %+v", times, want)
+	}
+
+	// В виртуальной машине firmware и loader не измеряются
+	times, err = parseAnalyzeTime("Startup finished in 980ms (kernel) + 4.020s (userspace) = 5.000s\n")
+	if err != nil || times.Firmware != 0 || times.Kernel != 980*time.Millisecond || times.Total != 5*time.Second {
+		t.Errorf("parseAnalyzeTime() without firmware = %+v, %v", times, err)
+	}
+
+	if _, err := parseAnalyzeTime("Bootup is not yet finished.\n"); err == nil {
+		t.Error("parseAnalyzeTime() should fail while boot is not finished")
+	}
+}
+
+func TestParseBlame(t *testing.T) {
+	units := parseBlame(strings.NewReader(testBlame))
+	if len(units) != 5 {
+		t.Fatalf("parseBlame() returned %d units, want 5", len(units))
+	}
+	if units[0].Unit != "NetworkManager-wait-online.service" || units[0].Duration != 62345*time.Millisecond {
+		t.Errorf("slowest unit = %+v", units[0])
+	}
+	if units[4].Unit != "cups.service" {
+		t.Errorf("fastest unit = %+v, want cups.service", units[4])
+	}
+}
+
+func TestParseCriticalChain(t *testing.T) {
+	chain := parseCriticalChain(strings.NewReader(testCriticalChain))
+	if len(chain) != 7 {
+		t.Fatalf("parseCriticalChain() returned %d links, want 7", len(chain))
+	}
+	if chain[0].Unit != "graphical.target" || chain[0].At != 72400*time.Millisecond || chain[0].Took != 0 {
+		t.Errorf("chain[0] = %+v", chain[0])
+	}
+	if chain[2].Unit != "snapd.service" || chain[2].At != 69200*time.Millisecond || chain[2].Took != 3120*time.Millisecond {
+		t.Errorf("chain[2] = %+v", chain[2])
+	}
+	if chain[6].Unit != "-.mount" {
+		t.Errorf("chain[6] = %+v, want -.mount", chain[6])
+	}
+}
+
+func TestBootReport_Suggestions(t *testing.T) {
+	report := BootReport{
+		Blame: parseBlame(strings.NewReader(testBlame)),
+		Chain: parseCriticalChain(strings.NewReader(testCriticalChain)),
+	}
+	suggestions := report.Suggestions()
+
+	got := make(map[string]string)
+	for _, suggestion := range suggestions {
+		got[suggestion.Unit] = suggestion.Action
+	}
+	want := map[string]string{
+		"NetworkManager-wait-online.service": BootDisable,
+		"apt-daily.service":                  BootDelay,
+		"snapd.service":                      BootDelay,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Suggestions() = %v, want %v", got, want)
+	}
+	for unit, action := range want {
+		if got[unit] != action {
+			t.Errorf("suggestion for %s = %q, want %q", unit, got[unit], action)
+		}
+	}
+	if suggestions[0].Unit != "NetworkManager-wait-online.service" {
+		t.Errorf("slowest suggestion should come first, got %s", suggestions[0].Unit)
+	}
+}
+
+func TestBootReport_Changes(t *testing.T) {
+	report := BootReport{
+		Times: BootTimes{Total: 20 * time.Second},
+		Blame: parseBlame(strings.NewReader(testBlame)),
+		Previous: &BootRecord{
+			Times: BootTimes{Total: 25 * time.Second},
+			Units: map[string]time.Duration{
+				"NetworkManager-wait-online.service": 2 * time.Second,
+				"snapd.service":                      3 * time.Second,
+				"cups.service":                       2 * time.Second,
+			},
+		},
+	}
+
+	changes := report.Changes()
+	if len(changes) != 2 {
+		t.Fatalf("Changes() = %+v, want 2 changes", changes)
+	}
+	if changes[0].Unit != "NetworkManager-wait-online.service" || changes[0].Delta() <= 0 {
+		t.Errorf("biggest change = %+v", changes[0])
+	}
+	if changes[1].Unit != "cups.service" || changes[1].Delta() >= 0 {
+		t.Errorf("second change = %+v", changes[1])
+	}
+
+	if summary := report.Summary(); !strings.Contains(summary, "-5s vs previous boot") {
+		t.Errorf("Summary() = %q", summary)
+	}
+}
+
+func TestBootHistory(t *testing.T) {
+	history := &BootHistory{Path: filepath.Join(t.TempDir(), "state/boot.json")}
+
+	if previous, err := history.Previous("b"); err != nil || previous != nil {
+		t.Fatalf("Previous() on empty history = %v, %v", previous, err)
+	}
+
+	first := BootRecord{BootID: "a", Times: BootTimes{Total: 30 * time.Second}}
+	if err := history.Save(first); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	// Повторный анализ той же загрузки заменяет запись
+	current := BootRecord{BootID: "b", Times: BootTimes{Total: 20 * time.Second}}
+	history.Save(current)
+	current.Times.Total = 21 * time.Second
+	history.Save(current)
+
+	records, err := history.Load()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Load() = %+v, %v, want 2 records", records, err)
+	}
+	if records[1].Times.Total != 21*time.Second {
+		t.Errorf("latest record = %+v", records[1])
+	}
+
+	previous, err := history.Previous("b")
+	if err != nil || previous == nil || previous.BootID != "a" {
+		t.Errorf("Previous(b) = %+v, %v, want boot a", previous, err)
+	}
+
+	for i := 0; i < bootHistoryLimit+5; i++ {
+		history.Save(BootRecord{BootID: strings.Repeat("x", i+1)})
+	}
+	if records, _ := history.Load(); len(records) != bootHistoryLimit {
+		t.Errorf("history kept %d records, want %d", len(records), bootHistoryLimit)
+	}
+}
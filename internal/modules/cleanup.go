@@ -9,7 +9,69 @@ import (
 	"time"
 )
 
-type CleanupModule struct{}
+type CleanupModule struct {
+	// DevCacheMaxAge - возраст, старше которого файлы кэшей инструментов
+	// удаляются, если у инструмента нет своей команды очистки (по умолчанию 30 дней)
+	DevCacheMaxAge time.Duration
+	
+	// WorkspaceRoots - каталоги, в которых ищутся заброшенные проекты
+	// (по умолчанию ~/projects, ~/src, ~/code, ~/work, ~/dev и ~/go/src).
+	// Относительные пути и пути с ~/ отсчитываются от домашнего каталога каждого пользователя
+	WorkspaceRoots []string
+	// StaleProjectAge - сколько проект не должен меняться, чтобы его
+	// артефакты сборки считались ненужными (по умолчанию 90 дней)
+	StaleProjectAge time.Duration
+	
+	// SnapRetain - сколько отключенных ревизий каждого snap-пакета оставить
+	// для отката (по умолчанию все отключенные ревизии удаляются)
+	SnapRetain int
+	
+	// PurgePackages - пакеты, выбранные для удаления: библиотеки-сироты и пакеты,
+	// от которых остались только файлы конфигурации. Без выбора пакеты не удаляются
+	PurgePackages []string
+	
+	// KernelsToKeep - сколько самых новых ядер оставить помимо запущенного (по умолчанию 2)
+	KernelsToKeep int
+	
+	// QuarantineRetention - сколько хранятся файлы в карантине, прежде чем
+	// очистка удалит их окончательно (по умолчанию 7 дней)
+	QuarantineRetention time.Duration
+	
+	// OversizedLogSize - активные логи больше этого размера попадают в отчет (по умолчанию 100 МБ)
+	OversizedLogSize int64
+	// RotatedLogMaxAge - ротированные логи старше этого возраста удаляются (по умолчанию 7 дней)
+	RotatedLogMaxAge time.Duration
+	// JournalMaxSize - если задан, журнал systemd дополнительно сжимается до этого размера
+	JournalMaxSize int64
+	// JournaldMaxUse - если задан, лимит журнала закрепляется в drop-in конфигурации journald
+	JournaldMaxUse int64
+	
+	// CrashMaxAge - отчеты о падениях и дампы памяти старше этого возраста удаляются (по умолчанию 14 дней)
+	CrashMaxAge time.Duration
+	// CrashTotalLimit - сколько места могут занимать оставшиеся дампы (по умолчанию 1 ГБ)
+	CrashTotalLimit int64
+	
+	// CurrentUserOnly - даже от root очищать только файлы самого root.
+	// По умолчанию root очищает домашние каталоги всех обычных пользователей
+	CurrentUserOnly bool
+	// MinUserUID и MaxUserUID - диапазон UID обычных пользователей в /etc/passwd
+	// (по умолчанию 1000-59999)
+	MinUserUID int
+	MaxUserUID int
+	
+	// Results - итоги последнего запуска по категориям
+	Results []CleanupResult
+	
+	scanner *Scanner
+	// previewedAt - когда Preview последний раз заполнил кэш сканера
+	previewedAt time.Time
+	// ctx - контекст текущего Preview или Execute, им прерываются обходы дисков
+	ctx context.Context
+}
+
+// previewFreshness - сколько Execute доверяет размерам, посчитанным Preview.
+// Более старый предпросмотр пересчитывается заново
+const previewFreshness = 10 * time.Minute
 
 func (m *CleanupModule) GetName() string {
 	return "System Cleanup"
@@ -23,160 +85,316 @@ func (m *CleanupModule) RequiresRoot() bool {
 	return false
 }
 
+// CleanupItem описывает одну позицию предпросмотра очистки
+type CleanupItem struct {
+	Category string
+	Path     string
+	Size     int64
+	// Optional - позиция удаляется, только если выбрана в PurgePackages
+	Optional bool
+	// Program и Modified - упавшая программа и время дампа, если известны
+	Program  string
+	Modified time.Time
+}
+
+// cleanupStep описывает один шаг очистки в Execute.
+// report позволяет шагу сообщать о промежуточных результатах
+type cleanupStep struct {
+	start string
+	done  string
+	run   func(report func(message string)) (int64, error)
+}
+
+// userCleanupStep - шаг очистки, который выполняется для каждого пользователя
+type userCleanupStep struct {
+	start string
+	done  string
+	run   func(account SystemUser, report func(message string)) (int64, error)
+}
+
+// quiet адаптирует шаг без промежуточных сообщений к cleanupStep
+func quiet(run func() (int64, error)) func(report func(message string)) (int64, error) {
+	return func(func(message string)) (int64, error) {
+		return run()
+	}
+}
+
+// inHome адаптирует функцию очистки домашнего каталога к userCleanupStep
+func inHome(run func(homeDir string) (int64, error)) func(account SystemUser, report func(message string)) (int64, error) {
+	return func(account SystemUser, _ func(message string)) (int64, error) {
+		return run(account.Home)
+	}
+}
+
 func (m *CleanupModule) Execute(progressCallback func(progress float64, message string)) error {
-	var totalFreed int64
+	return m.ExecuteContext(context.Background(), progressCallback)
+}
+
+// ExecuteContext выполняет очистку, прерывая ее при отмене ctx.
+// Размеры, посчитанные недавним Preview, используются повторно, чтобы
+// предпросмотр и удаление не обходили одно и то же дерево дважды
+func (m *CleanupModule) ExecuteContext(ctx context.Context, progressCallback func(progress float64, message string)) error {
+	m.ctx = ctx
+	defer func() { m.ctx = nil }()
 	
-	progressCallback(0.1, "Cleaning package cache...")
-	freed, err := m.cleanPackageCache()
-	if err == nil {
-		totalFreed += freed
-		progressCallback(0.25, fmt.Sprintf("Package cache cleaned: %d MB freed", freed/1024/1024))
+	// Устаревший предпросмотр не в счет: диск мог измениться, размеры считаются заново.
+	// Следующий запуск тоже начнет с пустого кэша
+	if m.previewedAt.IsZero() || time.Since(m.previewedAt) > previewFreshness {
+		m.diskScanner().Reset()
 	}
+	m.previewedAt = time.Time{}
 	
-	progressCallback(0.3, "Cleaning browser caches...")
-	freed, err = m.cleanBrowserCache()
-	if err == nil {
-		totalFreed += freed
-		progressCallback(0.5, fmt.Sprintf("Browser cache cleaned: %d MB freed", freed/1024/1024))
+	users, err := m.cleanupUsers()
+	if err != nil {
+		return err
 	}
 	
-	progressCallback(0.6, "Cleaning temporary files...")
-	freed, err = m.cleanTempFiles()
-	if err == nil {
-		totalFreed += freed
-		progressCallback(0.75, fmt.Sprintf("Temp files cleaned: %d MB freed", freed/1024/1024))
+	steps := []cleanupStep{
+		{"Cleaning package cache...", "Package cache cleaned", quiet(m.cleanPackageCache)},
+		{"Removing disabled snap revisions...", "Snap revisions removed", m.cleanSnapRevisions},
+		{"Removing unused Flatpak runtimes...", "Flatpak runtimes removed", m.cleanFlatpak},
+		{"Removing old kernels...", "Old kernels removed", m.cleanOldKernels},
+		{"Removing crash dumps...", "Crash dumps removed", m.cleanCrashDumps},
+		{"Cleaning temporary files...", "Temp files cleaned", quiet(m.cleanSystemTemp)},
+		{"Cleaning old logs...", "Old logs cleaned", m.cleanOldLogs},
+	}
+	
+	userSteps := []userCleanupStep{
+		{"Cleaning browser caches...", "Browser cache cleaned", inHome(m.cleanBrowserCacheFor)},
+		{"Cleaning developer caches...", "Developer caches cleaned", m.cleanDevCachesFor},
+		{"Sweeping stale build artifacts...", "Stale build artifacts removed", func(account SystemUser, report func(message string)) (int64, error) {
+			return m.cleanStaleArtifactsFor(account.Home, report)
+		}},
+		{"Cleaning Flatpak app caches...", "Flatpak caches cleaned", func(account SystemUser, report func(message string)) (int64, error) {
+			return m.cleanFlatpakCachesFor(account.Home, report)
+		}},
+		{"Cleaning user caches and trash...", "User caches cleaned", inHome(m.cleanUserTemp)},
+		{"Cleaning user logs...", "User logs cleaned", inHome(m.cleanUserLogs)},
+		{"Purging expired quarantine...", "Quarantine purged", inHome(m.purgeQuarantine)},
+	}
+	
+	m.Results = nil
+	var totalFreed, totalReclaimed int64
+	
+	// Каждому шагу отводится равная доля общего прогресса
+	total := len(steps) + len(users)*len(userSteps)
+	done := 0
+	
+	// runStep выполняет шаг и запоминает, сколько он удалил и сколько места вернулось
+	runStep := func(start, finished, user string, run func(report func(message string)) (int64, error)) (int64, int64) {
+		prefix := ""
+		if user != "" {
+			prefix = user + ": "
+		}
+		base := 0.05 + 0.9*float64(done)/float64(total)
+		done++
+		if ctx.Err() != nil {
+			return 0, 0
+		}
+		progressCallback(base, prefix+start)
+		
+		// Свободное место меряем по файловым системам до и после шага,
+		// чтобы видеть, сколько на самом деле вернулось на диск
+		before := takeFSSnapshot()
+		freed, err := run(func(message string) {
+			progressCallback(base, prefix+message)
+		})
+		reclaimed := before.reclaimedSince(takeFSSnapshot())
+		if err != nil {
+			return 0, 0
+		}
+		
+		m.Results = append(m.Results, CleanupResult{Category: finished, User: user, Deleted: freed, Reclaimed: reclaimed})
+		progressCallback(base+0.9/float64(total), fmt.Sprintf("%s%s: %d MB freed (%d MB reclaimed on disk)",
+			prefix, finished, freed/1024/1024, reclaimed/1024/1024))
+		return freed, reclaimed
 	}
 	
-	progressCallback(0.8, "Cleaning old logs...")
-	freed, err = m.cleanOldLogs()
-	if err == nil {
+	for _, step := range steps {
+		freed, reclaimed := runStep(step.start, step.done, "", step.run)
 		totalFreed += freed
-		progressCallback(0.9, fmt.Sprintf("Old logs cleaned: %d MB freed", freed/1024/1024))
+		totalReclaimed += reclaimed
 	}
 	
-	progressCallback(1.0, fmt.Sprintf("Cleanup completed! Total freed: %d MB", totalFreed/1024/1024))
+	for _, account := range users {
+		// Имя пользователя в сообщениях нужно, только если их несколько
+		name := ""
+		if len(users) > 1 {
+			name = account.Name
+		}
+		
+		var userFreed, userReclaimed int64
+		for _, step := range userSteps {
+			run := step.run
+			freed, reclaimed := runStep(step.start, step.done, name, func(report func(message string)) (int64, error) {
+				return run(account, report)
+			})
+			userFreed += freed
+			userReclaimed += reclaimed
+		}
+		totalFreed += userFreed
+		totalReclaimed += userReclaimed
+		
+		if len(users) > 1 {
+			progressCallback(0.05+0.9*float64(done)/float64(total), fmt.Sprintf("👤 %s: %d MB freed (%d MB reclaimed on disk)",
+				account.Name, userFreed/1024/1024, userReclaimed/1024/1024))
+		}
+	}
+	
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	progressCallback(1.0, fmt.Sprintf("Cleanup completed! Total freed: %d MB (%d MB reclaimed on disk)",
+		totalFreed/1024/1024, totalReclaimed/1024/1024))
 	
 	return nil
 }
 
+// Preview оценивает, что будет удалено, ничего не трогая на диске
+func (m *CleanupModule) Preview() ([]CleanupItem, error) {
+	return m.PreviewContext(context.Background())
+}
+
+// PreviewContext - Preview, который прерывается при отмене ctx. Посчитанные
+// размеры остаются в кэше, и Execute сразу после него не обходит диск повторно
+func (m *CleanupModule) PreviewContext(ctx context.Context) ([]CleanupItem, error) {
+	m.ctx = ctx
+	defer func() { m.ctx = nil }()
+	
+	m.diskScanner().Reset()
+	m.previewedAt = time.Time{}
+	
+	users, err := m.cleanupUsers()
+	if err != nil {
+		return nil, err
+	}
+	
+	var items []CleanupItem
+	var homeDirs []string
+	for _, account := range users {
+		homeDirs = append(homeDirs, account.Home)
+		for _, cachePath := range browserCachePaths(account.Home) {
+			if size, err := m.getDirSize(cachePath); err == nil && size > 0 {
+				items = append(items, CleanupItem{Category: "Browser cache", Path: cachePath, Size: size})
+			}
+		}
+		items = append(items, m.previewDevCaches(account.Home)...)
+		items = append(items, m.previewStaleArtifacts(account.Home)...)
+		items = append(items, m.previewQuarantine(account.Home)...)
+	}
+	
+	items = append(items, m.previewPackages()...)
+	items = append(items, m.previewSnapRevisions()...)
+	items = append(items, m.previewFlatpak(homeDirs)...)
+	items = append(items, m.previewOldKernels()...)
+	items = append(items, m.previewCrashDumps(homeDirs)...)
+	items = append(items, m.previewLogs()...)
+	
+	// Прерванный предпросмотр посчитал не все, Execute на него не полагается
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.previewedAt = time.Now()
+	
+	return items, nil
+}
+
 func (m *CleanupModule) cleanPackageCache() (int64, error) {
-	var totalSize int64
+	const archivesDir = "/var/cache/apt/archives"
 	
 	// Создаем контекст с таймаутом
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 	
 	// Получаем размер кэша перед очисткой
-	cmd := exec.CommandContext(ctx, "du", "-sb", "/var/cache/apt/archives")
-	output, err := cmd.Output()
-	if err == nil {
-		fmt.Sscanf(string(output), "%d", &totalSize)
-	}
+	before := measurePath(archivesDir).Allocated
 	
 	// Очищаем кэш пакетов (без sudo для избежания зависания)
-	cmd = exec.CommandContext(ctx, "apt", "clean")
+	cmd := exec.CommandContext(ctx, "apt", "clean")
 	cmd.Run() // Игнорируем ошибки
 	
 	// Удаляем неиспользуемые пакеты (без sudo)
 	cmd = exec.CommandContext(ctx, "apt", "autoremove", "-y")
 	cmd.Run() // Игнорируем ошибки
 	
-	return totalSize, nil
+	// Без прав root apt clean ничего не удалит, поэтому считаем по факту
+	freed := before - measurePath(archivesDir).Allocated
+	
+	// Удаляем выбранные остатки конфигураций и библиотеки-сироты
+	freed += m.purgePackages()
+	
+	return freed, nil
 }
 
 func (m *CleanupModule) cleanBrowserCache() (int64, error) {
-	var totalSize int64
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return 0, err
 	}
+	return m.cleanBrowserCacheFor(homeDir)
+}
+
+func (m *CleanupModule) cleanBrowserCacheFor(homeDir string) (int64, error) {
+	var totalSize int64
+	for _, cachePath := range browserCachePaths(homeDir) {
+		totalSize += m.removeMeasured(cachePath, false)
+	}
 	
-	// Пути к кэшам браузеров
-	cachePaths := []string{
+	return totalSize, nil
+}
+
+// browserCachePaths возвращает пути к кэшам браузеров
+func browserCachePaths(homeDir string) []string {
+	return []string{
 		filepath.Join(homeDir, ".cache/google-chrome"),
 		filepath.Join(homeDir, ".cache/chromium"),
 		filepath.Join(homeDir, ".cache/firefox"),
 		filepath.Join(homeDir, ".cache/mozilla"),
 		filepath.Join(homeDir, ".cache/thumbnails"),
 	}
-	
-	for _, cachePath := range cachePaths {
-		if size, err := m.getDirSize(cachePath); err == nil {
-			totalSize += size
-			os.RemoveAll(cachePath)
-		}
-	}
-	
-	return totalSize, nil
 }
 
-func (m *CleanupModule) cleanTempFiles() (int64, error) {
+func (m *CleanupModule) cleanSystemTemp() (int64, error) {
+	// Для /tmp очищаем только файлы, к которым не обращались больше недели
+	freed := pruneByAccessTime("/tmp", 7*24*time.Hour)
+	m.diskScanner().Invalidate("/tmp")
+	return freed, nil
+}
+
+func (m *CleanupModule) cleanUserTemp(homeDir string) (int64, error) {
 	var totalSize int64
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return 0, err
-	}
 	
-	// Временные папки
+	// Временные папки, которые очищаются целиком
 	tempPaths := []string{
-		"/tmp",
 		filepath.Join(homeDir, ".cache"),
 		filepath.Join(homeDir, ".local/share/Trash"),
 	}
 	
 	for _, tempPath := range tempPaths {
-		if tempPath == "/tmp" {
-			// Для /tmp очищаем только старые файлы
-			cmd := exec.Command("find", "/tmp", "-type", "f", "-atime", "+7", "-delete")
-			cmd.Run()
-		} else {
-			if size, err := m.getDirSize(tempPath); err == nil {
-				totalSize += size
-				os.RemoveAll(tempPath)
-				os.MkdirAll(tempPath, 0755) // Пересоздаем папку
-			}
-		}
+		totalSize += m.removeMeasured(tempPath, true) // Пересоздаем папку
 	}
 	
 	return totalSize, nil
 }
 
-func (m *CleanupModule) cleanOldLogs() (int64, error) {
-	var totalSize int64
-	
-	// Создаем контекст с таймаутом
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-	defer cancel()
-	
-	// Очищаем системные логи старше 7 дней (без sudo)
-	cmd := exec.CommandContext(ctx, "journalctl", "--vacuum-time=7d")
-	cmd.Run() // Игнорируем ошибки
-	
-	// Очищаем старые логи в домашней папке пользователя
-	homeDir, err := os.UserHomeDir()
-	if err == nil {
-		logPath := filepath.Join(homeDir, ".local/share/logs")
-		if size, err := m.getDirSize(logPath); err == nil {
-			totalSize += size
-			os.RemoveAll(logPath)
-		}
+// getDirSize возвращает место, занимаемое деревом на диске. Результаты
+// кэшируются до удаления, поэтому предпросмотр и очистка не обходят дерево дважды
+func (m *CleanupModule) getDirSize(path string) (int64, error) {
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	
-	return totalSize, nil
+	usage, err := m.diskScanner().Scan(ctx, path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return usage.Allocated, err
 }
 
-func (m *CleanupModule) getDirSize(path string) (int64, error) {
-	var size int64
-	
-	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Игнорируем ошибки доступа
-		}
-		if !info.IsDir() {
-			size += info.Size()
-		}
-		return nil
-	})
-	
-	return size, err
+func (m *CleanupModule) diskScanner() *Scanner {
+	if m.scanner == nil {
+		m.scanner = NewScanner()
+	}
+	return m.scanner
 }
@@ -1,10 +1,13 @@
 package modules
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestCleanupModule_GetName(t *testing.T) {
@@ -96,7 +99,12 @@ func TestCleanupModule_GetDirSize(t *testing.T) {
 		t.Errorf("getDirSize() returned error: %v", err)
 	}
 	
-	expectedSize := int64(len(testContent))
+	// Размер считается по занятым на диске блокам, а не по длине содержимого
+	info, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("Failed to stat test file: %v", err)
+	}
+	expectedSize := info.Sys().(*syscall.Stat_t).Blocks * 512
 	if size != expectedSize {
 		t.Errorf("getDirSize() = %d, want %d", size, expectedSize)
 	}
@@ -119,6 +127,55 @@ func TestCleanupModule_GetDirSize_NonExistent(t *testing.T) {
 	}
 }
 
+func TestCleanupModule_ExecuteReusesFreshPreview(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "cache.dat"), make([]byte, 4096), 0644)
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	
+	// Свежий предпросмотр: Execute берет размеры из кэша, а не обходит диск снова
+	module := &CleanupModule{}
+	module.getDirSize(dir)
+	module.previewedAt = time.Now()
+	if err := module.ExecuteContext(ctx, func(float64, string) {}); err != context.Canceled {
+		t.Errorf("ExecuteContext() with cancelled context = %v, want %v", err, context.Canceled)
+	}
+	if _, ok := module.diskScanner().Cached(dir); !ok {
+		t.Error("Execute after a fresh preview should keep the scanner cache")
+	}
+	if len(module.Results) != 0 {
+		t.Errorf("cancelled Execute ran %d steps", len(module.Results))
+	}
+	
+	// Следующий запуск уже не считается продолжением предпросмотра
+	module.ExecuteContext(ctx, func(float64, string) {})
+	if _, ok := module.diskScanner().Cached(dir); ok {
+		t.Error("second Execute should start with an empty cache")
+	}
+	
+	// Устаревший предпросмотр пересчитывается
+	module.getDirSize(dir)
+	module.previewedAt = time.Now().Add(-2 * previewFreshness)
+	module.ExecuteContext(ctx, func(float64, string) {})
+	if _, ok := module.diskScanner().Cached(dir); ok {
+		t.Error("Execute after a stale preview should reset the scanner cache")
+	}
+}
+
+func TestCleanupModule_PreviewCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	
+	module := &CleanupModule{CurrentUserOnly: true}
+	if _, err := module.PreviewContext(ctx); err != context.Canceled {
+		t.Errorf("PreviewContext() with cancelled context = %v, want %v", err, context.Canceled)
+	}
+	if !module.previewedAt.IsZero() {
+		t.Error("cancelled preview should not count as fresh")
+	}
+}
+
 func TestCleanupModule_CleanPackageCache(t *testing.T) {
 	module := &CleanupModule{}
 	
@@ -0,0 +1,419 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContainerModule освобождает место, занятое Docker и Podman: висячие образы,
+// остановленные контейнеры, неиспользуемые тома и кэш сборки
+type ContainerModule struct {
+	// Sockets - unix-сокеты API движков. По умолчанию проверяются стандартные
+	// сокеты Docker и Podman (системный и rootless)
+	Sockets []string
+	// PruneUntil - удалять только объекты старше этого возраста (0 - любые)
+	PruneUntil time.Duration
+	// LabelFilters - фильтры по меткам в формате Docker: "key", "key=value",
+	// а с префиксом "!" - исключить объекты с такой меткой
+	LabelFilters []string
+	// PruneNamedVolumes - удалять и именованные тома без контейнеров. По умолчанию
+	// удаляются только анонимные тома: в именованных обычно лежат данные,
+	// например базы остановленных compose-проектов
+	PruneNamedVolumes bool
+}
+
+// ContainerUsage - место, которое можно освободить, по типам ресурсов
+type ContainerUsage struct {
+	Images     int64
+	Containers int64
+	Volumes    int64
+	BuildCache int64
+}
+
+// Total возвращает суммарный объем, который можно освободить
+func (u ContainerUsage) Total() int64 {
+	return u.Images + u.Containers + u.Volumes + u.BuildCache
+}
+
+// containerEngine - клиент API одного движка поверх unix-сокета
+type containerEngine struct {
+	name   string
+	socket string
+	client *http.Client
+}
+
+func (m *ContainerModule) GetName() string {
+	return "Container Cleanup"
+}
+
+func (m *ContainerModule) GetDescription() string {
+	return "Clean unused Docker and Podman images, containers and volumes"
+}
+
+func (m *ContainerModule) RequiresRoot() bool {
+	return false
+}
+
+func (m *ContainerModule) Execute(progressCallback func(progress float64, message string)) error {
+	progressCallback(0.1, "Looking for container engines...")
+
+	engines := m.engines()
+	if len(engines) == 0 {
+		progressCallback(1.0, "No container engine found, nothing to clean")
+		return nil
+	}
+
+	var totalFreed int64
+	for i, engine := range engines {
+		base := 0.2 + 0.7*float64(i)/float64(len(engines))
+		step := 0.7 / float64(len(engines))
+
+		volumes := engine.volumePrune(m.PruneNamedVolumes)
+		usage, err := engine.reclaimable(volumes)
+		if err != nil {
+			progressCallback(base, fmt.Sprintf("%s: failed to read disk usage: %v", engine.name, err))
+			continue
+		}
+		progressCallback(base, fmt.Sprintf("%s reclaimable: images %d MB • containers %d MB • volumes %d MB • build cache %d MB",
+			engine.name, usage.Images/1024/1024, usage.Containers/1024/1024, usage.Volumes/1024/1024, usage.BuildCache/1024/1024))
+
+		progressCallback(base+step/2, fmt.Sprintf("Pruning %s resources...", engine.name))
+		freed, err := engine.prune(m.filters(), volumes)
+		totalFreed += freed
+		if err != nil {
+			progressCallback(base+step, fmt.Sprintf("%s: prune failed: %v", engine.name, err))
+			continue
+		}
+		progressCallback(base+step, fmt.Sprintf("%s: %d MB freed", engine.name, freed/1024/1024))
+	}
+
+	progressCallback(1.0, fmt.Sprintf("Container cleanup completed! Total freed: %d MB", totalFreed/1024/1024))
+
+	return nil
+}
+
+// Preview возвращает объем, который можно освободить, по сокетам найденных
+// движков: у одного движка может быть и системный, и rootless сокет
+func (m *ContainerModule) Preview() map[string]ContainerUsage {
+	result := make(map[string]ContainerUsage)
+	for _, engine := range m.engines() {
+		if usage, err := engine.reclaimable(engine.volumePrune(m.PruneNamedVolumes)); err == nil {
+			result[engine.socket] = usage
+		}
+	}
+	return result
+}
+
+func (m *ContainerModule) sockets() []string {
+	if len(m.Sockets) > 0 {
+		return m.Sockets
+	}
+
+	sockets := []string{"/var/run/docker.sock", "/run/podman/podman.sock"}
+	if host := os.Getenv("DOCKER_HOST"); strings.HasPrefix(host, "unix://") {
+		sockets = append([]string{strings.TrimPrefix(host, "unix://")}, sockets...)
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		sockets = append(sockets,
+			filepath.Join(runtimeDir, "docker.sock"),
+			filepath.Join(runtimeDir, "podman/podman.sock"))
+	}
+	return sockets
+}
+
+// engines возвращает клиентов для всех существующих сокетов
+func (m *ContainerModule) engines() []*containerEngine {
+	var engines []*containerEngine
+	seen := make(map[string]bool)
+
+	for _, socket := range m.sockets() {
+		if seen[socket] {
+			continue
+		}
+		seen[socket] = true
+
+		if info, err := os.Stat(socket); err != nil || info.Mode()&os.ModeSocket == 0 {
+			continue
+		}
+		engines = append(engines, newContainerEngine(socket))
+	}
+
+	return engines
+}
+
+func newContainerEngine(socket string) *containerEngine {
+	name := "Docker"
+	if strings.Contains(socket, "podman") {
+		name = "Podman"
+	}
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+
+	return &containerEngine{
+		name:   name,
+		socket: socket,
+		// Очистка кэша сборки на больших машинах может занимать минуты
+		client: &http.Client{Transport: transport, Timeout: 5 * time.Minute},
+	}
+}
+
+// filters собирает параметр filters API из настроек модуля
+func (m *ContainerModule) filters() map[string][]string {
+	filters := make(map[string][]string)
+	if m.PruneUntil > 0 {
+		filters["until"] = []string{m.PruneUntil.String()}
+	}
+	for _, label := range m.LabelFilters {
+		if strings.HasPrefix(label, "!") {
+			filters["label!"] = append(filters["label!"], strings.TrimPrefix(label, "!"))
+		} else {
+			filters["label"] = append(filters["label"], label)
+		}
+	}
+	return filters
+}
+
+// do выполняет запрос к API и декодирует JSON-ответ в out
+func (e *containerEngine) do(method, path string, query url.Values, out interface{}) error {
+	u := url.URL{Scheme: "http", Host: "localhost", Path: path, RawQuery: query.Encode()}
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotSupported
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("%s %s: %s (HTTP %d)", method, path, apiErr.Message, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// errNotSupported - движок не поддерживает запрошенный метод API
+// (например, Podman не реализует /build/prune)
+var errNotSupported = fmt.Errorf("not supported by container engine")
+
+// anonymousVolumeLabel - метка, которой Docker помечает анонимные тома
+const anonymousVolumeLabel = "com.docker.volume.anonymous"
+
+// volumePrune - какие тома удаляет очистка
+type volumePrune int
+
+const (
+	// volumesKept - тома не трогаются
+	volumesKept volumePrune = iota
+	// volumesAnonymous - только анонимные тома без контейнеров
+	volumesAnonymous
+	// volumesAll - все тома без контейнеров, включая именованные
+	volumesAll
+)
+
+// anonymousOnlyPrune сообщает, что /volumes/prune по умолчанию удаляет только
+// анонимные тома. Так ведет себя Docker начиная с API 1.42, а более старые
+// версии и Podman удаляют и именованные тома
+func (e *containerEngine) anonymousOnlyPrune() bool {
+	if e.name != "Docker" {
+		return false
+	}
+	var version struct {
+		ApiVersion string
+	}
+	if err := e.do(http.MethodGet, "/version", nil, &version); err != nil {
+		return false
+	}
+	return apiVersionAtLeast(version.ApiVersion, 1, 42)
+}
+
+// volumePrune выбирает, какие тома удалить. Если движок не умеет удалять
+// только анонимные тома, без явного согласия тома не трогаются вовсе
+func (e *containerEngine) volumePrune(named bool) volumePrune {
+	switch {
+	case named:
+		return volumesAll
+	case e.anonymousOnlyPrune():
+		return volumesAnonymous
+	default:
+		return volumesKept
+	}
+}
+
+// apiVersionAtLeast сравнивает версию API вида "1.43" с major.minor
+func apiVersionAtLeast(version string, major, minor int) bool {
+	majorPart, minorPart, _ := strings.Cut(version, ".")
+	gotMajor, err := strconv.Atoi(majorPart)
+	if err != nil {
+		return false
+	}
+	gotMinor, _ := strconv.Atoi(minorPart)
+	return gotMajor > major || gotMajor == major && gotMinor >= minor
+}
+
+// reclaimable считает место, которое освободит очистка, по данным /system/df.
+// Тома учитываются те же, что удалит prune с тем же volumes
+func (e *containerEngine) reclaimable(volumes volumePrune) (ContainerUsage, error) {
+	var df struct {
+		Images []struct {
+			RepoTags   []string
+			Size       int64
+			SharedSize int64
+			Containers int64
+		}
+		Containers []struct {
+			State  string
+			SizeRw int64
+		}
+		Volumes []struct {
+			Labels    map[string]string
+			UsageData struct {
+				Size     int64
+				RefCount int64
+			}
+		}
+		BuildCache []struct {
+			Size  int64
+			InUse bool
+		}
+	}
+
+	if err := e.do(http.MethodGet, "/system/df", nil, &df); err != nil {
+		return ContainerUsage{}, err
+	}
+
+	var usage ContainerUsage
+	for _, image := range df.Images {
+		if image.Containers == 0 && isDanglingImage(image.RepoTags) {
+			// Общие слои остаются на диске, пока их использует другой образ
+			usage.Images += image.Size - max(image.SharedSize, 0)
+		}
+	}
+	for _, container := range df.Containers {
+		if container.State != "running" && container.State != "paused" {
+			usage.Containers += container.SizeRw
+		}
+	}
+	for _, volume := range df.Volumes {
+		if volume.UsageData.RefCount != 0 || volume.UsageData.Size <= 0 {
+			continue
+		}
+		_, anonymous := volume.Labels[anonymousVolumeLabel]
+		if volumes == volumesAll || volumes == volumesAnonymous && anonymous {
+			usage.Volumes += volume.UsageData.Size
+		}
+	}
+	for _, cache := range df.BuildCache {
+		if !cache.InUse {
+			usage.BuildCache += cache.Size
+		}
+	}
+
+	return usage, nil
+}
+
+func isDanglingImage(repoTags []string) bool {
+	for _, tag := range repoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+// prune удаляет неиспользуемые ресурсы и возвращает освобожденное место
+func (e *containerEngine) prune(filters map[string][]string, volumes volumePrune) (int64, error) {
+	// Тома не поддерживают фильтр until, а образы чистим только висячие
+	containerFilters := filters
+	imageFilters := withFilter(filters, "dangling", "true")
+	volumeFilters := withoutFilter(filters, "until")
+	if volumes == volumesAll && e.anonymousOnlyPrune() {
+		// Именованные тома Docker с API 1.42 удаляет только с all=true
+		volumeFilters = withFilter(volumeFilters, "all", "true")
+	}
+	buildFilters := withoutFilter(filters, "label", "label!")
+
+	var totalFreed int64
+	var errs []string
+
+	for _, target := range []struct {
+		path    string
+		filters map[string][]string
+	}{
+		{"/containers/prune", containerFilters},
+		{"/images/prune", imageFilters},
+		{"/volumes/prune", volumeFilters},
+		{"/build/prune", buildFilters},
+	} {
+		if target.path == "/volumes/prune" && volumes == volumesKept {
+			continue
+		}
+		query := url.Values{}
+		if len(target.filters) > 0 {
+			encoded, _ := json.Marshal(target.filters)
+			query.Set("filters", string(encoded))
+		}
+
+		var result struct {
+			SpaceReclaimed int64
+		}
+		err := e.do(http.MethodPost, target.path, query, &result)
+		if err == errNotSupported {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		totalFreed += result.SpaceReclaimed
+	}
+
+	if len(errs) > 0 {
+		return totalFreed, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return totalFreed, nil
+}
+
+func withFilter(filters map[string][]string, key, value string) map[string][]string {
+	result := make(map[string][]string, len(filters)+1)
+	for k, v := range filters {
+		result[k] = v
+	}
+	result[key] = append(result[key], value)
+	return result
+}
+
+func withoutFilter(filters map[string][]string, keys ...string) map[string][]string {
+	result := make(map[string][]string, len(filters))
+	for k, v := range filters {
+		result[k] = v
+	}
+	for _, key := range keys {
+		delete(result, key)
+	}
+	return result
+}
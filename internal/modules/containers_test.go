@@ -0,0 +1,257 @@
+package modules
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEngine - поддельный сервер API Docker на unix-сокете
+type fakeEngine struct {
+	mu      sync.Mutex
+	filters map[string]string
+	socket  string
+	// apiVersion отдается в /version, пустая строка - метод не поддерживается
+	apiVersion string
+}
+
+const fakeSystemDF = `{
+	"Images": [
+		{"RepoTags": ["<none>:<none>"], "Size": 300, "SharedSize": 100, "Containers": 0},
+		{"RepoTags": [], "Size": 50, "SharedSize": -1, "Containers": 0},
+		{"RepoTags": ["nginx:latest"], "Size": 1000, "SharedSize": 0, "Containers": 0},
+		{"RepoTags": ["<none>:<none>"], "Size": 400, "SharedSize": 0, "Containers": 1}
+	],
+	"Containers": [
+		{"State": "exited", "SizeRw": 20},
+		{"State": "running", "SizeRw": 500}
+	],
+	"Volumes": [
+		{"Labels": {"com.docker.volume.anonymous": ""}, "UsageData": {"Size": 70, "RefCount": 0}},
+		{"Labels": {"com.docker.compose.project": "shop"}, "UsageData": {"Size": 800, "RefCount": 0}},
+		{"Labels": null, "UsageData": {"Size": 900, "RefCount": 2}}
+	],
+	"BuildCache": [
+		{"Size": 5, "InUse": false},
+		{"Size": 600, "InUse": true}
+	]
+}`
+
+func startFakeEngine(t *testing.T, name string, buildPrune bool, apiVersion string) *fakeEngine {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "ububu_sock")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	engine := &fakeEngine{filters: make(map[string]string), socket: filepath.Join(dir, name), apiVersion: apiVersion}
+	listener, err := net.Listen("unix", engine.socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on unix socket: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/system/df", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fakeSystemDF))
+	})
+	if apiVersion != "" {
+		mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]string{"ApiVersion": apiVersion})
+		})
+	}
+	prune := func(reclaimed int64) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			engine.mu.Lock()
+			engine.filters[r.URL.Path] = r.URL.Query().Get("filters")
+			engine.mu.Unlock()
+			json.NewEncoder(w).Encode(map[string]int64{"SpaceReclaimed": reclaimed})
+		}
+	}
+	mux.HandleFunc("/containers/prune", prune(20))
+	mux.HandleFunc("/images/prune", prune(250))
+	mux.HandleFunc("/volumes/prune", prune(70))
+	if buildPrune {
+		mux.HandleFunc("/build/prune", prune(5))
+	}
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return engine
+}
+
+func TestContainerModule_Interface(t *testing.T) {
+	module := &ContainerModule{}
+	if module.GetName() != "Container Cleanup" {
+		t.Errorf("GetName() = %v, want Container Cleanup", module.GetName())
+	}
+	if !strings.Contains(strings.ToLower(module.GetDescription()), "docker") {
+		t.Error("Description should mention Docker")
+	}
+	if module.RequiresRoot() {
+		t.Error("ContainerModule should not require root privileges")
+	}
+}
+
+func TestContainerModule_Preview(t *testing.T) {
+	engine := startFakeEngine(t, "docker.sock", true, "1.43")
+	rootless := startFakeEngine(t, "docker.sock", true, "1.43")
+	module := &ContainerModule{Sockets: []string{engine.socket, rootless.socket}}
+
+	// Два сокета одного движка не должны затирать друг друга
+	preview := module.Preview()
+	if len(preview) != 2 {
+		t.Fatalf("Preview should contain both sockets, got %v", preview)
+	}
+	usage, ok := preview[engine.socket]
+	if !ok {
+		t.Fatalf("Preview should be keyed by socket, got %v", preview)
+	}
+
+	expected := ContainerUsage{Images: 250, Containers: 20, Volumes: 70, BuildCache: 5}
+	if usage != expected {
+		t.Errorf("Preview usage = %+v, want %+v", usage, expected)
+	}
+	if usage.Total() != 345 {
+		t.Errorf("Total() = %d, want 345", usage.Total())
+	}
+}
+
+func TestContainerModule_ExecuteWithFilters(t *testing.T) {
+	// Podman не поддерживает /build/prune - это не должно считаться ошибкой
+	engine := startFakeEngine(t, "podman.sock", false, "1.41")
+	module := &ContainerModule{
+		Sockets:      []string{engine.socket, "/non/existent/docker.sock"},
+		PruneUntil:   24 * time.Hour,
+		LabelFilters: []string{"env=dev", "!keep"},
+		// Podman удаляет и именованные тома, поэтому только с явного согласия
+		PruneNamedVolumes: true,
+	}
+
+	var messages []string
+	err := module.Execute(func(progress float64, message string) {
+		if progress < 0 || progress > 1 {
+			t.Errorf("Invalid progress: %f", progress)
+		}
+		messages = append(messages, message)
+	})
+	if err != nil {
+		t.Errorf("Execute() returned error: %v", err)
+	}
+
+	last := messages[len(messages)-1]
+	if !strings.Contains(last, "completed") {
+		t.Errorf("Last message should report completion, got: %s", last)
+	}
+	for _, message := range messages {
+		if strings.Contains(message, "failed") {
+			t.Errorf("Unexpected failure message: %s", message)
+		}
+	}
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+
+	var containerFilters map[string][]string
+	json.Unmarshal([]byte(engine.filters["/containers/prune"]), &containerFilters)
+	if got := containerFilters["until"]; len(got) != 1 || got[0] != "24h0m0s" {
+		t.Errorf("containers until filter = %v, want [24h0m0s]", got)
+	}
+	if got := containerFilters["label!"]; len(got) != 1 || got[0] != "keep" {
+		t.Errorf("containers label! filter = %v, want [keep]", got)
+	}
+
+	var imageFilters map[string][]string
+	json.Unmarshal([]byte(engine.filters["/images/prune"]), &imageFilters)
+	if got := imageFilters["dangling"]; len(got) != 1 || got[0] != "true" {
+		t.Errorf("images dangling filter = %v, want [true]", got)
+	}
+
+	var volumeFilters map[string][]string
+	json.Unmarshal([]byte(engine.filters["/volumes/prune"]), &volumeFilters)
+	if _, ok := volumeFilters["until"]; ok {
+		t.Error("volumes prune does not support the until filter")
+	}
+	if got := volumeFilters["label"]; len(got) != 1 || got[0] != "env=dev" {
+		t.Errorf("volumes label filter = %v, want [env=dev]", got)
+	}
+	if _, ok := volumeFilters["all"]; ok {
+		t.Error("Podman does not support the all filter for volumes")
+	}
+}
+
+func TestContainerEngine_VolumePrune(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		socket  string
+		version string
+		named   bool
+		pruned  bool
+		all     bool
+		volumes int64
+	}{
+		{"anonymous only by default", "docker.sock", "1.43", false, true, false, 70},
+		{"named volumes opted in", "docker.sock", "1.42", true, true, true, 870},
+		{"old Docker prunes named volumes", "docker.sock", "1.41", false, false, false, 0},
+		{"unknown API version", "docker.sock", "", false, false, false, 0},
+		{"Podman prunes named volumes", "podman.sock", "1.41", false, false, false, 0},
+		{"Podman opted in", "podman.sock", "1.41", true, true, false, 870},
+	} {
+		engine := startFakeEngine(t, tt.socket, true, tt.version)
+		client := newContainerEngine(engine.socket)
+		volumes := client.volumePrune(tt.named)
+
+		usage, err := client.reclaimable(volumes)
+		if err != nil || usage.Volumes != tt.volumes {
+			t.Errorf("%s: reclaimable volumes = %d, %v, want %d", tt.name, usage.Volumes, err, tt.volumes)
+		}
+		if _, err := client.prune(nil, volumes); err != nil {
+			t.Fatalf("%s: prune() returned error: %v", tt.name, err)
+		}
+
+		engine.mu.Lock()
+		encoded, pruned := engine.filters["/volumes/prune"]
+		engine.mu.Unlock()
+		var volumeFilters map[string][]string
+		json.Unmarshal([]byte(encoded), &volumeFilters)
+
+		if pruned != tt.pruned {
+			t.Errorf("%s: volumes pruned = %v, want %v", tt.name, pruned, tt.pruned)
+		}
+		if all := len(volumeFilters["all"]) == 1 && volumeFilters["all"][0] == "true"; all != tt.all {
+			t.Errorf("%s: volumes filters = %v, want all=true: %v", tt.name, volumeFilters, tt.all)
+		}
+	}
+}
+func TestContainerModule_NoEngine(t *testing.T) {
+	module := &ContainerModule{Sockets: []string{"/non/existent/docker.sock"}}
+
+	var lastMessage string
+	var lastProgress float64
+	err := module.Execute(func(progress float64, message string) {
+		lastProgress = progress
+		lastMessage = message
+	})
+	if err != nil {
+		t.Errorf("Execute() returned error: %v", err)
+	}
+	if lastProgress != 1.0 {
+		t.Errorf("Final progress = %v, want 1.0", lastProgress)
+	}
+	if !strings.Contains(lastMessage, "No container engine") {
+		t.Errorf("Unexpected message: %s", lastMessage)
+	}
+}
@@ -0,0 +1,239 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cpuSysDir    = "/sys/devices/system/cpu"
+	procStatPath = "/proc/stat"
+	// cpuCappedRatio - лимит частоты ниже этой доли максимума считается ограничением
+	cpuCappedRatio = 0.9
+	// cpuSlowRatio и cpuBusyRatio - процессор загружен больше чем на cpuBusyRatio,
+	// а средняя частота ниже cpuSlowRatio от максимума
+	cpuSlowRatio = 0.5
+	cpuBusyRatio = 0.5
+)
+
+// cpuSampleInterval - за сколько времени измеряются загрузка и новые случаи троттлинга
+var cpuSampleInterval = time.Second
+
+// CPUCore - частота и счетчики троттлинга одного ядра. Частоты в кГц
+type CPUCore struct {
+	ID int
+	// CurFreq - текущая частота
+	CurFreq int64
+	// MaxFreq - максимальная частота процессора
+	MaxFreq int64
+	// ScalingMax - лимит частоты, установленный системой
+	ScalingMax int64
+	Governor   string
+	Driver     string
+	// CoreThrottles и PackageThrottles - сколько раз с загрузки ядро
+	// и весь процессор сбрасывали частоту из-за перегрева
+	CoreThrottles    int64
+	PackageThrottles int64
+}
+
+// readCPUCores читает cpufreq и thermal_throttle каждого ядра из root
+// (/sys/devices/system/cpu). Ядра без cpufreq пропускаются
+func readCPUCores(root string) []CPUCore {
+	dirs, _ := filepath.Glob(filepath.Join(root, "cpu[0-9]*"))
+
+	var cores []CPUCore
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "cpu"))
+		if err != nil {
+			continue
+		}
+		freqDir := filepath.Join(dir, "cpufreq")
+		cur, ok := readSysValue(filepath.Join(freqDir, "scaling_cur_freq"))
+		if !ok {
+			continue
+		}
+
+		core := CPUCore{
+			ID:       id,
+			CurFreq:  int64(cur),
+			Governor: readSysString(filepath.Join(freqDir, "scaling_governor")),
+			Driver:   readSysString(filepath.Join(freqDir, "scaling_driver")),
+		}
+		if max, ok := readSysValue(filepath.Join(freqDir, "cpuinfo_max_freq")); ok {
+			core.MaxFreq = int64(max)
+		}
+		if max, ok := readSysValue(filepath.Join(freqDir, "scaling_max_freq")); ok {
+			core.ScalingMax = int64(max)
+		}
+		if count, ok := readSysValue(filepath.Join(dir, "thermal_throttle", "core_throttle_count")); ok {
+			core.CoreThrottles = int64(count)
+		}
+		if count, ok := readSysValue(filepath.Join(dir, "thermal_throttle", "package_throttle_count")); ok {
+			core.PackageThrottles = int64(count)
+		}
+		cores = append(cores, core)
+	}
+
+	sort.Slice(cores, func(i, j int) bool { return cores[i].ID < cores[j].ID })
+	return cores
+}
+
+// throttleCount возвращает число троттлингов: ядер в сумме, а процессора
+// по максимуму, так как счетчик пакета одинаков для всех его ядер
+func throttleCount(cores []CPUCore) int64 {
+	var coreTotal, packageMax int64
+	for _, core := range cores {
+		coreTotal += core.CoreThrottles
+		if core.PackageThrottles > packageMax {
+			packageMax = core.PackageThrottles
+		}
+	}
+	return coreTotal + packageMax
+}
+
+// readCPUTimes читает общую строку cpu из /proc/stat: время работы и полное время
+func readCPUTimes(path string) (busy, total uint64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		// user nice system idle iowait irq softirq steal guest guest_nice.
+		// guest и guest_nice уже учтены в user и nice
+		values := fields[1:]
+		if len(values) > 8 {
+			values = values[:8]
+		}
+		for i, field := range values {
+			value, err := strconv.ParseUint(field, 10, 64)
+			if err != nil {
+				return 0, 0, err
+			}
+			total += value
+			if i != 3 && i != 4 { // idle и iowait
+				busy += value
+			}
+		}
+		return busy, total, nil
+	}
+	return 0, 0, fmt.Errorf("no cpu line in %s", path)
+}
+
+// cpuFreqFindings сравнивает два снимка ядер, сделанных с интервалом,
+// и загрузку процессора за это время (от 0 до 1)
+func cpuFreqFindings(before, after []CPUCore, busy float64) []Finding {
+	var findings []Finding
+	if len(after) == 0 {
+		return findings
+	}
+
+	if throttled := throttleCount(after) - throttleCount(before); throttled > 0 {
+		findings = append(findings, Finding{Severity: SeverityCritical,
+			Message: fmt.Sprintf("CPU is thermally throttling right now (%d events in %s)", throttled, cpuSampleInterval)})
+	} else if total := throttleCount(after); total > 0 {
+		findings = append(findings, Finding{Severity: SeverityInfo,
+			Message: fmt.Sprintf("CPU was thermally throttled %d times since boot", total)})
+	}
+
+	var capped []string
+	var cur, max int64
+	for _, core := range after {
+		cur += core.CurFreq
+		max += core.MaxFreq
+		if core.MaxFreq > 0 && core.ScalingMax > 0 && float64(core.ScalingMax) < cpuCappedRatio*float64(core.MaxFreq) {
+			capped = append(capped, strconv.Itoa(core.ID))
+		}
+	}
+	if len(capped) > 0 {
+		core := after[0]
+		findings = append(findings, Finding{Severity: SeverityWarning,
+			Message: fmt.Sprintf("frequency capped at %d of %d MHz on %d cores (cpu %s)",
+				core.ScalingMax/1000, core.MaxFreq/1000, len(capped), strings.Join(capped, ","))})
+	}
+	if max > 0 && busy > cpuBusyRatio && float64(cur) < cpuSlowRatio*float64(max) {
+		findings = append(findings, Finding{Severity: SeverityWarning,
+			Message: fmt.Sprintf("CPU %.0f%% busy but running at %d of %d MHz on average",
+				busy*100, cur/int64(len(after))/1000, max/int64(len(after))/1000)})
+	}
+
+	// Регулятор powersave драйвера acpi-cpufreq держит минимальную частоту.
+	// У intel_pstate и amd-pstate powersave - обычный режим с динамической частотой
+	if core := after[0]; core.Governor == "powersave" && core.Driver == "acpi-cpufreq" {
+		findings = append(findings, Finding{Severity: SeverityWarning,
+			Message: "powersave governor keeps acpi-cpufreq at the minimum frequency"})
+	}
+
+	return findings
+}
+
+// summarizeCPUFreq описывает частоты одной строкой
+func summarizeCPUFreq(cores []CPUCore, findings []Finding) string {
+	status := "✅ GOOD"
+	if worst := worstSeverity(findings); worst > SeverityInfo {
+		status = worst.String()
+	}
+	if len(cores) == 0 {
+		return status + ": cpufreq is not available"
+	}
+
+	var cur, max int64
+	governors := make(map[string]bool)
+	for _, core := range cores {
+		cur += core.CurFreq
+		max += core.MaxFreq
+		governors[core.Governor] = true
+	}
+	var names []string
+	for governor := range governors {
+		names = append(names, governor)
+	}
+	sort.Strings(names)
+
+	n := int64(len(cores))
+	return fmt.Sprintf("%s: %d cores at %d/%d MHz on average • governor %s",
+		status, n, cur/n/1000, max/n/1000, strings.Join(names, ", "))
+}
+
+// CheckCPUFrequency снимает частоты и счетчики троттлинга дважды с интервалом
+// cpuSampleInterval. Возвращает ядра и найденные проблемы
+func CheckCPUFrequency() ([]CPUCore, []Finding, error) {
+	before := readCPUCores(cpuSysDir)
+	if len(before) == 0 {
+		return nil, nil, fmt.Errorf("cpufreq is not available")
+	}
+	busyBefore, totalBefore, err := readCPUTimes(procStatPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	time.Sleep(cpuSampleInterval)
+
+	// Ядро могли отключить или драйвер выгрузить за время замера
+	after := readCPUCores(cpuSysDir)
+	if len(after) == 0 {
+		return nil, nil, fmt.Errorf("cpufreq disappeared during the sample")
+	}
+	busyAfter, totalAfter, err := readCPUTimes(procStatPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var busy float64
+	if totalAfter > totalBefore {
+		busy = float64(busyAfter-busyBefore) / float64(totalAfter-totalBefore)
+	}
+	return after, cpuFreqFindings(before, after, busy), nil
+}
@@ -0,0 +1,97 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCPU создает cpufreq и thermal_throttle одного ядра
+func writeCPU(t *testing.T, root string, id string, cur, max, scalingMax, governor, driver, throttles string) {
+	t.Helper()
+	writeSysFiles(t, filepath.Join(root, "cpu"+id, "cpufreq"), map[string]string{
+		"scaling_cur_freq": cur,
+		"cpuinfo_max_freq": max,
+		"scaling_max_freq": scalingMax,
+		"scaling_governor": governor,
+		"scaling_driver":   driver,
+	})
+	writeSysFiles(t, filepath.Join(root, "cpu"+id, "thermal_throttle"), map[string]string{
+		"core_throttle_count":    throttles,
+		"package_throttle_count": "5",
+	})
+}
+
+func TestReadCPUCores(t *testing.T) {
+	root := t.TempDir()
+	writeCPU(t, root, "0", "800000", "4000000", "4000000", "powersave", "intel_pstate", "2")
+	writeCPU(t, root, "10", "1200000", "4000000", "4000000", "powersave", "intel_pstate", "3")
+	os.MkdirAll(filepath.Join(root, "cpufreq"), 0755) // Не ядро, хотя имя начинается с cpu
+	os.MkdirAll(filepath.Join(root, "cpu1"), 0755) // Ядро без cpufreq
+
+	cores := readCPUCores(root)
+	if len(cores) != 2 || cores[0].ID != 0 || cores[1].ID != 10 {
+		t.Fatalf("readCPUCores() = %+v, want cpu0 and cpu10", cores)
+	}
+	if cores[0].CurFreq != 800000 || cores[0].MaxFreq != 4000000 || cores[0].Governor != "powersave" {
+		t.Errorf("Unexpected core: %+v", cores[0])
+	}
+	// Счетчики ядер складываются, счетчик процессора учитывается один раз
+	if count := throttleCount(cores); count != 10 {
+		t.Errorf("throttleCount() = %d, want 10", count)
+	}
+}
+
+func TestCPUFreqFindings(t *testing.T) {
+	core := CPUCore{CurFreq: 3500000, MaxFreq: 4000000, ScalingMax: 4000000, Governor: "powersave", Driver: "intel_pstate"}
+	idle := []CPUCore{core, core}
+	if findings := cpuFreqFindings(idle, idle, 0.1); len(findings) != 0 {
+		t.Errorf("Healthy CPU should have no findings, got %v", findings)
+	}
+
+	throttled := []CPUCore{core, core}
+	throttled[1].CoreThrottles = 3
+	findings := cpuFreqFindings(idle, throttled, 0.1)
+	if len(findings) != 1 || findings[0].Severity != SeverityCritical || !strings.Contains(findings[0].Message, "throttling right now") {
+		t.Errorf("Active throttling should be critical, got %v", findings)
+	}
+	findings = cpuFreqFindings(throttled, throttled, 0.1)
+	if len(findings) != 1 || findings[0].Severity != SeverityInfo {
+		t.Errorf("Past throttling should only be reported, got %v", findings)
+	}
+
+	slow := CPUCore{CurFreq: 800000, MaxFreq: 4000000, ScalingMax: 1600000, Governor: "powersave", Driver: "acpi-cpufreq"}
+	findings = cpuFreqFindings([]CPUCore{slow}, []CPUCore{slow}, 0.9)
+	var messages []string
+	for _, finding := range findings {
+		messages = append(messages, finding.Message)
+	}
+	got := strings.Join(messages, "; ")
+	for _, want := range []string{"capped at 1600 of 4000 MHz", "90% busy but running at 800 of 4000 MHz", "powersave governor"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Findings %q should mention %q", got, want)
+		}
+	}
+
+	summary := summarizeCPUFreq([]CPUCore{slow}, findings)
+	if !strings.Contains(summary, "WARNING: 1 cores at 800/4000 MHz") || !strings.Contains(summary, "governor powersave") {
+		t.Errorf("summarizeCPUFreq() = %q", summary)
+	}
+	if summary := summarizeCPUFreq(nil, nil); !strings.Contains(summary, "not available") {
+		t.Errorf("summarizeCPUFreq() without cores = %q", summary)
+	}
+}
+
+func TestReadCPUTimes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stat")
+	os.WriteFile(path, []byte("cpu  100 0 50 800 50 0 0 0 10 0\ncpu0 50 0 25 400 25 0 0 0 5 0\n"), 0644)
+
+	busy, total, err := readCPUTimes(path)
+	if err != nil {
+		t.Fatalf("readCPUTimes() returned error: %v", err)
+	}
+	if busy != 150 || total != 1000 {
+		t.Errorf("readCPUTimes() = %d, %d; want 150, 1000", busy, total)
+	}
+}
@@ -0,0 +1,400 @@
+package modules
+
+import (
+	"bufio"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apportCrashDir         = "/var/crash"
+	systemdCoredumpDir     = "/var/lib/systemd/coredump"
+	defaultCrashMaxAge     = 14 * 24 * time.Hour
+	defaultCrashTotalLimit = 1024 * 1024 * 1024
+	// coreSearchDepth ограничивает глубину поиска файлов core в домашнем каталоге
+	coreSearchDepth = 6
+	// maxCoreNotesSize - сколько заметок дампа читается в поисках NT_PRPSINFO.
+	// Она идет в начале, сразу за состоянием первого потока
+	maxCoreNotesSize = 4 * 1024 * 1024
+)
+
+// CrashDump - отчет о падении или дамп памяти упавшей программы
+type CrashDump struct {
+	Path    string
+	Program string
+	Time    time.Time
+	Size    int64
+	// Source - откуда дамп: apport, systemd-coredump или core
+	Source string
+}
+
+// CrashCount - сколько раз падала программа
+type CrashCount struct {
+	Program string
+	Count   int
+	Last    time.Time
+}
+
+// FindCrashDumps собирает отчеты apport из /var/crash, дампы systemd-coredump
+// и файлы core в домашних каталогах. Результат отсортирован от новых к старым
+func FindCrashDumps(homeDirs ...string) []CrashDump {
+	var dumps []CrashDump
+	dumps = append(dumps, findApportCrashes(apportCrashDir)...)
+	dumps = append(dumps, findCoredumps(systemdCoredumpDir)...)
+	for _, homeDir := range homeDirs {
+		dumps = append(dumps, findCoreFiles(homeDir)...)
+	}
+
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].Time.After(dumps[j].Time) })
+	return dumps
+}
+
+// findApportCrashes разбирает заголовки отчетов *.crash
+func findApportCrashes(dir string) []CrashDump {
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.crash"))
+
+	var dumps []CrashDump
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		dump := CrashDump{Path: path, Time: info.ModTime(), Size: fileUsage(info).Allocated, Source: "apport"}
+		dump.Program, dump.Time = parseApportHeader(path, dump.Time)
+		if dump.Program == "" {
+			// _usr_bin_foo.1000.crash
+			dump.Program = strings.ReplaceAll(strings.SplitN(filepath.Base(path), ".", 2)[0], "_", "/")
+		}
+		dumps = append(dumps, dump)
+	}
+	return dumps
+}
+
+// parseApportHeader читает ExecutablePath и Date из начала отчета apport
+func parseApportHeader(path string, fallback time.Time) (string, time.Time) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fallback
+	}
+	defer file.Close()
+
+	program, date := "", fallback
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lines := 0; scanner.Scan() && lines < 50; lines++ {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "ExecutablePath: "); ok {
+			program = value
+		}
+		if value, ok := strings.CutPrefix(line, "Date: "); ok {
+			if parsed, err := time.ParseInLocation(time.ANSIC, value, time.Local); err == nil {
+				date = parsed
+			}
+		}
+	}
+	return program, date
+}
+
+// findCoredumps разбирает имена дампов systemd-coredump:
+// core.<comm>.<uid>.<boot id>.<pid>.<usec>[.zst]
+func findCoredumps(dir string) []CrashDump {
+	entries, _ := os.ReadDir(dir)
+
+	var dumps []CrashDump
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "core.") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		dump := CrashDump{
+			Path:    filepath.Join(dir, entry.Name()),
+			Program: "unknown",
+			Time:    info.ModTime(),
+			Size:    fileUsage(info).Allocated,
+			Source:  "systemd-coredump",
+		}
+		if program, when, ok := parseCoredumpName(entry.Name()); ok {
+			dump.Program, dump.Time = program, when
+		}
+		dumps = append(dumps, dump)
+	}
+	return dumps
+}
+
+func parseCoredumpName(name string) (string, time.Time, bool) {
+	name = strings.TrimPrefix(name, "core.")
+	for _, ext := range []string{".zst", ".xz", ".lz4"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	parts := strings.Split(name, ".")
+	if len(parts) < 5 {
+		return "", time.Time{}, false
+	}
+	usec, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+
+	// Точки в имени программы systemd экранирует как \x2e
+	program := strings.ReplaceAll(strings.Join(parts[:len(parts)-4], "."), `\x2e`, ".")
+	return program, time.UnixMicro(usec), true
+}
+
+// findCoreFiles ищет файлы core и core.<pid> в домашнем каталоге. Файл
+// считается дампом, только если это ELF типа ET_CORE. Поиск не выходит за
+// пределы файловой системы root и глубже coreSearchDepth
+func findCoreFiles(root string) []CrashDump {
+	var dumps []CrashDump
+
+	rootInfo, err := os.Stat(root)
+	if err != nil {
+		return nil
+	}
+	rootDev := deviceOf(rootInfo)
+	rootDepth := strings.Count(filepath.Clean(root), string(os.PathSeparator))
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if path == root {
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") || strings.Count(path, string(os.PathSeparator))-rootDepth > coreSearchDepth {
+				return filepath.SkipDir
+			}
+			// Точки монтирования (сетевые диски, внешние носители) не обходим
+			if info, err := d.Info(); err != nil || deviceOf(info) != rootDev {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !isCoreFileName(d.Name()) {
+			return nil
+		}
+
+		program, ok := coreProgram(path)
+		if !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		dumps = append(dumps, CrashDump{
+			Path:    path,
+			Program: program,
+			Time:    info.ModTime(),
+			Size:    fileUsage(info).Allocated,
+			Source:  "core",
+		})
+		return nil
+	})
+
+	return dumps
+}
+
+func isCoreFileName(name string) bool {
+	if name == "core" {
+		return true
+	}
+	pid, ok := strings.CutPrefix(name, "core.")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(pid)
+	return err == nil
+}
+
+// coreProgram проверяет, что path - дамп памяти, и достает имя программы
+// из заметки NT_PRPSINFO (pr_fname)
+func coreProgram(path string) (string, bool) {
+	file, err := elf.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+	if file.Type != elf.ET_CORE {
+		return "", false
+	}
+
+	for _, prog := range file.Progs {
+		if prog.Type != elf.PT_NOTE {
+			continue
+		}
+		size := prog.Filesz
+		if size > maxCoreNotesSize {
+			size = maxCoreNotesSize
+		}
+		data := make([]byte, size)
+		if _, err := prog.ReadAt(data, 0); err != nil {
+			continue
+		}
+		if name := prpsinfoName(data, file.Class, file.ByteOrder); name != "" {
+			return name, true
+		}
+	}
+	return "unknown", true
+}
+
+// prpsinfoName ищет в заметках ELF NT_PRPSINFO и возвращает pr_fname
+func prpsinfoName(notes []byte, class elf.Class, order binary.ByteOrder) string {
+	const ntPrpsinfo = 3
+	// Смещение pr_fname в struct elf_prpsinfo
+	fnameOffset := 40
+	if class == elf.ELFCLASS32 {
+		fnameOffset = 28
+	}
+
+	// Размеры из файла могут быть любыми, поэтому считаем в uint64 без переполнения
+	align := func(n uint32) uint64 { return (uint64(n) + 3) &^ 3 }
+	for len(notes) >= 12 {
+		nameSize := order.Uint32(notes[0:4])
+		descSize := order.Uint32(notes[4:8])
+		noteType := order.Uint32(notes[8:12])
+		descStart := 12 + align(nameSize)
+		if descStart+uint64(descSize) > uint64(len(notes)) {
+			return ""
+		}
+
+		if noteType == ntPrpsinfo && uint64(descSize) >= uint64(fnameOffset+16) {
+			start := int(descStart) + fnameOffset
+			return strings.TrimRight(string(notes[start:start+16]), "\x00")
+		}
+		// У последней заметки выравнивание может выходить за конец данных
+		next := descStart + align(descSize)
+		if next >= uint64(len(notes)) {
+			return ""
+		}
+		notes = notes[next:]
+	}
+	return ""
+}
+
+// repeatCrashers возвращает программы, падавшие больше одного раза
+func repeatCrashers(dumps []CrashDump) []CrashCount {
+	byProgram := make(map[string]*CrashCount)
+	for _, dump := range dumps {
+		count, ok := byProgram[dump.Program]
+		if !ok {
+			count = &CrashCount{Program: dump.Program}
+			byProgram[dump.Program] = count
+		}
+		count.Count++
+		if dump.Time.After(count.Last) {
+			count.Last = dump.Time
+		}
+	}
+
+	var repeated []CrashCount
+	for _, count := range byProgram {
+		if count.Count > 1 {
+			repeated = append(repeated, *count)
+		}
+	}
+	sort.Slice(repeated, func(i, j int) bool {
+		if repeated[i].Count != repeated[j].Count {
+			return repeated[i].Count > repeated[j].Count
+		}
+		return repeated[i].Program < repeated[j].Program
+	})
+	return repeated
+}
+
+// selectCrashRemovals выбирает дампы старше maxAge, а затем самые старые
+// из оставшихся, пока их общий объем не станет меньше totalLimit.
+// dumps должны быть отсортированы от новых к старым
+func selectCrashRemovals(dumps []CrashDump, maxAge time.Duration, totalLimit int64) []CrashDump {
+	cutoff := time.Now().Add(-maxAge)
+
+	var kept, removals []CrashDump
+	var keptSize int64
+	for _, dump := range dumps {
+		if dump.Time.Before(cutoff) {
+			removals = append(removals, dump)
+			continue
+		}
+		kept = append(kept, dump)
+		keptSize += dump.Size
+	}
+
+	for i := len(kept) - 1; i >= 0 && keptSize > totalLimit; i-- {
+		removals = append(removals, kept[i])
+		keptSize -= kept[i].Size
+	}
+	return removals
+}
+
+func (m *CleanupModule) crashMaxAge() time.Duration {
+	if m.CrashMaxAge > 0 {
+		return m.CrashMaxAge
+	}
+	return defaultCrashMaxAge
+}
+
+func (m *CleanupModule) crashTotalLimit() int64 {
+	if m.CrashTotalLimit > 0 {
+		return m.CrashTotalLimit
+	}
+	return defaultCrashTotalLimit
+}
+
+func (m *CleanupModule) previewCrashDumps(homeDirs []string) []CleanupItem {
+	var items []CleanupItem
+	removals := selectCrashRemovals(FindCrashDumps(homeDirs...), m.crashMaxAge(), m.crashTotalLimit())
+	for _, dump := range removals {
+		items = append(items, CleanupItem{Category: "Crash dumps", Path: dump.Path, Size: dump.Size,
+			Program: dump.Program, Modified: dump.Time})
+	}
+	return items
+}
+
+func (m *CleanupModule) cleanCrashDumps(report func(message string)) (int64, error) {
+	var homeDirs []string
+	if users, err := m.cleanupUsers(); err == nil {
+		for _, account := range users {
+			homeDirs = append(homeDirs, account.Home)
+		}
+	}
+	dumps := FindCrashDumps(homeDirs...)
+
+	for _, crasher := range repeatCrashers(dumps) {
+		report(fmt.Sprintf("🔁 %s crashed %d times (last %s)",
+			crasher.Program, crasher.Count, crasher.Last.Format("2006-01-02 15:04")))
+	}
+
+	var freed int64
+	for _, dump := range selectCrashRemovals(dumps, m.crashMaxAge(), m.crashTotalLimit()) {
+		if err := os.Remove(dump.Path); err != nil {
+			continue // Дампы в /var без прав root не удалить
+		}
+		freed += dump.Size
+		report(fmt.Sprintf("🗑 %s crash from %s (%d MB)",
+			dump.Program, dump.Time.Format("2006-01-02 15:04"), dump.Size/1024/1024))
+		// Вместе с отчетом apport удаляем метки о его отправке
+		if dump.Source == "apport" {
+			base := strings.TrimSuffix(dump.Path, ".crash")
+			os.Remove(base + ".upload")
+			os.Remove(base + ".uploaded")
+		}
+	}
+
+	return freed, nil
+}
@@ -0,0 +1,231 @@
+package modules
+
+import (
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseCoredumpName(t *testing.T) {
+	program, when, ok := parseCoredumpName("core.firefox.1000.8f2c4d9e1a2b4c3d.4321.1760000000000000.zst")
+	if !ok {
+		t.Fatal("parseCoredumpName() failed to parse a valid name")
+	}
+	if program != "firefox" {
+		t.Errorf("program = %q, want firefox", program)
+	}
+	if !when.Equal(time.UnixMicro(1760000000000000)) {
+		t.Errorf("time = %v, want %v", when, time.UnixMicro(1760000000000000))
+	}
+
+	if program, _, _ := parseCoredumpName(`core.python3\x2e12.1000.abc.99.1760000000000000`); program != "python3.12" {
+		t.Errorf("program = %q, want python3.12", program)
+	}
+	if _, _, ok := parseCoredumpName("core.broken"); ok {
+		t.Error("parseCoredumpName() should reject malformed names")
+	}
+}
+
+func TestFindApportCrashes(t *testing.T) {
+	crashDir, err := os.MkdirTemp("", "ububu_crash_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(crashDir)
+
+	writeFile(t, filepath.Join(crashDir, "_usr_bin_gedit.1000.crash"), []byte(
+		"ProblemType: Crash\nArchitecture: amd64\nDate: Sat Oct 17 12:34:56 2026\nExecutablePath: /usr/bin/gedit\n"))
+	writeFile(t, filepath.Join(crashDir, "_usr_lib_foo.0.crash"), []byte("ProblemType: Crash\n"))
+
+	dumps := findApportCrashes(crashDir)
+	if len(dumps) != 2 {
+		t.Fatalf("findApportCrashes() returned %d dumps, want 2", len(dumps))
+	}
+
+	byProgram := make(map[string]CrashDump)
+	for _, dump := range dumps {
+		byProgram[dump.Program] = dump
+	}
+	gedit, ok := byProgram["/usr/bin/gedit"]
+	if !ok {
+		t.Fatalf("Program from ExecutablePath not found: %+v", dumps)
+	}
+	if want := time.Date(2026, 10, 17, 12, 34, 56, 0, time.Local); !gedit.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", gedit.Time, want)
+	}
+	// Без заголовка имя программы берется из имени файла
+	if _, ok := byProgram["/usr/lib/foo"]; !ok {
+		t.Errorf("Program should fall back to the file name: %+v", dumps)
+	}
+}
+
+func TestIsCoreFileName(t *testing.T) {
+	tests := map[string]bool{
+		"core":       true,
+		"core.12345": true,
+		"core.go":    false,
+		"score":      false,
+		"core.txt":   false,
+	}
+	for name, expected := range tests {
+		if got := isCoreFileName(name); got != expected {
+			t.Errorf("isCoreFileName(%q) = %v, want %v", name, got, expected)
+		}
+	}
+}
+
+func TestCoreProgram_NotELF(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_crash_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Файл с именем core, но не дамп памяти, не должен удаляться
+	writeFile(t, filepath.Join(tempDir, "src", "core"), []byte("package core\n"))
+	if dumps := findCoreFiles(tempDir); len(dumps) != 0 {
+		t.Errorf("findCoreFiles() = %+v, want none", dumps)
+	}
+}
+
+// coreNotes собирает заметки ELF из NT_PRSTATUS и NT_PRPSINFO с именем program
+func coreNotes(program string) []byte {
+	order := binary.LittleEndian
+	note := func(noteType uint32, desc []byte) []byte {
+		header := make([]byte, 12)
+		order.PutUint32(header[0:4], 5)
+		order.PutUint32(header[4:8], uint32(len(desc)))
+		order.PutUint32(header[8:12], noteType)
+		return append(append(header, "CORE\x00\x00\x00\x00"...), desc...)
+	}
+	prpsinfo := make([]byte, 136)
+	copy(prpsinfo[40:], program)
+	return append(note(1, make([]byte, 16)), note(3, prpsinfo)...)
+}
+
+// writeCoreFile создает минимальный 64-битный дамп памяти: заголовок ELF
+// типа ET_CORE и один сегмент PT_NOTE
+func writeCoreFile(t *testing.T, path, program string) {
+	t.Helper()
+	order := binary.LittleEndian
+	notes := coreNotes(program)
+
+	header := make([]byte, 64+56)
+	copy(header, "\x7fELF")
+	header[4], header[5], header[6] = 2, 1, 1 // ELFCLASS64, little endian, EV_CURRENT
+	order.PutUint16(header[16:], uint16(elf.ET_CORE))
+	order.PutUint16(header[18:], uint16(elf.EM_X86_64))
+	order.PutUint32(header[20:], 1)
+	order.PutUint64(header[32:], 64) // e_phoff
+	order.PutUint16(header[52:], 64) // e_ehsize
+	order.PutUint16(header[54:], 56) // e_phentsize
+	order.PutUint16(header[56:], 1)  // e_phnum
+
+	prog := header[64:]
+	order.PutUint32(prog[0:], uint32(elf.PT_NOTE))
+	order.PutUint64(prog[8:], uint64(len(header)))
+	order.PutUint64(prog[32:], uint64(len(notes)))
+	order.PutUint64(prog[40:], uint64(len(notes)))
+	order.PutUint64(prog[48:], 4)
+
+	writeFile(t, path, append(header, notes...))
+}
+
+func TestFindCoreFiles_Depth(t *testing.T) {
+	root := t.TempDir()
+	writeCoreFile(t, filepath.Join(root, "projects", "app", "core"), "app")
+
+	deep := root
+	for i := 0; i <= coreSearchDepth; i++ {
+		deep = filepath.Join(deep, "nested")
+	}
+	writeCoreFile(t, filepath.Join(deep, "core.42"), "deep")
+
+	dumps := findCoreFiles(root)
+	if len(dumps) != 1 || dumps[0].Program != "app" {
+		t.Errorf("findCoreFiles() = %+v, want only the app dump", dumps)
+	}
+}
+
+func TestPrpsinfoName(t *testing.T) {
+	order := binary.LittleEndian
+	note := func(noteType uint32, desc []byte) []byte {
+		name := []byte("CORE\x00\x00\x00\x00")
+		header := make([]byte, 12)
+		order.PutUint32(header[0:4], 5)
+		order.PutUint32(header[4:8], uint32(len(desc)))
+		order.PutUint32(header[8:12], noteType)
+		return append(append(header, name...), desc...)
+	}
+
+	prpsinfo := make([]byte, 136)
+	copy(prpsinfo[40:], "nautilus")
+	notes := append(note(1, make([]byte, 16)), note(3, prpsinfo)...)
+
+	if got := prpsinfoName(notes, elf.ELFCLASS64, order); got != "nautilus" {
+		t.Errorf("prpsinfoName() = %q, want nautilus", got)
+	}
+	if got := prpsinfoName(notes[:20], elf.ELFCLASS64, order); got != "" {
+		t.Errorf("prpsinfoName() of truncated notes = %q, want empty", got)
+	}
+	if got := prpsinfoName(coreNotes("gedit"), elf.ELFCLASS64, order); got != "gedit" {
+		t.Errorf("prpsinfoName() = %q, want gedit", got)
+	}
+
+	// Последняя заметка без выравнивания в конце и размеры, близкие к 2^32,
+	// не должны выводить за пределы данных
+	unaligned := note(1, []byte{1})
+	if got := prpsinfoName(unaligned, elf.ELFCLASS64, order); got != "" {
+		t.Errorf("prpsinfoName() of unaligned note = %q, want empty", got)
+	}
+	huge := note(1, make([]byte, 16))
+	order.PutUint32(huge[0:4], 0xFFFFFFFF)
+	order.PutUint32(huge[4:8], 0xFFFFFFFD)
+	if got := prpsinfoName(huge, elf.ELFCLASS64, order); got != "" {
+		t.Errorf("prpsinfoName() of oversized note = %q, want empty", got)
+	}
+}
+
+func TestRepeatCrashers(t *testing.T) {
+	now := time.Now()
+	dumps := []CrashDump{
+		{Program: "firefox", Time: now.Add(-time.Hour)},
+		{Program: "gedit", Time: now},
+		{Program: "firefox", Time: now},
+		{Program: "firefox", Time: now.Add(-2 * time.Hour)},
+		{Program: "vlc", Time: now},
+		{Program: "vlc", Time: now.Add(-time.Hour)},
+	}
+
+	crashers := repeatCrashers(dumps)
+	if len(crashers) != 2 {
+		t.Fatalf("repeatCrashers() = %+v, want firefox and vlc", crashers)
+	}
+	if crashers[0].Program != "firefox" || crashers[0].Count != 3 || !crashers[0].Last.Equal(now) {
+		t.Errorf("First crasher = %+v, want firefox x3", crashers[0])
+	}
+}
+
+func TestSelectCrashRemovals(t *testing.T) {
+	now := time.Now()
+	// Отсортированы от новых к старым
+	dumps := []CrashDump{
+		{Path: "new", Time: now, Size: 300},
+		{Path: "recent", Time: now.Add(-24 * time.Hour), Size: 300},
+		{Path: "older", Time: now.Add(-48 * time.Hour), Size: 300},
+		{Path: "expired", Time: now.Add(-30 * 24 * time.Hour), Size: 10},
+	}
+
+	removals := selectCrashRemovals(dumps, 14*24*time.Hour, 700)
+	var paths []string
+	for _, dump := range removals {
+		paths = append(paths, dump.Path)
+	}
+	// Сначала по возрасту, затем самые старые сверх лимита
+	if len(paths) != 2 || paths[0] != "expired" || paths[1] != "older" {
+		t.Errorf("selectCrashRemovals() = %v, want [expired older]", paths)
+	}
+}
@@ -0,0 +1,216 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+const defaultDevCacheMaxAge = 30 * 24 * time.Hour
+
+// devCache описывает кэш одного инструмента разработчика
+type devCache struct {
+	name string
+	// paths возвращает каталоги кэша с учетом переменных окружения инструмента
+	paths func(homeDir string) []string
+	// clean - собственная команда очистки инструмента, если она есть
+	clean []string
+}
+
+var devCaches = []devCache{
+	{
+		name: "Go build cache",
+		paths: func(homeDir string) []string {
+			return []string{homeEnvPath(homeDir, "GOCACHE", filepath.Join(xdgCacheHome(homeDir), "go-build"))}
+		},
+		clean: []string{"go", "clean", "-cache"},
+	},
+	{
+		name: "Go module cache",
+		paths: func(homeDir string) []string {
+			gopath := homeEnvPath(homeDir, "GOPATH", filepath.Join(homeDir, "go"))
+			return []string{homeEnvPath(homeDir, "GOMODCACHE", filepath.Join(gopath, "pkg/mod"))}
+		},
+		clean: []string{"go", "clean", "-modcache"},
+	},
+	{
+		name: "npm cache",
+		paths: func(homeDir string) []string {
+			return []string{filepath.Join(homeEnvPath(homeDir, "npm_config_cache", filepath.Join(homeDir, ".npm")), "_cacache")}
+		},
+		clean: []string{"npm", "cache", "clean", "--force"},
+	},
+	{
+		name: "Yarn cache",
+		paths: func(homeDir string) []string {
+			return []string{
+				filepath.Join(xdgCacheHome(homeDir), "yarn"),
+				filepath.Join(homeDir, ".yarn/berry/cache"),
+			}
+		},
+		clean: []string{"yarn", "cache", "clean"},
+	},
+	{
+		name: "pnpm store",
+		paths: func(homeDir string) []string {
+			return []string{filepath.Join(homeDir, ".local/share/pnpm/store")}
+		},
+		clean: []string{"pnpm", "store", "prune"},
+	},
+	{
+		name: "pip cache",
+		paths: func(homeDir string) []string {
+			return []string{homeEnvPath(homeDir, "PIP_CACHE_DIR", filepath.Join(xdgCacheHome(homeDir), "pip"))}
+		},
+		clean: []string{"pip", "cache", "purge"},
+	},
+	{
+		// У cargo нет встроенной команды очистки реестра
+		name: "Cargo registry",
+		paths: func(homeDir string) []string {
+			cargoHome := homeEnvPath(homeDir, "CARGO_HOME", filepath.Join(homeDir, ".cargo"))
+			return []string{
+				filepath.Join(cargoHome, "registry/cache"),
+				filepath.Join(cargoHome, "registry/src"),
+			}
+		},
+	},
+	{
+		name: "Gradle caches",
+		paths: func(homeDir string) []string {
+			return []string{filepath.Join(homeEnvPath(homeDir, "GRADLE_USER_HOME", filepath.Join(homeDir, ".gradle")), "caches")}
+		},
+	},
+	{
+		name: "Maven repository",
+		paths: func(homeDir string) []string {
+			return []string{filepath.Join(homeDir, ".m2/repository")}
+		},
+	},
+}
+
+// envPath возвращает значение переменной окружения или путь по умолчанию
+func envPath(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func xdgCacheHome(homeDir string) string {
+	return homeEnvPath(homeDir, "XDG_CACHE_HOME", filepath.Join(homeDir, ".cache"))
+}
+
+func (m *CleanupModule) devCacheMaxAge() time.Duration {
+	if m.DevCacheMaxAge > 0 {
+		return m.DevCacheMaxAge
+	}
+	return defaultDevCacheMaxAge
+}
+
+// previewDevCaches оценивает размер кэшей инструментов разработчика
+func (m *CleanupModule) previewDevCaches(homeDir string) []CleanupItem {
+	var items []CleanupItem
+	for _, cache := range devCaches {
+		for _, path := range cache.paths(homeDir) {
+			if size, err := m.getDirSize(path); err == nil && size > 0 {
+				items = append(items, CleanupItem{Category: cache.name, Path: path, Size: size})
+			}
+		}
+	}
+	return items
+}
+
+func (m *CleanupModule) cleanDevCaches(report func(message string)) (int64, error) {
+	account, err := currentUser()
+	if err != nil {
+		return 0, err
+	}
+	return m.cleanDevCachesFor(account, report)
+}
+
+func (m *CleanupModule) cleanDevCachesFor(account SystemUser, report func(message string)) (int64, error) {
+	var totalFreed int64
+	for _, cache := range devCaches {
+		paths := cache.paths(account.Home)
+
+		var before int64
+		for _, path := range paths {
+			if size, err := m.getDirSize(path); err == nil {
+				before += size
+			}
+		}
+		if before == 0 {
+			continue
+		}
+
+		if !m.runDevCacheClean(cache, account) {
+			// Инструмент не установлен или не справился - удаляем только старые файлы
+			for _, path := range paths {
+				pruneOlderThan(path, m.devCacheMaxAge())
+			}
+		}
+
+		var after int64
+		for _, path := range paths {
+			m.diskScanner().Invalidate(path)
+			if size, err := m.getDirSize(path); err == nil {
+				after += size
+			}
+		}
+
+		if freed := before - after; freed > 0 {
+			totalFreed += freed
+			report(fmt.Sprintf("%s: %d MB freed", cache.name, freed/1024/1024))
+		}
+	}
+
+	return totalFreed, nil
+}
+
+// runDevCacheClean запускает команду очистки инструмента от имени владельца кэша
+func (m *CleanupModule) runDevCacheClean(cache devCache, account SystemUser) bool {
+	if len(cache.clean) == 0 {
+		return false
+	}
+	if _, err := exec.LookPath(cache.clean[0]); err != nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cache.clean[0], cache.clean[1:]...)
+	runAs(cmd, account)
+	return cmd.Run() == nil
+}
+
+// pruneOlderThan удаляет файлы, не изменявшиеся дольше maxAge, и опустевшие каталоги
+func pruneOlderThan(root string, maxAge time.Duration) {
+	cutoff := time.Now().Add(-maxAge)
+	var dirs []string
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Игнорируем ошибки доступа
+		}
+		if info.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if info.ModTime().Before(cutoff) {
+			os.Remove(path)
+		}
+		return nil
+	})
+
+	// Удаляем пустые каталоги, начиная с самых глубоких
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+}
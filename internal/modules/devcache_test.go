@@ -0,0 +1,163 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPruneOlderThan(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_prune_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Старый файл во вложенной папке и свежий файл в корне
+	oldDir := filepath.Join(tempDir, "old")
+	os.MkdirAll(oldDir, 0755)
+	oldFile := filepath.Join(oldDir, "artifact.jar")
+	freshFile := filepath.Join(tempDir, "fresh.jar")
+	os.WriteFile(oldFile, []byte("old"), 0644)
+	os.WriteFile(freshFile, []byte("fresh"), 0644)
+
+	past := time.Now().Add(-60 * 24 * time.Hour)
+	os.Chtimes(oldFile, past, past)
+
+	pruneOlderThan(tempDir, 30*24*time.Hour)
+
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Error("Old file should have been deleted")
+	}
+	if _, err := os.Stat(oldDir); !os.IsNotExist(err) {
+		t.Error("Empty directory should have been deleted")
+	}
+	if _, err := os.Stat(freshFile); err != nil {
+		t.Error("Fresh file should have been kept")
+	}
+}
+
+func TestDevCachePaths_EnvOverride(t *testing.T) {
+	original := os.Getenv("PIP_CACHE_DIR")
+	os.Setenv("PIP_CACHE_DIR", "/custom/pip")
+	defer os.Setenv("PIP_CACHE_DIR", original)
+
+	for _, cache := range devCaches {
+		if cache.name != "pip cache" {
+			continue
+		}
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			t.Skip("no home directory")
+		}
+		paths := cache.paths(homeDir)
+		if len(paths) != 1 || paths[0] != "/custom/pip" {
+			t.Errorf("pip cache paths = %v, want [/custom/pip]", paths)
+		}
+		// Переменные окружения не относятся к чужим домашним каталогам
+		other := filepath.Join(t.TempDir(), "other")
+		paths = cache.paths(other)
+		if len(paths) != 1 || paths[0] != filepath.Join(other, ".cache/pip") {
+			t.Errorf("pip cache paths for another user = %v", paths)
+		}
+		return
+	}
+	t.Error("pip cache category not found")
+}
+
+func TestDevCachePaths_GoModCache(t *testing.T) {
+	original := os.Getenv("GOMODCACHE")
+	os.Setenv("GOMODCACHE", "/custom/gomod")
+	defer os.Setenv("GOMODCACHE", original)
+
+	for _, cache := range devCaches {
+		if cache.name != "Go module cache" {
+			continue
+		}
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			t.Skip("no home directory")
+		}
+		paths := cache.paths(homeDir)
+		if len(paths) != 1 || paths[0] != "/custom/gomod" {
+			t.Errorf("Go module cache paths = %v, want [/custom/gomod]", paths)
+		}
+		// GOMODCACHE процесса не относится к чужим домашним каталогам
+		other := filepath.Join(t.TempDir(), "other")
+		paths = cache.paths(other)
+		if len(paths) != 1 || paths[0] != filepath.Join(other, "go/pkg/mod") {
+			t.Errorf("Go module cache paths for another user = %v", paths)
+		}
+		return
+	}
+	t.Error("Go module cache category not found")
+}
+
+func TestCleanupModule_CleanDevCaches_Fallback(t *testing.T) {
+	module := &CleanupModule{DevCacheMaxAge: 24 * time.Hour}
+
+	tempHome, err := os.MkdirTemp("", "ububu_home_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp home dir: %v", err)
+	}
+	defer os.RemoveAll(tempHome)
+
+	// У Maven нет своей команды очистки - должны удалиться только старые артефакты
+	repoDir := filepath.Join(tempHome, ".m2/repository/org/example")
+	os.MkdirAll(repoDir, 0755)
+	oldJar := filepath.Join(repoDir, "old.jar")
+	newJar := filepath.Join(repoDir, "new.jar")
+	os.WriteFile(oldJar, make([]byte, 2048), 0644)
+	os.WriteFile(newJar, make([]byte, 1024), 0644)
+	past := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(oldJar, past, past)
+
+	// Все кэши должны искаться только во временной домашней папке
+	for _, key := range []string{"GOCACHE", "GOMODCACHE", "GOPATH", "npm_config_cache", "XDG_CACHE_HOME",
+		"PIP_CACHE_DIR", "CARGO_HOME", "GRADLE_USER_HOME"} {
+		original, ok := os.LookupEnv(key)
+		os.Unsetenv(key)
+		if ok {
+			defer os.Setenv(key, original)
+		}
+	}
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempHome)
+	defer os.Setenv("HOME", originalHome)
+
+	items := module.previewDevCaches(tempHome)
+	found := false
+	for _, item := range items {
+		if item.Category == "Maven repository" {
+			found = true
+			if item.Size <= 0 {
+				t.Errorf("Preview size for Maven repository = %d, want > 0", item.Size)
+			}
+		}
+	}
+	if !found {
+		t.Error("Preview should contain Maven repository")
+	}
+
+	var messages []string
+	freed, err := module.cleanDevCaches(func(message string) {
+		messages = append(messages, message)
+	})
+	if err != nil {
+		t.Errorf("cleanDevCaches() returned error: %v", err)
+	}
+	if freed <= 0 {
+		t.Errorf("cleanDevCaches() freed = %d, want > 0", freed)
+	}
+	if len(messages) == 0 {
+		t.Error("cleanDevCaches() should report freed space per category")
+	}
+
+	if _, err := os.Stat(oldJar); !os.IsNotExist(err) {
+		t.Error("Old artifact should have been deleted")
+	}
+	if _, err := os.Stat(newJar); err != nil {
+		t.Error("Recent artifact should have been kept")
+	}
+}
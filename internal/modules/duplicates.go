@@ -0,0 +1,411 @@
+package modules
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+const (
+	defaultDuplicateMinSize = 1024 * 1024
+	// partialHashSize - сколько байт с начала и с конца файла хэшируется
+	// на втором проходе, прежде чем читать файл целиком
+	partialHashSize = 16 * 1024
+)
+
+// DuplicateAction - что делать с лишними копиями
+type DuplicateAction string
+
+const (
+	// DuplicateReport только показывает найденные группы
+	DuplicateReport DuplicateAction = ""
+	// DuplicateDelete переносит лишние копии в карантин
+	DuplicateDelete DuplicateAction = "delete"
+	// DuplicateHardlink заменяет лишние копии жесткими ссылками на оставленную
+	DuplicateHardlink DuplicateAction = "hardlink"
+)
+
+// DuplicatesModule ищет одинаковые файлы в домашних каталогах
+type DuplicatesModule struct {
+	// Roots - где искать (по умолчанию домашний каталог без скрытых каталогов)
+	Roots []string
+	// MinSize - файлы меньше этого размера не проверяются (по умолчанию 1 МБ)
+	MinSize int64
+	// Action - что делать с лишними копиями при выполнении задачи.
+	// Оставляется самая старая копия, остальные уходят в карантин
+	Action DuplicateAction
+
+	// Groups - группы дубликатов, найденные при последнем запуске
+	Groups []DuplicateGroup
+}
+
+// DuplicateGroup - файлы с одинаковым содержимым
+type DuplicateGroup struct {
+	Size  int64
+	Hash  string
+	Paths []string
+}
+
+// Wasted возвращает место, которое занимают лишние копии
+func (g DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Paths)-1)
+}
+
+func (m *DuplicatesModule) GetName() string {
+	return "Duplicate Finder"
+}
+
+func (m *DuplicatesModule) GetDescription() string {
+	return "Find duplicate files in home directories"
+}
+
+func (m *DuplicatesModule) RequiresRoot() bool {
+	return false
+}
+
+func (m *DuplicatesModule) Execute(progressCallback func(progress float64, message string)) error {
+	progressCallback(0.1, "Looking for duplicate files...")
+
+	roots, err := m.roots()
+	if err != nil {
+		return err
+	}
+
+	groups, err := FindDuplicates(context.Background(), roots, m.minSize())
+	if err != nil {
+		return err
+	}
+	m.Groups = groups
+
+	var wasted int64
+	for _, group := range groups {
+		wasted += group.Wasted()
+	}
+	progressCallback(0.6, fmt.Sprintf("Found %d duplicate groups, %d MB wasted", len(groups), wasted/1024/1024))
+
+	// Показываем самые затратные группы
+	for i, group := range groups {
+		if i == 5 {
+			progressCallback(0.6, fmt.Sprintf("...and %d more groups", len(groups)-i))
+			break
+		}
+		progressCallback(0.6, fmt.Sprintf("%d copies of %s (%d MB wasted)",
+			len(group.Paths), filepath.Base(group.Paths[0]), group.Wasted()/1024/1024))
+	}
+
+	if m.Action == DuplicateReport || len(groups) == 0 {
+		progressCallback(1.0, "Duplicate scan completed")
+		return nil
+	}
+
+	quarantine, err := DefaultQuarantine()
+	if err != nil {
+		return err
+	}
+
+	var freed int64
+	for i, group := range groups {
+		keep := OldestCopy(group)
+		saved, err := ResolveDuplicates(quarantine, group, keep, m.Action == DuplicateHardlink)
+		freed += saved
+		if err != nil {
+			progressCallback(0.6+0.4*float64(i+1)/float64(len(groups)), fmt.Sprintf("%s: %v", filepath.Base(keep), err))
+		}
+	}
+
+	progressCallback(1.0, fmt.Sprintf("Duplicate cleanup completed! %d MB moved to quarantine", freed/1024/1024))
+	return nil
+}
+
+func (m *DuplicatesModule) roots() ([]string, error) {
+	if len(m.Roots) > 0 {
+		return m.Roots, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return []string{homeDir}, nil
+}
+
+func (m *DuplicatesModule) minSize() int64 {
+	if m.MinSize > 0 {
+		return m.MinSize
+	}
+	return defaultDuplicateMinSize
+}
+
+// FindDuplicates ищет файлы с одинаковым содержимым в roots. Кандидаты
+// отсеиваются по размеру, затем по хэшу начала и конца файла, и только
+// оставшиеся читаются целиком. Группы отсортированы по потерянному месту
+func FindDuplicates(ctx context.Context, roots []string, minSize int64) ([]DuplicateGroup, error) {
+	bySize, err := collectBySize(ctx, roots, minSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []DuplicateGroup
+	for size, paths := range bySize {
+		if len(paths) < 2 {
+			continue
+		}
+
+		byPartial, err := groupByHash(ctx, paths, partialHash)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidates := range byPartial {
+			if len(candidates) < 2 {
+				continue
+			}
+
+			// Небольшие файлы уже прочитаны целиком при частичном хэшировании
+			byFull := map[string][]string{"": candidates}
+			if size > 2*partialHashSize {
+				if byFull, err = groupByHash(ctx, candidates, fullHash); err != nil {
+					return nil, err
+				}
+			}
+			for hash, same := range byFull {
+				if len(same) < 2 {
+					continue
+				}
+				sort.Strings(same)
+				groups = append(groups, DuplicateGroup{Size: size, Hash: hash, Paths: same})
+			}
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Wasted() != groups[j].Wasted() {
+			return groups[i].Wasted() > groups[j].Wasted()
+		}
+		return groups[i].Paths[0] < groups[j].Paths[0]
+	})
+	return groups, nil
+}
+
+// collectBySize раскладывает обычные файлы по размеру. Скрытые каталоги
+// (кэши, настройки, репозитории) пропускаются, а жесткие ссылки на один
+// файл учитываются один раз - это уже не дубликаты
+func collectBySize(ctx context.Context, roots []string, minSize int64) (map[int64][]string, error) {
+	bySize := make(map[int64][]string)
+	seen := make(map[inodeKey]bool)
+
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err != nil {
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if path != root && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil || info.Size() < minSize {
+				return nil
+			}
+			if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+				key := inodeKey{dev: uint64(stat.Dev), ino: stat.Ino}
+				if seen[key] {
+					return nil
+				}
+				seen[key] = true
+			}
+
+			bySize[info.Size()] = append(bySize[info.Size()], path)
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	return bySize, nil
+}
+
+func groupByHash(ctx context.Context, paths []string, hash func(path string) (string, error)) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sum, err := hash(path)
+		if err != nil {
+			continue // Файл исчез или недоступен
+		}
+		groups[sum] = append(groups[sum], path)
+	}
+	return groups, nil
+}
+
+// partialHash хэширует первые и последние partialHashSize байт файла
+func partialHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	if info.Size() <= 2*partialHashSize {
+		if _, err := io.Copy(hash, file); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%x", hash.Sum(nil)), nil
+	}
+
+	if _, err := io.CopyN(hash, file, partialHashSize); err != nil {
+		return "", err
+	}
+	if _, err := file.Seek(-partialHashSize, io.SeekEnd); err != nil {
+		return "", err
+	}
+	if _, err := io.CopyN(hash, file, partialHashSize); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+func fullHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// OldestCopy возвращает копию с самым ранним временем изменения -
+// скорее всего, это оригинал
+func OldestCopy(group DuplicateGroup) string {
+	keep := group.Paths[0]
+	var oldest int64
+	for _, path := range group.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if mtime := info.ModTime().UnixNano(); oldest == 0 || mtime < oldest {
+			oldest = mtime
+			keep = path
+		}
+	}
+	return keep
+}
+
+// ResolveDuplicates оставляет в группе только keep: остальные копии уходят
+// в карантин, а если hardlink, на их месте остаются жесткие ссылки на keep.
+// Возвращает освобожденное место
+func ResolveDuplicates(quarantine *Quarantine, group DuplicateGroup, keep string, hardlink bool) (int64, error) {
+	if !containsString(group.Paths, keep) {
+		return 0, fmt.Errorf("%s is not part of the duplicate group", keep)
+	}
+
+	var freed int64
+	var errs []string
+	for _, path := range group.Paths {
+		if path == keep {
+			continue
+		}
+
+		// Файл мог измениться после сканирования
+		if same, err := sameContent(keep, path); err != nil || !same {
+			errs = append(errs, fmt.Sprintf("%s changed since scan, skipped", path))
+			continue
+		}
+
+		var entry QuarantineEntry
+		var err error
+		if hardlink {
+			entry, err = quarantine.ReplaceWithLink(path, keep)
+		} else {
+			entry, err = quarantine.Add(path)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		freed += entry.Size
+	}
+
+	if len(errs) > 0 {
+		return freed, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return freed, nil
+}
+
+// sameContent побайтно сравнивает два файла
+func sameContent(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	fileA, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fileA.Close()
+	fileB, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fileB.Close()
+
+	bufA := make([]byte, 64*1024)
+	bufB := make([]byte, 64*1024)
+	for {
+		nA, errA := io.ReadFull(fileA, bufA)
+		nB, errB := io.ReadFull(fileB, bufB)
+		if nA != nB || !bytes.Equal(bufA[:nA], bufB[:nB]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package modules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+}
+
+func patternData(size int, seed byte) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i) ^ seed
+	}
+	return data
+}
+
+func TestFindDuplicates(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_dup_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	photo := patternData(100*1024, 1)
+	writeFile(t, filepath.Join(root, "Pictures", "photo.jpg"), photo)
+	writeFile(t, filepath.Join(root, "Downloads", "photo (1).jpg"), photo)
+	writeFile(t, filepath.Join(root, "Backup", "photo.jpg"), photo)
+
+	// Тот же размер, одинаковые начало и конец, но разная середина
+	almost := patternData(100*1024, 1)
+	almost[50*1024] ^= 0xff
+	writeFile(t, filepath.Join(root, "Downloads", "almost.jpg"), almost)
+
+	// Маленькие файлы отсеиваются по размеру, скрытые каталоги пропускаются
+	writeFile(t, filepath.Join(root, "a.txt"), []byte("tiny"))
+	writeFile(t, filepath.Join(root, "b.txt"), []byte("tiny"))
+	writeFile(t, filepath.Join(root, ".cache", "photo.jpg"), photo)
+
+	// Жесткая ссылка не является дубликатом
+	os.Link(filepath.Join(root, "Pictures", "photo.jpg"), filepath.Join(root, "Pictures", "link.jpg"))
+
+	groups, err := FindDuplicates(context.Background(), []string{root}, 1024)
+	if err != nil {
+		t.Fatalf("FindDuplicates() returned error: %v", err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("FindDuplicates() returned %d groups, want 1: %+v", len(groups), groups)
+	}
+
+	group := groups[0]
+	if len(group.Paths) != 3 {
+		t.Errorf("Group has %d paths, want 3: %v", len(group.Paths), group.Paths)
+	}
+	if group.Wasted() != 2*100*1024 {
+		t.Errorf("Wasted() = %d, want %d", group.Wasted(), 2*100*1024)
+	}
+}
+
+func TestResolveDuplicates(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_dup_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	data := patternData(64*1024, 7)
+	original := filepath.Join(root, "original.mp3")
+	copy1 := filepath.Join(root, "copy1.mp3")
+	copy2 := filepath.Join(root, "copy2.mp3")
+	writeFile(t, original, data)
+	writeFile(t, copy1, data)
+	writeFile(t, copy2, data)
+
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(original, past, past)
+
+	group := DuplicateGroup{Size: int64(len(data)), Paths: []string{copy1, copy2, original}}
+	keep := OldestCopy(group)
+	if keep != original {
+		t.Fatalf("OldestCopy() = %s, want %s", keep, original)
+	}
+
+	quarantine := NewQuarantine(filepath.Join(root, "quarantine"))
+
+	// Изменившийся после сканирования файл не трогаем
+	writeFile(t, copy2, patternData(64*1024, 8))
+
+	freed, err := ResolveDuplicates(quarantine, group, keep, false)
+	if err == nil {
+		t.Error("ResolveDuplicates() should report the changed file")
+	}
+	if freed == 0 {
+		t.Error("ResolveDuplicates() should free space for the unchanged copy")
+	}
+	if _, err := os.Stat(copy1); !os.IsNotExist(err) {
+		t.Error("Duplicate should have been moved to quarantine")
+	}
+	if _, err := os.Stat(copy2); err != nil {
+		t.Error("Changed file should have been kept")
+	}
+	if _, err := os.Stat(original); err != nil {
+		t.Error("Kept copy should stay in place")
+	}
+
+	if _, err := ResolveDuplicates(quarantine, group, filepath.Join(root, "other"), false); err == nil {
+		t.Error("ResolveDuplicates() should reject a keep path outside the group")
+	}
+}
+
+func TestResolveDuplicates_Hardlink(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_dup_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	data := patternData(64*1024, 3)
+	keep := filepath.Join(root, "keep.iso")
+	duplicate := filepath.Join(root, "dup.iso")
+	writeFile(t, keep, data)
+	writeFile(t, duplicate, data)
+
+	group := DuplicateGroup{Size: int64(len(data)), Paths: []string{keep, duplicate}}
+	if _, err := ResolveDuplicates(NewQuarantine(filepath.Join(root, "quarantine")), group, keep, true); err != nil {
+		t.Skipf("Hardlinks not supported: %v", err)
+	}
+
+	keepInfo, _ := os.Stat(keep)
+	dupInfo, err := os.Stat(duplicate)
+	if err != nil || !os.SameFile(keepInfo, dupInfo) {
+		t.Error("Duplicate should have been replaced with a hardlink")
+	}
+}
+
+func TestSameContent(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_dup_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	a := filepath.Join(root, "a")
+	b := filepath.Join(root, "b")
+	c := filepath.Join(root, "c")
+	writeFile(t, a, patternData(200*1024, 1))
+	writeFile(t, b, patternData(200*1024, 1))
+	writeFile(t, c, patternData(200*1024, 2))
+
+	if same, err := sameContent(a, b); err != nil || !same {
+		t.Errorf("sameContent(a, b) = %v, %v; want true", same, err)
+	}
+	if same, _ := sameContent(a, c); same {
+		t.Error("sameContent(a, c) should be false")
+	}
+}
@@ -0,0 +1,157 @@
+package modules
+
+import (
+	"container/heap"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExplorerEntry - элемент каталога в обозревателе диска
+type ExplorerEntry struct {
+	Name  string
+	Path  string
+	IsDir bool
+	// Mount - каталог является точкой монтирования другой файловой системы
+	// и не сканировался
+	Mount bool
+	Usage DiskUsage
+}
+
+// TypeUsage - сколько места занимают файлы одного типа
+type TypeUsage struct {
+	Type      string
+	Files     int64
+	Allocated int64
+}
+
+// FileStats - крупнейшие файлы дерева и распределение места по типам файлов
+type FileStats struct {
+	Largest []ExplorerEntry
+	Types   []TypeUsage
+}
+
+// ListDir возвращает содержимое dir, отсортированное по занятому месту.
+// Размеры подкаталогов берутся из кэша сканера, поэтому при переходе вглубь
+// дерево повторно не обходится
+func ListDir(ctx context.Context, scanner *Scanner, dir string) ([]ExplorerEntry, error) {
+	dir = filepath.Clean(dir)
+	if _, err := scanner.Scan(ctx, dir); err != nil {
+		return nil, err
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ExplorerEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		path := filepath.Join(dir, dirEntry.Name())
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+
+		entry := ExplorerEntry{Name: dirEntry.Name(), Path: path, IsDir: info.IsDir()}
+		if entry.IsDir {
+			usage, ok := scanner.Cached(path)
+			// Сканер не заходит в другие файловые системы
+			entry.Mount = !ok
+			entry.Usage = usage
+		} else {
+			entry.Usage = fileUsage(info)
+		}
+		entries = append(entries, entry)
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// CollectFileStats находит top крупнейших файлов в root и считает, сколько
+// места занимает каждый тип файлов (по расширению)
+func CollectFileStats(ctx context.Context, root string, top int) (FileStats, error) {
+	var mu sync.Mutex
+	largest := &entryHeap{}
+	types := make(map[string]*TypeUsage)
+
+	scanner := NewScanner()
+	scanner.OnFile = func(path string, usage DiskUsage) {
+		fileType := fileTypeOf(path)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		stat, ok := types[fileType]
+		if !ok {
+			stat = &TypeUsage{Type: fileType}
+			types[fileType] = stat
+		}
+		stat.Files++
+		stat.Allocated += usage.Allocated
+
+		if largest.Len() < top {
+			heap.Push(largest, ExplorerEntry{Name: filepath.Base(path), Path: path, Usage: usage})
+		} else if top > 0 && usage.Allocated > (*largest)[0].Usage.Allocated {
+			(*largest)[0] = ExplorerEntry{Name: filepath.Base(path), Path: path, Usage: usage}
+			heap.Fix(largest, 0)
+		}
+	}
+
+	if _, err := scanner.Scan(ctx, root); err != nil {
+		return FileStats{}, err
+	}
+
+	stats := FileStats{Largest: []ExplorerEntry(*largest)}
+	sortEntries(stats.Largest)
+
+	for _, stat := range types {
+		stats.Types = append(stats.Types, *stat)
+	}
+	sort.Slice(stats.Types, func(i, j int) bool {
+		if stats.Types[i].Allocated != stats.Types[j].Allocated {
+			return stats.Types[i].Allocated > stats.Types[j].Allocated
+		}
+		return stats.Types[i].Type < stats.Types[j].Type
+	})
+
+	return stats, nil
+}
+
+// fileTypeOf возвращает расширение файла в нижнем регистре
+func fileTypeOf(path string) string {
+	name := filepath.Base(path)
+	ext := strings.ToLower(filepath.Ext(name))
+	// Скрытые файлы вроде .bashrc не имеют расширения
+	if ext == "" || ext == strings.ToLower(name) {
+		return "(no extension)"
+	}
+	return ext
+}
+
+func sortEntries(entries []ExplorerEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Usage.Allocated != entries[j].Usage.Allocated {
+			return entries[i].Usage.Allocated > entries[j].Usage.Allocated
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// entryHeap - min-куча по занятому месту для отбора крупнейших файлов
+type entryHeap []ExplorerEntry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].Usage.Allocated < h[j].Usage.Allocated }
+func (h entryHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *entryHeap) Push(x any)        { *h = append(*h, x.(ExplorerEntry)) }
+func (h *entryHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
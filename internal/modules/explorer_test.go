@@ -0,0 +1,76 @@
+package modules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListDir(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_explorer_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(filepath.Join(root, "videos"), 0755)
+	os.MkdirAll(filepath.Join(root, "empty"), 0755)
+	os.WriteFile(filepath.Join(root, "videos", "movie.mp4"), make([]byte, 256*1024), 0644)
+	os.WriteFile(filepath.Join(root, "notes.txt"), make([]byte, 4096), 0644)
+
+	scanner := NewScanner()
+	entries, err := ListDir(context.Background(), scanner, root)
+	if err != nil {
+		t.Fatalf("ListDir() returned error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ListDir() returned %d entries, want 3", len(entries))
+	}
+
+	// Сортировка по занятому месту, каталоги с размером всего поддерева
+	if entries[0].Name != "videos" || !entries[0].IsDir || entries[0].Usage.Files != 1 {
+		t.Errorf("First entry = %+v, want videos directory", entries[0])
+	}
+	if entries[1].Name != "notes.txt" || entries[2].Name != "empty" {
+		t.Errorf("Unexpected order: %s, %s", entries[1].Name, entries[2].Name)
+	}
+
+	// Переход в подкаталог обслуживается из кэша
+	if _, ok := scanner.Cached(filepath.Join(root, "videos")); !ok {
+		t.Error("Subdirectories should be cached after listing the parent")
+	}
+}
+
+func TestCollectFileStats(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_explorer_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	os.MkdirAll(filepath.Join(root, "a", "b"), 0755)
+	os.WriteFile(filepath.Join(root, "a", "b", "big.MP4"), make([]byte, 512*1024), 0644)
+	os.WriteFile(filepath.Join(root, "a", "clip.mp4"), make([]byte, 128*1024), 0644)
+	os.WriteFile(filepath.Join(root, "a", "log.txt"), make([]byte, 64*1024), 0644)
+	os.WriteFile(filepath.Join(root, ".bashrc"), make([]byte, 4096), 0644)
+
+	stats, err := CollectFileStats(context.Background(), root, 2)
+	if err != nil {
+		t.Fatalf("CollectFileStats() returned error: %v", err)
+	}
+
+	if len(stats.Largest) != 2 || stats.Largest[0].Name != "big.MP4" || stats.Largest[1].Name != "clip.mp4" {
+		t.Errorf("Largest = %+v, want big.MP4 and clip.mp4", stats.Largest)
+	}
+
+	if len(stats.Types) != 3 {
+		t.Fatalf("Types = %+v, want 3 types", stats.Types)
+	}
+	if stats.Types[0].Type != ".mp4" || stats.Types[0].Files != 2 {
+		t.Errorf("Top type = %+v, want .mp4 with 2 files", stats.Types[0])
+	}
+	if stats.Types[2].Type != "(no extension)" {
+		t.Errorf("Dotfiles should have no extension, got %+v", stats.Types[2])
+	}
+}
@@ -0,0 +1,198 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// flatpakRuntime - установленный runtime Flatpak
+type flatpakRuntime struct {
+	ID           string
+	Arch         string
+	Branch       string
+	Installation string
+}
+
+// Ref возвращает ссылку на runtime в формате flatpak: id/arch/branch
+func (r flatpakRuntime) Ref() string {
+	return r.ID + "/" + r.Arch + "/" + r.Branch
+}
+
+// Dir возвращает каталог runtime внутри установки
+func (r flatpakRuntime) Dir(homeDir string) string {
+	base := "/var/lib/flatpak"
+	if r.Installation == "user" {
+		base = filepath.Join(homeDir, ".local/share/flatpak")
+	}
+	return filepath.Join(base, "runtime", r.ID, r.Arch, r.Branch)
+}
+
+// parseFlatpakRuntimes разбирает вывод
+// `flatpak list --runtime --columns=application,arch,branch,installation`
+func parseFlatpakRuntimes(output string) []flatpakRuntime {
+	var runtimes []flatpakRuntime
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] == "Application" {
+			continue
+		}
+		runtimes = append(runtimes, flatpakRuntime{
+			ID:           fields[0],
+			Arch:         fields[1],
+			Branch:       fields[2],
+			Installation: fields[3],
+		})
+	}
+	return runtimes
+}
+
+// unusedFlatpakRuntimes возвращает runtimes, на которые не ссылается ни одно
+// приложение. appRuntimes - вывод `flatpak list --app --columns=runtime`.
+// Расширения (Locale, GL, кодеки) считаются используемыми вместе со своим runtime
+func unusedFlatpakRuntimes(runtimes []flatpakRuntime, appRuntimes string) []flatpakRuntime {
+	usedIDs := make(map[string]bool)
+	usedRefs := make(map[string]bool)
+	for _, ref := range strings.Fields(appRuntimes) {
+		usedRefs[ref] = true
+		usedIDs[strings.SplitN(ref, "/", 2)[0]] = true
+	}
+
+	var unused []flatpakRuntime
+	for _, runtime := range runtimes {
+		if usedRefs[runtime.Ref()] {
+			continue
+		}
+		isExtension := false
+		for id := range usedIDs {
+			if strings.HasPrefix(runtime.ID, id+".") {
+				isExtension = true
+				break
+			}
+		}
+		if !isExtension {
+			unused = append(unused, runtime)
+		}
+	}
+	return unused
+}
+
+func listFlatpak(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "flatpak", append([]string{"list"}, args...)...).Output()
+	return string(output), err
+}
+
+func (m *CleanupModule) unusedFlatpakRuntimes() ([]flatpakRuntime, error) {
+	runtimes, err := listFlatpak("--runtime", "--columns=application,arch,branch,installation")
+	if err != nil {
+		return nil, err
+	}
+	apps, err := listFlatpak("--app", "--columns=runtime")
+	if err != nil {
+		return nil, err
+	}
+	return unusedFlatpakRuntimes(parseFlatpakRuntimes(runtimes), apps), nil
+}
+
+// flatpakSystemCaches возвращает временные кэши системного помощника Flatpak
+func flatpakSystemCaches() []string {
+	paths, _ := filepath.Glob("/var/tmp/flatpak-cache-*")
+	return paths
+}
+
+// flatpakAppCaches возвращает кэши приложений пользователя в ~/.var/app/*/cache
+func flatpakAppCaches(homeDir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(homeDir, ".var/app/*/cache"))
+	return paths
+}
+
+// flatpakCacheName возвращает имя приложения для кэша в ~/.var/app
+func flatpakCacheName(path string) string {
+	if filepath.Base(path) == "cache" {
+		return filepath.Base(filepath.Dir(path))
+	}
+	return filepath.Base(path)
+}
+
+func (m *CleanupModule) previewFlatpak(homeDirs []string) []CleanupItem {
+	var items []CleanupItem
+
+	homeDir, _ := os.UserHomeDir()
+	if runtimes, err := m.unusedFlatpakRuntimes(); err == nil {
+		for _, runtime := range runtimes {
+			size, _ := m.getDirSize(runtime.Dir(homeDir))
+			items = append(items, CleanupItem{Category: "Unused Flatpak runtime", Path: runtime.Ref(), Size: size})
+		}
+	}
+
+	paths := flatpakSystemCaches()
+	for _, homeDir := range homeDirs {
+		paths = append(paths, flatpakAppCaches(homeDir)...)
+	}
+	for _, path := range paths {
+		if size, err := m.getDirSize(path); err == nil && size > 0 {
+			items = append(items, CleanupItem{Category: "Flatpak cache", Path: path, Size: size})
+		}
+	}
+
+	return items
+}
+
+func (m *CleanupModule) cleanFlatpak(report func(message string)) (int64, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return 0, err
+	}
+
+	var totalFreed int64
+
+	if runtimes, err := m.unusedFlatpakRuntimes(); err == nil && len(runtimes) > 0 {
+		sizes := make(map[string]int64)
+		for _, runtime := range runtimes {
+			sizes[runtime.Ref()], _ = m.getDirSize(runtime.Dir(homeDir))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		exec.CommandContext(ctx, "flatpak", "uninstall", "--unused", "-y", "--noninteractive").Run()
+		cancel()
+
+		// Сам flatpak решает, что удалять, поэтому считаем только то, что действительно пропало
+		for _, runtime := range runtimes {
+			m.diskScanner().Invalidate(runtime.Dir(homeDir))
+			if _, err := os.Stat(runtime.Dir(homeDir)); os.IsNotExist(err) {
+				totalFreed += sizes[runtime.Ref()]
+				report(fmt.Sprintf("%s: %d MB freed", runtime.Ref(), sizes[runtime.Ref()]/1024/1024))
+			}
+		}
+	}
+
+	totalFreed += m.removeFlatpakCaches(flatpakSystemCaches(), report)
+
+	return totalFreed, nil
+}
+
+// cleanFlatpakCachesFor очищает кэши Flatpak-приложений пользователя
+func (m *CleanupModule) cleanFlatpakCachesFor(homeDir string, report func(message string)) (int64, error) {
+	return m.removeFlatpakCaches(flatpakAppCaches(homeDir), report), nil
+}
+
+func (m *CleanupModule) removeFlatpakCaches(paths []string, report func(message string)) int64 {
+	var totalFreed int64
+	for _, path := range paths {
+		// Приложение ожидает, что каталог кэша существует
+		size := m.removeMeasured(path, filepath.Base(path) == "cache")
+		if size == 0 {
+			continue
+		}
+		totalFreed += size
+		report(fmt.Sprintf("%s cache: %d MB freed", flatpakCacheName(path), size/1024/1024))
+	}
+	return totalFreed
+}
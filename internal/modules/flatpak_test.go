@@ -0,0 +1,65 @@
+package modules
+
+import (
+	"testing"
+)
+
+const sampleFlatpakRuntimes = `org.freedesktop.Platform	x86_64	22.08	system
+org.freedesktop.Platform	x86_64	23.08	system
+org.freedesktop.Platform.GL.default	x86_64	23.08	system
+org.freedesktop.Platform.openh264	x86_64	2.2.0	system
+org.gnome.Platform	x86_64	44	system
+org.gnome.Platform.Locale	x86_64	44	system
+org.kde.Platform	x86_64	5.15-22.08	user
+`
+
+const sampleFlatpakApps = `org.freedesktop.Platform/x86_64/23.08
+org.gnome.Platform/x86_64/44
+`
+
+func TestParseFlatpakRuntimes(t *testing.T) {
+	runtimes := parseFlatpakRuntimes(sampleFlatpakRuntimes)
+	if len(runtimes) != 7 {
+		t.Fatalf("Expected 7 runtimes, got %d", len(runtimes))
+	}
+
+	kde := runtimes[6]
+	if kde.Ref() != "org.kde.Platform/x86_64/5.15-22.08" {
+		t.Errorf("Ref() = %s", kde.Ref())
+	}
+	if kde.Dir("/home/user") != "/home/user/.local/share/flatpak/runtime/org.kde.Platform/x86_64/5.15-22.08" {
+		t.Errorf("Dir() for user installation = %s", kde.Dir("/home/user"))
+	}
+	if runtimes[0].Dir("/home/user") != "/var/lib/flatpak/runtime/org.freedesktop.Platform/x86_64/22.08" {
+		t.Errorf("Dir() for system installation = %s", runtimes[0].Dir("/home/user"))
+	}
+}
+
+func TestUnusedFlatpakRuntimes(t *testing.T) {
+	unused := unusedFlatpakRuntimes(parseFlatpakRuntimes(sampleFlatpakRuntimes), sampleFlatpakApps)
+
+	expected := map[string]bool{
+		"org.freedesktop.Platform/x86_64/22.08": true,
+		"org.kde.Platform/x86_64/5.15-22.08":    true,
+	}
+	if len(unused) != len(expected) {
+		t.Fatalf("Expected %d unused runtimes, got %v", len(expected), unused)
+	}
+	for _, runtime := range unused {
+		if !expected[runtime.Ref()] {
+			t.Errorf("Runtime %s should not be reported as unused", runtime.Ref())
+		}
+	}
+}
+
+func TestFlatpakCacheName(t *testing.T) {
+	tests := map[string]string{
+		"/home/user/.var/app/org.mozilla.firefox/cache": "org.mozilla.firefox",
+		"/var/tmp/flatpak-cache-AB12CD":                 "flatpak-cache-AB12CD",
+	}
+	for path, expected := range tests {
+		if got := flatpakCacheName(path); got != expected {
+			t.Errorf("flatpakCacheName(%s) = %s, want %s", path, got, expected)
+		}
+	}
+}
@@ -3,13 +3,53 @@ package modules
 import (
 	"fmt"
 	"io/ioutil"
-	"os/exec"
 	"strconv"
 	"strings"
 )
 
 type HealthModule struct{}
 
+// Severity - насколько серьезна найденная проблема
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityCritical:
+		return "❌ CRITICAL"
+	case SeverityWarning:
+		return "⚠️ WARNING"
+	default:
+		return "ℹ️ INFO"
+	}
+}
+
+// Finding - проблема, найденная при проверке системы
+type Finding struct {
+	Severity Severity
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Severity, f.Message)
+}
+
+// worstSeverity возвращает самую серьезную оценку среди findings
+func worstSeverity(findings []Finding) Severity {
+	worst := SeverityInfo
+	for _, finding := range findings {
+		if finding.Severity > worst {
+			worst = finding.Severity
+		}
+	}
+	return worst
+}
+
 func (m *HealthModule) GetName() string {
 	return "System Health Check"
 }
@@ -22,41 +62,40 @@ func (m *HealthModule) RequiresRoot() bool {
 	return false
 }
 
+// healthCheck - одна проверка системы. Результат может состоять из нескольких
+// строк, каждая сообщается отдельно
+type healthCheck struct {
+	start string
+	name  string
+	run   func() (string, error)
+}
+
 func (m *HealthModule) Execute(progressCallback func(progress float64, message string)) error {
-	progressCallback(0.1, "Checking disk health...")
-	
-	diskHealth, err := m.checkDiskHealth()
-	if err != nil {
-		progressCallback(0.25, fmt.Sprintf("Disk health check failed: %v", err))
-	} else {
-		progressCallback(0.25, diskHealth)
+	checks := []healthCheck{
+		{"Checking disk health...", "Disk health", m.checkDiskHealth},
+		{"Checking system temperature...", "Temperature", m.checkTemperature},
+		{"Checking CPU frequency and throttling...", "CPU frequency", m.checkCPUFrequency},
+		{"Analyzing memory usage...", "Memory", m.checkMemoryUsage},
+		{"Analyzing running processes...", "Process", m.analyzeProcesses},
+		{"Checking systemd units...", "Systemd", m.checkSystemdUnits},
+		{"Scanning kernel log for errors...", "Kernel log", m.checkKernelErrors},
+		{"Checking battery and power supply...", "Battery", m.checkBattery},
+		{"Checking network configuration...", "Network", m.checkNetwork},
 	}
 	
-	progressCallback(0.3, "Checking system temperature...")
-	
-	tempInfo, err := m.checkTemperature()
-	if err != nil {
-		progressCallback(0.5, fmt.Sprintf("Temperature check failed: %v", err))
-	} else {
-		progressCallback(0.5, tempInfo)
-	}
-	
-	progressCallback(0.6, "Analyzing memory usage...")
-	
-	memInfo, err := m.checkMemoryUsage()
-	if err != nil {
-		progressCallback(0.75, fmt.Sprintf("Memory check failed: %v", err))
-	} else {
-		progressCallback(0.75, memInfo)
-	}
-	
-	progressCallback(0.8, "Analyzing running processes...")
-	
-	procInfo, err := m.analyzeProcesses()
-	if err != nil {
-		progressCallback(0.95, fmt.Sprintf("Process analysis failed: %v", err))
-	} else {
-		progressCallback(0.95, procInfo)
+	for i, check := range checks {
+		// Каждой проверке отводится равная доля общего прогресса
+		progressCallback(0.05+0.9*float64(i)/float64(len(checks)), check.start)
+		done := 0.05 + 0.9*float64(i+1)/float64(len(checks))
+		
+		result, err := check.run()
+		if err != nil {
+			progressCallback(done, fmt.Sprintf("%s check failed: %v", check.name, err))
+			continue
+		}
+		for _, line := range strings.Split(result, "\n") {
+			progressCallback(done, line)
+		}
 	}
 	
 	progressCallback(1.0, "System health check completed")
@@ -64,132 +103,93 @@ func (m *HealthModule) Execute(progressCallback func(progress float64, message s
 	return nil
 }
 
+// checkDiskHealth проверяет каждую смонтированную файловую систему и SMART
+// накопителей. Возвращает по строке на файловую систему, накопитель и проблему
 func (m *HealthModule) checkDiskHealth() (string, error) {
-	// Проверяем доступное место на диске
-	cmd := exec.Command("df", "-h", "/")
-	output, err := cmd.Output()
+	filesystems, err := CheckFilesystems()
 	if err != nil {
 		return "", err
 	}
-	
-	lines := strings.Split(string(output), "\n")
-	if len(lines) < 2 {
-		return "", fmt.Errorf("unexpected df output")
+	if len(filesystems) == 0 {
+		return "", fmt.Errorf("no mounted filesystems found")
 	}
 	
-	fields := strings.Fields(lines[1])
-	if len(fields) < 5 {
-		return "", fmt.Errorf("unexpected df fields")
+	var lines []string
+	for _, fs := range filesystems {
+		lines = append(lines, fs.String())
 	}
 	
-	used := fields[4] // Процент использования
-	usedPercent, err := strconv.Atoi(strings.TrimSuffix(used, "%"))
+	// Состояние накопителей по SMART
+	drives, err := CheckDrives()
 	if err != nil {
-		return "", err
+		lines = append(lines, Finding{Severity: SeverityInfo, Message: err.Error()}.String())
 	}
-	
-	var status string
-	if usedPercent > 90 {
-		status = "⚠️ WARNING: Disk usage is high"
-	} else if usedPercent > 80 {
-		status = "⚠️ CAUTION: Disk usage is moderate"
-	} else {
-		status = "✅ GOOD: Disk usage is normal"
-	}
-	
-	// Пробуем проверить SMART статус
-	cmd = exec.Command("sudo", "smartctl", "-H", "/dev/sda")
-	smartOutput, err := cmd.Output()
-	if err == nil && strings.Contains(string(smartOutput), "PASSED") {
-		status += " • SMART: PASSED"
+	for _, drive := range drives {
+		lines = append(lines, drive.String())
+		for _, finding := range drive.Findings() {
+			lines = append(lines, "  "+finding.String())
+		}
 	}
 	
-	return fmt.Sprintf("%s (%s used)", status, used), nil
+	return strings.Join(lines, "\n"), nil
 }
 
+// checkTemperature проверяет все датчики hwmon и thermal zones, каждый по его
+// собственным порогам. Первая строка - итог, дальше по строке на датчик
 func (m *HealthModule) checkTemperature() (string, error) {
-	// Проверяем температуру CPU
-	tempFiles := []string{
-		"/sys/class/thermal/thermal_zone0/temp",
-		"/sys/class/thermal/thermal_zone1/temp",
-	}
-	
-	var temps []int
-	for _, file := range tempFiles {
-		data, err := ioutil.ReadFile(file)
-		if err != nil {
-			continue
-		}
-		
-		tempStr := strings.TrimSpace(string(data))
-		temp, err := strconv.Atoi(tempStr)
-		if err != nil {
-			continue
-		}
-		
-		// Температура в милли-градусах, конвертируем в градусы
-		temps = append(temps, temp/1000)
-	}
-	
-	if len(temps) == 0 {
+	sensors := ReadSensors()
+	hottest, ok := hottestSensor(sensors)
+	if !ok {
 		return "Temperature sensors not available", nil
 	}
 	
-	// Берем максимальную температуру
-	maxTemp := temps[0]
-	for _, temp := range temps {
-		if temp > maxTemp {
-			maxTemp = temp
+	worst := SeverityInfo
+	for _, sensor := range sensors {
+		if severity := sensor.Severity(); severity > worst {
+			worst = severity
 		}
 	}
 	
 	var status string
-	if maxTemp > 80 {
+	switch worst {
+	case SeverityCritical:
 		status = "🔥 HOT"
-	} else if maxTemp > 70 {
+	case SeverityWarning:
 		status = "⚠️ WARM"
-	} else {
+	default:
 		status = "❄️ COOL"
 	}
 	
-	return fmt.Sprintf("%s: CPU temperature %d°C", status, maxTemp), nil
+	lines := []string{fmt.Sprintf("%s: hottest sensor %s %.0f°C", status, hottest.Name(), hottest.Value)}
+	for _, sensor := range sensors {
+		lines = append(lines, "  "+sensor.String())
+	}
+	return strings.Join(lines, "\n"), nil
 }
 
-func (m *HealthModule) checkMemoryUsage() (string, error) {
-	data, err := ioutil.ReadFile("/proc/meminfo")
+// checkCPUFrequency ищет троттлинг и заниженные частоты процессора
+func (m *HealthModule) checkCPUFrequency() (string, error) {
+	cores, findings, err := CheckCPUFrequency()
 	if err != nil {
 		return "", err
 	}
 	
-	lines := strings.Split(string(data), "\n")
-	var memTotal, memAvailable int
-	
-	for _, line := range lines {
-		fields := strings.Fields(line)
-		if len(fields) < 2 {
-			continue
-		}
-		
-		value, err := strconv.Atoi(fields[1])
-		if err != nil {
-			continue
-		}
-		
-		switch fields[0] {
-		case "MemTotal:":
-			memTotal = value
-		case "MemFree:":
-			// memFree не используется, но оставляем для полноты
-		case "MemAvailable:":
-			memAvailable = value
-		}
+	lines := []string{summarizeCPUFreq(cores, findings)}
+	for _, finding := range findings {
+		lines = append(lines, "  "+finding.String())
 	}
-	
-	if memTotal == 0 {
-		return "", fmt.Errorf("could not parse memory info")
+	return strings.Join(lines, "\n"), nil
+}
+
+// checkMemoryUsage сообщает занятость памяти и swap, устройства подкачки,
+// zram, zswap, hugepages и OOM, а также ошибки их настройки
+func (m *HealthModule) checkMemoryUsage() (string, error) {
+	memory, err := ReadMemoryStatus()
+	if err != nil {
+		return "", err
 	}
 	
-	usedPercent := (memTotal - memAvailable) * 100 / memTotal
+	usedPercent := memory.UsedPercent()
 	
 	var status string
 	if usedPercent > 90 {
@@ -200,23 +200,60 @@ func (m *HealthModule) checkMemoryUsage() (string, error) {
 		status = "✅ NORMAL"
 	}
 	
-	return fmt.Sprintf("%s: Memory usage %d%% (%d MB / %d MB)", 
-		status, usedPercent, (memTotal-memAvailable)/1024, memTotal/1024), nil
-}
-
-func (m *HealthModule) analyzeProcesses() (string, error) {
-	// Подсчитываем количество процессов
-	cmd := exec.Command("ps", "aux")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+	lines := []string{fmt.Sprintf("%s: Memory usage %d%% (%d MB / %d MB)", 
+		status, usedPercent, (memory.Total-memory.Available)/1024/1024, memory.Total/1024/1024)}
+	
+	if memory.SwapTotal > 0 {
+		lines = append(lines, fmt.Sprintf("  Swap: %d%% used (%d MB / %d MB)",
+			memory.SwapUsedPercent(), (memory.SwapTotal-memory.SwapFree)/1024/1024, memory.SwapTotal/1024/1024))
+	} else {
+		lines = append(lines, "  Swap: none")
+	}
+	for _, swap := range memory.Swaps {
+		line := fmt.Sprintf("    %s (%s, priority %d): %d / %d MB", swap.Path, swap.Type, swap.Priority, swap.Used/1024/1024, swap.Size/1024/1024)
+		switch {
+		case swap.Zram():
+		case swap.Disk == "":
+		case swap.Rotational:
+			line += fmt.Sprintf(" on HDD %s", swap.Disk)
+		case swap.WearUsed >= 0:
+			line += fmt.Sprintf(" on SSD %s, %d%% worn", swap.Disk, swap.WearUsed)
+		default:
+			line += fmt.Sprintf(" on SSD %s", swap.Disk)
+		}
+		lines = append(lines, line)
+	}
+	for _, zram := range memory.Zram {
+		line := fmt.Sprintf("  zram %s: %d MB, %s", zram.Name, zram.DiskSize/1024/1024, zram.Algorithm)
+		if zram.Compressed > 0 {
+			line += fmt.Sprintf(", %d MB stored in %d MB (%.1fx)", zram.OrigData/1024/1024, zram.MemUsed/1024/1024,
+				float64(zram.OrigData)/float64(zram.Compressed))
+		}
+		lines = append(lines, line)
 	}
 	
-	lines := strings.Split(string(output), "\n")
-	processCount := len(lines) - 2 // Убираем заголовок и пустую строку
+	zswap := "off"
+	if memory.ZswapEnabled {
+		zswap = "on (" + memory.ZswapCompressor + ")"
+	}
+	hugepages := "none"
+	if memory.HugePagesTotal > 0 {
+		hugepages = fmt.Sprintf("%d of %d free", memory.HugePagesFree, memory.HugePagesTotal)
+	}
+	lines = append(lines, fmt.Sprintf("  zswap %s • THP %s (defrag %s) • hugepages %s • %d OOM kills since boot",
+		zswap, memory.THP, memory.THPDefrag, hugepages, memory.OOMKills))
 	
-	// Проверяем загрузку системы
-	data, err := ioutil.ReadFile("/proc/loadavg")
+	for _, finding := range memory.Findings() {
+		lines = append(lines, "  "+finding.String())
+	}
+	
+	return strings.Join(lines, "\n"), nil
+}
+
+// analyzeProcesses оценивает загрузку относительно числа процессоров, давление
+// на ресурсы (PSI), находит самых активных потребителей и процессы с аномалиями
+func (m *HealthModule) analyzeProcesses() (string, error) {
+	data, err := ioutil.ReadFile(loadAvgPath)
 	if err != nil {
 		return "", err
 	}
@@ -231,15 +268,180 @@ func (m *HealthModule) analyzeProcesses() (string, error) {
 		return "", err
 	}
 	
+	// Нагрузка 2.0 критична для двух ядер и незаметна для шестидесяти четырех
+	cpus := onlineCPUs()
+	perCPU := load1min / float64(cpus)
+	
 	var loadStatus string
-	if load1min > 2.0 {
+	if perCPU > 1.0 {
 		loadStatus = "🔴 HIGH"
-	} else if load1min > 1.0 {
+	} else if perCPU > 0.7 {
 		loadStatus = "⚠️ MODERATE"
 	} else {
 		loadStatus = "✅ LOW"
 	}
 	
-	return fmt.Sprintf("%s load (%.2f) • %d active processes", 
-		loadStatus, load1min, processCount), nil
-}
\ No newline at end of file
+	sample := SampleProcesses()
+	usage := sample.Usage()
+	lines := []string{fmt.Sprintf("%s load (%.2f on %d CPUs, %.2f per CPU) • %d active processes",
+		loadStatus, load1min, cpus, perCPU, len(usage))}
+	
+	if pressure, err := ReadPressure(); err == nil {
+		lines = append(lines, fmt.Sprintf("  Pressure (last minute): cpu %.1f%% • memory %.1f%% • io %.1f%%",
+			pressure["cpu"].SomeAvg60, pressure["memory"].SomeAvg60, pressure["io"].SomeAvg60))
+		for _, finding := range pressureFindings(pressure) {
+			lines = append(lines, "  "+finding.String())
+		}
+	}
+	
+	for _, line := range topConsumerLines(usage) {
+		lines = append(lines, "  "+line)
+	}
+	
+	for _, anomaly := range FindProcessAnomalies(sample) {
+		lines = append(lines, "  "+anomaly.Finding().String())
+	}
+	
+	return strings.Join(lines, "\n"), nil
+}
+
+// checkSystemdUnits ищет упавшие, зацикленные и замаскированные юниты
+// и опоздавшие таймеры. К каждой проблеме добавляются строки из журнала
+func (m *HealthModule) checkSystemdUnits() (string, error) {
+	problems, err := CheckUnits()
+	if err != nil {
+		return "", err
+	}
+	
+	counts := make(map[string]int)
+	var findings []Finding
+	for _, problem := range problems {
+		counts[problem.Kind]++
+		findings = append(findings, problem.Finding())
+	}
+	
+	status := "✅ GOOD"
+	if worst := worstSeverity(findings); worst > SeverityInfo {
+		status = worst.String()
+	}
+	lines := []string{fmt.Sprintf("%s: %d failed • %d restarting • %d masked units • %d overdue timers",
+		status, counts[UnitFailed], counts[UnitRestartLoop], counts[UnitMasked], counts[UnitTimerLate])}
+	
+	for _, problem := range problems {
+		lines = append(lines, "  "+problem.Finding().String())
+		for _, log := range problem.Logs {
+			lines = append(lines, "    │ "+shorten(log))
+		}
+	}
+	
+	return strings.Join(lines, "\n"), nil
+}
+
+// checkKernelErrors сводит ошибки ядра и оборудования с момента загрузки
+// по подсистемам с числом сообщений и временем первого и последнего
+func (m *HealthModule) checkKernelErrors() (string, error) {
+	groups, err := ScanKernelErrors()
+	if err != nil {
+		return "", err
+	}
+	
+	if len(groups) == 0 {
+		return "✅ GOOD: No kernel or hardware errors since boot", nil
+	}
+	
+	var findings []Finding
+	total := 0
+	for _, group := range groups {
+		findings = append(findings, group.Finding())
+		total += group.Count
+	}
+	
+	lines := []string{fmt.Sprintf("%s: %d kernel errors since boot in %d subsystems",
+		worstSeverity(findings), total, len(groups))}
+	for _, group := range groups {
+		lines = append(lines, "  "+group.Finding().String())
+		lines = append(lines, "    │ "+shorten(group.Example))
+	}
+	
+	return strings.Join(lines, "\n"), nil
+}
+
+// checkBattery сообщает износ, циклы и пороги заряда аккумуляторов, питание
+// от сети и потребляемую мощность, а также тренд износа по истории запусков
+func (m *HealthModule) checkBattery() (string, error) {
+	power := ReadPowerSupply()
+	
+	ac := "AC offline"
+	if power.ACOnline {
+		ac = "AC online"
+	}
+	if len(power.Batteries) == 0 {
+		if power.HasAC {
+			return "✅ GOOD: No battery, " + ac, nil
+		}
+		return "✅ GOOD: No battery", nil
+	}
+	
+	var lines []string
+	for _, battery := range power.Batteries {
+		findings := battery.Findings(power.ACOnline)
+		status := "✅ GOOD"
+		if worst := worstSeverity(findings); worst > SeverityInfo {
+			status = worst.String()
+		}
+		
+		lines = append(lines, fmt.Sprintf("%s: %s • %s", status, battery, ac))
+		if details := battery.Details(); details != "" {
+			lines = append(lines, "  "+details)
+		}
+		if trend, ok := RecordBattery(battery); ok {
+			lines = append(lines, "  Trend: "+trend.String())
+		}
+		for _, finding := range findings {
+			lines = append(lines, "  "+finding.String())
+		}
+	}
+	
+	return strings.Join(lines, "\n"), nil
+}
+
+// checkNetwork сообщает состояние интерфейсов, маршрута по умолчанию, DNS,
+// синхронизации времени и NetworkManager по локальным данным
+func (m *HealthModule) checkNetwork() (string, error) {
+	network := CheckNetwork()
+	findings := network.Findings()
+	
+	status := "✅ GOOD"
+	if worst := worstSeverity(findings); worst > SeverityInfo {
+		status = worst.String()
+	}
+	
+	route := "no default route"
+	if len(network.Routes) > 0 {
+		route = "default route via " + network.Routes[0].Interface
+		if network.Routes[0].Gateway != "" {
+			route += " (" + network.Routes[0].Gateway + ")"
+		}
+	}
+	dns := strings.Join(network.Resolver.Nameservers, ", ")
+	if network.Resolver.Stub() {
+		dns = "systemd-resolved " + network.ResolvedActive
+	}
+	lines := []string{fmt.Sprintf("%s: %s • DNS %s", status, route, dns)}
+	
+	for _, iface := range network.Interfaces {
+		lines = append(lines, fmt.Sprintf("  %s %s: rx %d MB, tx %d MB, %d errors, %d dropped", iface.Name, iface.State,
+			iface.RxBytes/1024/1024, iface.TxBytes/1024/1024, iface.RxErrors+iface.TxErrors, iface.RxDropped+iface.TxDropped))
+	}
+	if network.TimeSync != nil {
+		lines = append(lines, fmt.Sprintf("  Time sync: NTP %s, synchronized %s", network.TimeSync["NTP"], network.TimeSync["NTPSynchronized"]))
+	}
+	if network.NMState != "" {
+		lines = append(lines, fmt.Sprintf("  NetworkManager: %s, connectivity %s", network.NMState, network.NMConnectivity))
+	}
+	for _, finding := range findings {
+		lines = append(lines, "  "+finding.String())
+	}
+	
+	return strings.Join(lines, "\n"), nil
+}
@@ -41,6 +41,8 @@ func TestSystemModuleInterface(t *testing.T) {
 		&UpdatesModule{},
 		&DriversModule{},
 		&CleanupModule{},
+		&ContainerModule{},
+		&DuplicatesModule{},
 		&OptimizationModule{},
 		&HealthModule{},
 	}
@@ -0,0 +1,203 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// kernelSignature - известный признак сбоя в журнале и подсистема, к которой он относится
+type kernelSignature struct {
+	subsystem string
+	severity  Severity
+	pattern   *regexp.Regexp
+}
+
+// kernelSignatures проверяются по порядку, сообщение относится к первой
+// совпавшей подсистеме: ошибка ext4 с "I/O error" - это сбой файловой системы
+var kernelSignatures = []kernelSignature{
+	{"Hardware (MCE/EDAC)", SeverityCritical, regexp.MustCompile(`(?i)\[Hardware Error\]|machine check|\bEDAC\b|\bmce:`)},
+	{"Filesystem", SeverityCritical, regexp.MustCompile(`(?i)(EXT4-fs|XFS|BTRFS|F2FS|FAT-fs)( \([^)]*\))?:? (error|critical|corrupt)|remounting filesystem read-only|corruption detected`)},
+	{"Disk I/O", SeverityCritical, regexp.MustCompile(`(?i)I/O error|blk_update_request|critical medium error|ata[0-9.]+: (exception|failed command)|nvme[0-9]+: (controller is down|I/O [0-9]+ .*timeout)`)},
+	{"Out of memory", SeverityWarning, regexp.MustCompile(`(?i)out of memory: kill|oom-kill:|memory cgroup out of memory|killed .* due to memory pressure`)},
+	{"GPU", SeverityWarning, regexp.MustCompile(`(?i)gpu hang|ring [a-z0-9_.]+ timeout|\*ERROR\* .*(hang|timeout)|NVRM: Xid|nouveau .* fault`)},
+	{"Firmware", SeverityWarning, regexp.MustCompile(`(?i)firmware.*(fail|error)|(fail|unable) to load.*firmware|direct firmware load`)},
+}
+
+// KernelEvent - одно сообщение журнала или кольцевого буфера ядра
+type KernelEvent struct {
+	Time    time.Time
+	Message string
+	// Kernel - сообщение пришло от ядра, а не от службы
+	Kernel bool
+}
+
+// KernelErrorGroup - сообщения об ошибках одной подсистемы с момента загрузки
+type KernelErrorGroup struct {
+	Subsystem string
+	Severity  Severity
+	Count     int
+	First     time.Time
+	Last      time.Time
+	// Example - последнее сообщение группы
+	Example string
+}
+
+// Finding описывает группу ошибок для отчета о здоровье системы
+func (g KernelErrorGroup) Finding() Finding {
+	when := g.Last.Format("15:04:05")
+	if g.Count > 1 {
+		when = fmt.Sprintf("%s - %s", g.First.Format("15:04:05"), g.Last.Format("15:04:05"))
+	}
+	return Finding{
+		Severity: g.Severity,
+		Message:  fmt.Sprintf("%s: %d errors (%s)", g.Subsystem, g.Count, when),
+	}
+}
+
+// journalEntry - поля записи journalctl -o json, которые нужны для анализа
+type journalEntry struct {
+	Message   json.RawMessage `json:"MESSAGE"`
+	Timestamp string          `json:"__REALTIME_TIMESTAMP"`
+	Transport string          `json:"_TRANSPORT"`
+}
+
+// journalMessage декодирует MESSAGE: journalctl отдает строку, а сообщения
+// не в UTF-8 - массивом байтов
+func journalMessage(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var bytes []byte
+	var numbers []int
+	if err := json.Unmarshal(raw, &numbers); err == nil {
+		for _, number := range numbers {
+			bytes = append(bytes, byte(number))
+		}
+	}
+	return string(bytes)
+}
+
+// parseJournalJSON разбирает вывод journalctl -o json: по объекту на строку
+func parseJournalJSON(r io.Reader) []KernelEvent {
+	var events []KernelEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		event := KernelEvent{Message: journalMessage(entry.Message), Kernel: entry.Transport == "kernel"}
+		if micros, err := strconv.ParseInt(entry.Timestamp, 10, 64); err == nil {
+			event.Time = time.UnixMicro(micros)
+		}
+		if event.Message != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// parseDmesg разбирает вывод dmesg --time-format iso:
+// "2024-01-06T10:00:00,123456+00:00 сообщение"
+func parseDmesg(r io.Reader) []KernelEvent {
+	var events []KernelEvent
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		stamp, message, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		event := KernelEvent{Message: strings.TrimSpace(message), Kernel: true}
+		if parsed, err := time.Parse("2006-01-02T15:04:05,999999-07:00", stamp); err == nil {
+			event.Time = parsed
+		}
+		if event.Message != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// groupKernelErrors относит сообщения к подсистемам по известным признакам.
+// Сообщения без известного признака не учитываются
+func groupKernelErrors(events []KernelEvent) []KernelErrorGroup {
+	groups := make(map[string]*KernelErrorGroup)
+	for _, event := range events {
+		for _, signature := range kernelSignatures {
+			if !signature.pattern.MatchString(event.Message) {
+				continue
+			}
+			group, ok := groups[signature.subsystem]
+			if !ok {
+				group = &KernelErrorGroup{Subsystem: signature.subsystem, Severity: signature.severity, First: event.Time}
+				groups[signature.subsystem] = group
+			}
+			group.Count++
+			if event.Time.Before(group.First) {
+				group.First = event.Time
+			}
+			if !event.Time.Before(group.Last) {
+				group.Last = event.Time
+				group.Example = event.Message
+			}
+			break
+		}
+	}
+
+	var result []KernelErrorGroup
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Severity != result[j].Severity {
+			return result[i].Severity > result[j].Severity
+		}
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Subsystem < result[j].Subsystem
+	})
+	return result
+}
+
+// ScanKernelErrors ищет сбои оборудования и ядра в сообщениях с приоритетом
+// err и выше с момента загрузки. Кольцевой буфер ядра читается, только если
+// в журнале нет сообщений ядра: иначе они были бы посчитаны дважды
+func ScanKernelErrors() ([]KernelErrorGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var events []KernelEvent
+	journalOutput, journalErr := exec.CommandContext(ctx, "journalctl", "-b", "-p", "err",
+		"-o", "json", "--no-pager").Output()
+	if journalErr == nil {
+		events = parseJournalJSON(strings.NewReader(string(journalOutput)))
+	}
+
+	hasKernel := false
+	for _, event := range events {
+		hasKernel = hasKernel || event.Kernel
+	}
+	if !hasKernel {
+		dmesgOutput, dmesgErr := exec.CommandContext(ctx, "dmesg", "--level=emerg,alert,crit,err",
+			"--time-format", "iso").Output()
+		if dmesgErr == nil {
+			events = append(events, parseDmesg(strings.NewReader(string(dmesgOutput)))...)
+		} else if journalErr != nil {
+			return nil, fmt.Errorf("journalctl failed: %v; dmesg failed: %v", journalErr, dmesgErr)
+		}
+	}
+
+	return groupKernelErrors(events), nil
+}
@@ -0,0 +1,96 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testJournalJSON = `{"MESSAGE":"blk_update_request: I/O error, dev sda, sector 2048 op 0x0:(READ)","__REALTIME_TIMESTAMP":"1704535200000000","_TRANSPORT":"kernel"}
+{"MESSAGE":"EXT4-fs error (device sda1): ext4_find_entry:1455: inode #2: comm ls: reading directory lblock 0","__REALTIME_TIMESTAMP":"1704535260000000","_TRANSPORT":"kernel"}
+{"MESSAGE":"Buffer I/O error on dev sda, logical block 256, async page read","__REALTIME_TIMESTAMP":"1704535320000000","_TRANSPORT":"kernel"}
+{"MESSAGE":"Out of memory: Killed process 4242 (chrome) total-vm:8123456kB","__REALTIME_TIMESTAMP":"1704538800000000","_TRANSPORT":"kernel"}
+{"MESSAGE":[105,57,49,53,32,48,48,48,48,58,48,48,58,48,50,46,48,58,32,71,80,85,32,72,65,78,71],"__REALTIME_TIMESTAMP":"1704539000000000","_TRANSPORT":"kernel"}
+{"MESSAGE":"Failed to start Docker Application Container Engine.","__REALTIME_TIMESTAMP":"1704539100000000","_TRANSPORT":"journal"}
+not json
+`
+
+func TestParseJournalJSON(t *testing.T) {
+	events := parseJournalJSON(strings.NewReader(testJournalJSON))
+	if len(events) != 6 {
+		t.Fatalf("parseJournalJSON() returned %d events, want 6", len(events))
+	}
+	if !events[0].Time.Equal(time.Unix(1704535200, 0)) || !events[0].Kernel {
+		t.Errorf("events[0] = %+v", events[0])
+	}
+	// Сообщение в виде массива байтов
+	if events[4].Message != "i915 0000:00:02.0: GPU HANG" {
+		t.Errorf("byte array message = %q", events[4].Message)
+	}
+	if events[5].Kernel {
+		t.Error("service message should not be marked as kernel")
+	}
+}
+
+func TestParseDmesg(t *testing.T) {
+	output := "2024-01-06T10:00:00,123456+00:00 mce: [Hardware Error]: Machine check events logged\n" +
+		"2024-01-06T10:05:00,000000+00:00 Direct firmware load for iwlwifi-ty-a0-gf-a0-72.ucode failed with error -2\n"
+	events := parseDmesg(strings.NewReader(output))
+	if len(events) != 2 {
+		t.Fatalf("parseDmesg() returned %d events, want 2", len(events))
+	}
+	want := time.Date(2024, 1, 6, 10, 0, 0, 123456000, time.UTC)
+	if !events[0].Time.Equal(want) {
+		t.Errorf("events[0].Time = %v, want %v", events[0].Time, want)
+	}
+	if !strings.HasPrefix(events[1].Message, "Direct firmware load") {
+		t.Errorf("events[1].Message = %q", events[1].Message)
+	}
+}
+
+func TestGroupKernelErrors(t *testing.T) {
+	events := parseJournalJSON(strings.NewReader(testJournalJSON))
+	events = append(events, parseDmesg(strings.NewReader(
+		"2024-01-06T10:00:00,000000+00:00 mce: [Hardware Error]: CPU 0: Machine Check: 0 Bank 5\n"+
+			"2024-01-06T10:05:00,000000+00:00 Direct firmware load for iwlwifi.ucode failed with error -2\n"))...)
+
+	groups := groupKernelErrors(events)
+	counts := make(map[string]int)
+	for _, group := range groups {
+		counts[group.Subsystem] = group.Count
+	}
+	want := map[string]int{
+		"Disk I/O":            2,
+		"Filesystem":          1,
+		"Out of memory":       1,
+		"GPU":                 1,
+		"Hardware (MCE/EDAC)": 1,
+		"Firmware":            1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("groupKernelErrors() = %v, want %v", counts, want)
+	}
+	for subsystem, count := range want {
+		if counts[subsystem] != count {
+			t.Errorf("%s count = %d, want %d", subsystem, counts[subsystem], count)
+		}
+	}
+
+	if groups[0].Severity != SeverityCritical {
+		t.Errorf("critical groups should come first, got %+v", groups[0])
+	}
+	for _, group := range groups {
+		if group.Subsystem != "Disk I/O" {
+			continue
+		}
+		if !group.First.Equal(time.Unix(1704535200, 0)) || !group.Last.Equal(time.Unix(1704535320, 0)) {
+			t.Errorf("Disk I/O first/last = %v / %v", group.First, group.Last)
+		}
+		if !strings.HasPrefix(group.Example, "Buffer I/O error") {
+			t.Errorf("Disk I/O example = %q, want the latest message", group.Example)
+		}
+		if finding := group.Finding(); finding.Severity != SeverityCritical || !strings.Contains(finding.Message, "2 errors") {
+			t.Errorf("Finding() = %+v", finding)
+		}
+	}
+}
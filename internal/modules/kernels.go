@@ -0,0 +1,276 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const defaultKernelsToKeep = 2
+
+// kernelVersionRe выделяет ABI-версию ядра из имени пакета:
+// linux-image-6.5.0-14-generic -> 6.5.0-14, flavor generic. Общие заголовки
+// HWE и OEM ядер названы по исходному пакету: linux-hwe-6.5-headers-6.5.0-14
+var kernelVersionRe = regexp.MustCompile(`^linux-(?:image|image-unsigned|modules|modules-extra|headers|(?:[a-z]+-)+\d+\.\d+-headers)-(\d+\.\d+\.\d+-\d+)(?:-([a-z0-9-]+))?$`)
+
+var kernelABIRe = regexp.MustCompile(`^\d+\.\d+\.\d+-\d+`)
+
+// installedKernel - все пакеты одной версии ядра
+type installedKernel struct {
+	Version  string   // ABI-версия, например 6.5.0-14
+	Releases []string // полные версии с flavor, как в uname -r
+	Packages []string
+}
+
+// hasImage сообщает, установлен ли образ ядра. Без него версия - это
+// оставшиеся заголовки или модули, загрузиться с нее нельзя
+func (k installedKernel) hasImage() bool {
+	for _, pkg := range k.Packages {
+		if strings.HasPrefix(pkg, "linux-image-") {
+			return true
+		}
+	}
+	return false
+}
+
+// kernelSpace - место, занимаемое ядром
+type kernelSpace struct {
+	Boot    int64
+	Modules int64
+}
+
+// parseKernelPackages разбирает вывод
+// `dpkg-query -W -f='${Package}\t${db:Status-Abbrev}\n' 'linux-*'`
+func parseKernelPackages(output string) []installedKernel {
+	byVersion := make(map[string]*installedKernel)
+
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], "ii") {
+			continue // Только установленные пакеты
+		}
+
+		match := kernelVersionRe.FindStringSubmatch(fields[0])
+		if match == nil {
+			continue // Мета-пакеты вроде linux-image-generic
+		}
+
+		kernel, ok := byVersion[match[1]]
+		if !ok {
+			kernel = &installedKernel{Version: match[1]}
+			byVersion[match[1]] = kernel
+		}
+		kernel.Packages = append(kernel.Packages, fields[0])
+		if match[2] != "" {
+			release := match[1] + "-" + match[2]
+			if !containsString(kernel.Releases, release) {
+				kernel.Releases = append(kernel.Releases, release)
+			}
+		}
+	}
+
+	var kernels []installedKernel
+	for _, kernel := range byVersion {
+		kernels = append(kernels, *kernel)
+	}
+	// Самые новые ядра - первыми
+	sort.Slice(kernels, func(i, j int) bool {
+		return compareKernelVersions(kernels[i].Version, kernels[j].Version) > 0
+	})
+	return kernels
+}
+
+// compareKernelVersions сравнивает версии по числовым компонентам
+func compareKernelVersions(a, b string) int {
+	split := func(v string) []int {
+		var parts []int
+		for _, part := range strings.FieldsFunc(v, func(r rune) bool { return r < '0' || r > '9' }) {
+			n, _ := strconv.Atoi(part)
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if pa[i] != pb[i] {
+			if pa[i] > pb[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return len(pa) - len(pb)
+}
+
+// kernelABI отрезает flavor от версии ядра: 6.5.0-14-generic -> 6.5.0-14
+func kernelABI(release string) string {
+	return kernelABIRe.FindString(release)
+}
+
+// planKernelRemoval выбирает ядра к удалению: всегда остаются запущенное ядро
+// и keep самых новых ядер с образом. kernels должны быть отсортированы от новых к старым
+func planKernelRemoval(kernels []installedKernel, running string, keep int) ([]installedKernel, error) {
+	runningABI := kernelABI(running)
+	if runningABI == "" {
+		return nil, fmt.Errorf("cannot determine running kernel version from %q", running)
+	}
+
+	found := false
+	for _, kernel := range kernels {
+		if kernel.Version == runningABI {
+			found = true
+			break
+		}
+	}
+	if !found {
+		// Запущенное ядро не из пакетов - не можем безопасно решать, что удалять
+		return nil, fmt.Errorf("running kernel %s is not among installed kernel packages", running)
+	}
+
+	// Место в keep занимают только ядра с образом: версия из одних заголовков
+	// не защищает от удаления ни одно загружаемое ядро
+	var removals []installedKernel
+	rank := 0
+	for _, kernel := range kernels {
+		newest := false
+		if kernel.hasImage() {
+			newest = rank < keep
+			rank++
+		}
+		if newest || kernel.Version == runningABI {
+			continue
+		}
+		removals = append(removals, kernel)
+	}
+	return removals, nil
+}
+
+// runningKernel возвращает версию запущенного ядра, как uname -r
+func runningKernel() (string, error) {
+	var uts syscall.Utsname
+	if err := syscall.Uname(&uts); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, c := range uts.Release {
+		if c == 0 {
+			break
+		}
+		b.WriteByte(byte(c))
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("empty kernel release")
+	}
+	return b.String(), nil
+}
+
+// measureKernel считает место ядра в bootDir и modulesDir
+func (m *CleanupModule) measureKernel(kernel installedKernel, bootDir, modulesDir string) kernelSpace {
+	var space kernelSpace
+	for _, release := range kernel.Releases {
+		for _, prefix := range []string{"vmlinuz", "initrd.img", "config", "System.map", "abi", "retpoline"} {
+			if info, err := os.Stat(filepath.Join(bootDir, prefix+"-"+release)); err == nil {
+				space.Boot += fileUsage(info).Allocated
+			}
+		}
+		if size, err := m.getDirSize(filepath.Join(modulesDir, release)); err == nil {
+			space.Modules += size
+		}
+	}
+	return space
+}
+
+func (m *CleanupModule) kernelsToKeep() int {
+	if m.KernelsToKeep > 0 {
+		return m.KernelsToKeep
+	}
+	return defaultKernelsToKeep
+}
+
+// oldKernels возвращает ядра, которые можно удалить
+func (m *CleanupModule) oldKernels() ([]installedKernel, error) {
+	running, err := runningKernel()
+	if err != nil {
+		return nil, fmt.Errorf("cannot determine running kernel: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "dpkg-query", "-W", "-f=${Package}\t${db:Status-Abbrev}\n", "linux-*").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return planKernelRemoval(parseKernelPackages(string(output)), running, m.kernelsToKeep())
+}
+
+func (m *CleanupModule) previewOldKernels() []CleanupItem {
+	kernels, err := m.oldKernels()
+	if err != nil {
+		return nil
+	}
+
+	var items []CleanupItem
+	for _, kernel := range kernels {
+		space := m.measureKernel(kernel, "/boot", "/lib/modules")
+		items = append(items, CleanupItem{Category: "Old kernel", Path: kernel.Version, Size: space.Boot + space.Modules})
+	}
+	return items
+}
+
+func (m *CleanupModule) cleanOldKernels(report func(message string)) (int64, error) {
+	kernels, err := m.oldKernels()
+	if err != nil {
+		report(fmt.Sprintf("Kernel cleanup skipped: %v", err))
+		return 0, err
+	}
+
+	var bootStat syscall.Statfs_t
+	if err := syscall.Statfs("/boot", &bootStat); err == nil && bootStat.Blocks > 0 {
+		usedPercent := 100 - bootStat.Bavail*100/bootStat.Blocks
+		report(fmt.Sprintf("/boot is %d%% full, %d old kernel(s) to remove", usedPercent, len(kernels)))
+	}
+
+	var totalFreed int64
+	for _, kernel := range kernels {
+		space := m.measureKernel(kernel, "/boot", "/lib/modules")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		args := append([]string{"purge", "-y"}, kernel.Packages...)
+		err := exec.CommandContext(ctx, "apt-get", args...).Run()
+		cancel()
+		if err != nil {
+			report(fmt.Sprintf("Kernel %s: removal failed: %v", kernel.Version, err))
+			continue
+		}
+
+		for _, release := range kernel.Releases {
+			m.diskScanner().Invalidate(filepath.Join("/lib/modules", release))
+		}
+		totalFreed += space.Boot + space.Modules
+		report(fmt.Sprintf("Kernel %s removed: %d MB freed in /boot, %d MB in /lib/modules",
+			kernel.Version, space.Boot/1024/1024, space.Modules/1024/1024))
+	}
+
+	return totalFreed, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
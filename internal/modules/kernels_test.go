@@ -0,0 +1,179 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleKernelPackages = `linux-generic	ii
+linux-headers-6.5.0-14	ii
+linux-headers-6.5.0-14-generic	ii
+linux-image-6.5.0-14-generic	ii
+linux-modules-6.5.0-14-generic	ii
+linux-modules-extra-6.5.0-14-generic	ii
+linux-image-6.5.0-9-generic	ii
+linux-modules-6.5.0-9-generic	ii
+linux-image-6.2.0-39-generic	ii
+linux-modules-6.2.0-39-generic	ii
+linux-image-6.2.0-26-generic	rc
+linux-image-5.19.0-50-generic	ii
+linux-image-generic	ii
+`
+
+func TestParseKernelPackages(t *testing.T) {
+	kernels := parseKernelPackages(sampleKernelPackages)
+
+	expectedOrder := []string{"6.5.0-14", "6.5.0-9", "6.2.0-39", "5.19.0-50"}
+	if len(kernels) != len(expectedOrder) {
+		t.Fatalf("Expected %d kernels, got %d: %+v", len(expectedOrder), len(kernels), kernels)
+	}
+	for i, version := range expectedOrder {
+		if kernels[i].Version != version {
+			t.Errorf("kernels[%d] = %s, want %s", i, kernels[i].Version, version)
+		}
+	}
+
+	newest := kernels[0]
+	if len(newest.Packages) != 5 {
+		t.Errorf("Expected 5 packages for newest kernel, got %v", newest.Packages)
+	}
+	if len(newest.Releases) != 1 || newest.Releases[0] != "6.5.0-14-generic" {
+		t.Errorf("Releases = %v, want [6.5.0-14-generic]", newest.Releases)
+	}
+}
+
+func TestParseKernelPackages_HWEHeaders(t *testing.T) {
+	kernels := parseKernelPackages(`linux-hwe-6.5-headers-6.5.0-14	ii
+linux-headers-6.5.0-14-generic	ii
+linux-image-6.5.0-14-generic	ii
+linux-lowlatency-hwe-6.8-headers-6.8.0-40	ii
+linux-oem-6.1-headers-6.1.0-1025	ii
+linux-headers-generic-hwe-22.04	ii
+`)
+
+	var got []string
+	for _, kernel := range kernels {
+		got = append(got, kernel.Version+": "+strings.Join(kernel.Packages, " "))
+	}
+	want := []string{
+		"6.8.0-40: linux-lowlatency-hwe-6.8-headers-6.8.0-40",
+		"6.5.0-14: linux-hwe-6.5-headers-6.5.0-14 linux-headers-6.5.0-14-generic linux-image-6.5.0-14-generic",
+		"6.1.0-1025: linux-oem-6.1-headers-6.1.0-1025",
+	}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("parseKernelPackages() = %q, want %q", got, want)
+	}
+	if len(kernels) > 1 && len(kernels[1].Releases) != 1 {
+		t.Errorf("Releases = %v, want only 6.5.0-14-generic", kernels[1].Releases)
+	}
+}
+
+func TestPlanKernelRemoval(t *testing.T) {
+	kernels := parseKernelPackages(sampleKernelPackages)
+
+	tests := []struct {
+		name     string
+		running  string
+		keep     int
+		expected []string
+	}{
+		{"running is newest", "6.5.0-14-generic", 2, []string{"6.2.0-39", "5.19.0-50"}},
+		{"running is old", "5.19.0-50-generic", 1, []string{"6.5.0-9", "6.2.0-39"}},
+		{"keep everything", "6.5.0-14-generic", 10, nil},
+	}
+
+	for _, tt := range tests {
+		removals, err := planKernelRemoval(kernels, tt.running, tt.keep)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if len(removals) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %+v", tt.name, tt.expected, removals)
+			continue
+		}
+		for i, version := range tt.expected {
+			if removals[i].Version != version {
+				t.Errorf("%s: removal[%d] = %s, want %s", tt.name, i, removals[i].Version, version)
+			}
+		}
+	}
+}
+
+func TestPlanKernelRemoval_HeadersOnly(t *testing.T) {
+	// Заголовки более новой версии без образа не занимают место в keep
+	kernels := parseKernelPackages("linux-headers-6.8.0-1-generic\tii\n" + sampleKernelPackages)
+
+	removals, err := planKernelRemoval(kernels, "6.5.0-14-generic", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var versions []string
+	for _, kernel := range removals {
+		versions = append(versions, kernel.Version)
+	}
+	if strings.Join(versions, " ") != "6.8.0-1 6.2.0-39 5.19.0-50" {
+		t.Errorf("removals = %v, want 6.8.0-1 6.2.0-39 5.19.0-50 (6.5.0-9 kept)", versions)
+	}
+}
+
+func TestPlanKernelRemoval_UnknownRunningKernel(t *testing.T) {
+	kernels := parseKernelPackages(sampleKernelPackages)
+
+	// Без известного запущенного ядра ничего удалять нельзя
+	for _, running := range []string{"", "custom", "6.8.0-1-generic"} {
+		removals, err := planKernelRemoval(kernels, running, 1)
+		if err == nil {
+			t.Errorf("running=%q: expected error, got removals %+v", running, removals)
+		}
+		if len(removals) != 0 {
+			t.Errorf("running=%q: no kernels should be removed", running)
+		}
+	}
+}
+
+func TestCleanupModule_MeasureKernel(t *testing.T) {
+	module := &CleanupModule{}
+
+	root, err := os.MkdirTemp("", "ububu_kernel_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	bootDir := filepath.Join(root, "boot")
+	modulesDir := filepath.Join(root, "lib/modules/6.2.0-39-generic/kernel")
+	os.MkdirAll(bootDir, 0755)
+	os.MkdirAll(modulesDir, 0755)
+	os.WriteFile(filepath.Join(bootDir, "vmlinuz-6.2.0-39-generic"), make([]byte, 1000), 0644)
+	os.WriteFile(filepath.Join(bootDir, "initrd.img-6.2.0-39-generic"), make([]byte, 3000), 0644)
+	os.WriteFile(filepath.Join(bootDir, "vmlinuz-6.5.0-14-generic"), make([]byte, 5000), 0644)
+	os.WriteFile(filepath.Join(modulesDir, "ext4.ko"), make([]byte, 200), 0644)
+
+	kernel := installedKernel{Version: "6.2.0-39", Releases: []string{"6.2.0-39-generic"}}
+	space := module.measureKernel(kernel, bootDir, filepath.Join(root, "lib/modules"))
+
+	// Файлы в /boot считаются по занятым блокам, как и модули
+	want := allocatedSize(t, filepath.Join(bootDir, "vmlinuz-6.2.0-39-generic")) +
+		allocatedSize(t, filepath.Join(bootDir, "initrd.img-6.2.0-39-generic"))
+	if space.Boot != want {
+		t.Errorf("Boot space = %d, want %d", space.Boot, want)
+	}
+	if space.Modules <= 0 {
+		t.Errorf("Modules space = %d, want > 0", space.Modules)
+	}
+}
+
+func TestCompareKernelVersions(t *testing.T) {
+	if compareKernelVersions("6.5.0-14", "6.5.0-9") <= 0 {
+		t.Error("6.5.0-14 should be newer than 6.5.0-9")
+	}
+	if compareKernelVersions("5.19.0-50", "6.2.0-1") >= 0 {
+		t.Error("5.19.0-50 should be older than 6.2.0-1")
+	}
+	if compareKernelVersions("6.2.0-39", "6.2.0-39") != 0 {
+		t.Error("Equal versions should compare as 0")
+	}
+}
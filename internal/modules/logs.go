@@ -0,0 +1,272 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	systemLogDir            = "/var/log"
+	journalDir              = "/var/log/journal"
+	journaldDropIn          = "/etc/systemd/journald.conf.d/ububu.conf"
+	defaultOversizedLogSize = 100 * 1024 * 1024
+	defaultRotatedLogMaxAge = 7 * 24 * time.Hour
+	// runawayLogRate - лог, растущий быстрее (байт в секунду), считается
+	// признаком процесса, который пишет в него без остановки (~1.4 ГБ в сутки)
+	runawayLogRate = 1024 * 1024 / 60
+)
+
+// logSampleInterval - за сколько времени измеряется скорость роста логов
+var logSampleInterval = 2 * time.Second
+
+// rotatedLogRe распознает ротированные файлы: syslog.1, syslog.2.gz,
+// auth.log-20240101, kern.log.xz
+var rotatedLogRe = regexp.MustCompile(`(\.\d+(\.(gz|xz|bz2|zst))?|-\d{8}(\.(gz|xz|bz2|zst))?|\.(gz|xz|bz2|zst))$`)
+
+// LogFile - файл журнала в /var/log
+type LogFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Rotated bool
+	// Rate - скорость роста в байтах в секунду (только для активных логов)
+	Rate float64
+	// Writers - процессы, у которых файл открыт
+	Writers []string
+}
+
+// LogReport - результат анализа системных логов
+type LogReport struct {
+	// Oversized - активные логи больше порога
+	Oversized []LogFile
+	// Rotated - старые ротированные копии
+	Rotated []LogFile
+	// Runaway - логи, которые растут слишком быстро
+	Runaway []LogFile
+}
+
+// AnalyzeLogs находит в dir слишком большие активные логи, ротированные копии
+// и логи, которые быстро растут. Скорость роста измеряется за interval
+func AnalyzeLogs(dir string, maxSize int64, interval time.Duration) LogReport {
+	var report LogReport
+	var active []LogFile
+	// Видимый размер активных логов на момент обхода для подсчета скорости роста
+	sizes := make(map[string]int64)
+
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			// Журнал systemd чистится через journalctl
+			if path == filepath.Join(dir, "journal") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		log := LogFile{Path: path, Size: fileUsage(info).Allocated, ModTime: info.ModTime()}
+		if rotatedLogRe.MatchString(d.Name()) {
+			log.Rotated = true
+			report.Rotated = append(report.Rotated, log)
+			return nil
+		}
+		active = append(active, log)
+		sizes[path] = info.Size()
+		return nil
+	})
+
+	if interval > 0 && len(active) > 0 {
+		time.Sleep(interval)
+	}
+	for _, log := range active {
+		if info, err := os.Stat(log.Path); err == nil && interval > 0 {
+			log.Rate = float64(info.Size()-sizes[log.Path]) / interval.Seconds()
+		}
+		if log.Size >= maxSize {
+			report.Oversized = append(report.Oversized, log)
+		}
+		if log.Rate >= runawayLogRate {
+			log.Writers = logWriters(log.Path)
+			report.Runaway = append(report.Runaway, log)
+		}
+	}
+
+	sortLogs(report.Oversized)
+	sortLogs(report.Rotated)
+	sort.Slice(report.Runaway, func(i, j int) bool { return report.Runaway[i].Rate > report.Runaway[j].Rate })
+	return report
+}
+
+func sortLogs(logs []LogFile) {
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Size > logs[j].Size })
+}
+
+// logWriters находит процессы, у которых path открыт. Без прав root видны
+// только процессы текущего пользователя
+func logWriters(path string) []string {
+	procDirs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+
+	var writers []string
+	for _, procDir := range procDirs {
+		pid, err := strconv.Atoi(procDir.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", procDir.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || target != path {
+				continue
+			}
+			comm, _ := os.ReadFile(filepath.Join("/proc", procDir.Name(), "comm"))
+			writers = append(writers, fmt.Sprintf("%s (pid %d)", strings.TrimSpace(string(comm)), pid))
+			break
+		}
+	}
+	return writers
+}
+
+// journaldDropInContent формирует drop-in с постоянным лимитом журнала
+func journaldDropInContent(maxUse int64) string {
+	return fmt.Sprintf("# Managed by ububu\n[Journal]\nSystemMaxUse=%dM\n", maxUse/1024/1024)
+}
+
+// writeJournaldLimit записывает лимит размера журнала и перезапускает journald
+func writeJournaldLimit(path string, maxUse int64) error {
+	content := journaldDropInContent(maxUse)
+	if current, err := os.ReadFile(path); err == nil && string(current) == content {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	return exec.CommandContext(ctx, "systemctl", "restart", "systemd-journald").Run()
+}
+
+func (m *CleanupModule) oversizedLogSize() int64 {
+	if m.OversizedLogSize > 0 {
+		return m.OversizedLogSize
+	}
+	return defaultOversizedLogSize
+}
+
+func (m *CleanupModule) rotatedLogMaxAge() time.Duration {
+	if m.RotatedLogMaxAge > 0 {
+		return m.RotatedLogMaxAge
+	}
+	return defaultRotatedLogMaxAge
+}
+
+// vacuumJournal уменьшает журнал systemd по возрасту и, если задан лимит, по размеру
+func (m *CleanupModule) vacuumJournal() int64 {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	before := measurePath(journalDir).Allocated
+	args := []string{"--vacuum-time=7d"}
+	if m.JournalMaxSize > 0 {
+		args = append(args, fmt.Sprintf("--vacuum-size=%dM", m.JournalMaxSize/1024/1024))
+	}
+	exec.CommandContext(ctx, "journalctl", args...).Run() // Игнорируем ошибки
+
+	if freed := before - measurePath(journalDir).Allocated; freed > 0 {
+		return freed
+	}
+	return 0
+}
+
+// removeRotatedLogs удаляет ротированные копии старше maxAge
+func removeRotatedLogs(rotated []LogFile, maxAge time.Duration) int64 {
+	cutoff := time.Now().Add(-maxAge)
+	var freed int64
+	for _, log := range rotated {
+		if log.ModTime.After(cutoff) {
+			continue
+		}
+		if os.Remove(log.Path) == nil {
+			freed += log.Size
+		}
+	}
+	return freed
+}
+
+func (m *CleanupModule) previewLogs() []CleanupItem {
+	var items []CleanupItem
+	cutoff := time.Now().Add(-m.rotatedLogMaxAge())
+	for _, log := range AnalyzeLogs(systemLogDir, m.oversizedLogSize(), 0).Rotated {
+		if log.ModTime.Before(cutoff) {
+			items = append(items, CleanupItem{Category: "Rotated logs", Path: log.Path, Size: log.Size})
+		}
+	}
+	return items
+}
+
+func (m *CleanupModule) cleanOldLogs(report func(message string)) (int64, error) {
+	var totalSize int64
+
+	logs := AnalyzeLogs(systemLogDir, m.oversizedLogSize(), logSampleInterval)
+	for _, log := range logs.Runaway {
+		writers := "unknown process"
+		if len(log.Writers) > 0 {
+			writers = strings.Join(log.Writers, ", ")
+		}
+		report(fmt.Sprintf("⚠️ %s grows by %d KB/s, written by %s", log.Path, int64(log.Rate)/1024, writers))
+	}
+	for _, log := range logs.Oversized {
+		report(fmt.Sprintf("⚠️ %s is %d MB", log.Path, log.Size/1024/1024))
+	}
+
+	totalSize += m.vacuumJournal()
+
+	if m.JournaldMaxUse > 0 {
+		if err := writeJournaldLimit(journaldDropIn, m.JournaldMaxUse); err != nil {
+			report(fmt.Sprintf("Failed to set journald limit: %v", err))
+		} else {
+			report(fmt.Sprintf("Journal size limited to %d MB", m.JournaldMaxUse/1024/1024))
+		}
+	}
+
+	// Без прав root удалить файлы из /var/log не получится, считаем по факту
+	totalSize += removeRotatedLogs(logs.Rotated, m.rotatedLogMaxAge())
+	m.diskScanner().Invalidate(systemLogDir)
+
+	return totalSize, nil
+}
+
+// cleanUserLogs очищает старые логи в домашней папке пользователя
+func (m *CleanupModule) cleanUserLogs(homeDir string) (int64, error) {
+	return m.removeMeasured(filepath.Join(homeDir, ".local/share/logs"), false), nil
+}
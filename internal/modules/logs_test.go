@@ -0,0 +1,138 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatedLogRe(t *testing.T) {
+	tests := map[string]bool{
+		"syslog":               false,
+		"auth.log":             false,
+		"syslog.1":             true,
+		"syslog.2.gz":          true,
+		"kern.log.xz":          true,
+		"auth.log-20240101":    true,
+		"dpkg.log-20240101.gz": true,
+		"Xorg.0.log":           false,
+	}
+	for name, rotated := range tests {
+		if got := rotatedLogRe.MatchString(name); got != rotated {
+			t.Errorf("rotatedLogRe.MatchString(%q) = %v, want %v", name, got, rotated)
+		}
+	}
+}
+
+func TestAnalyzeLogs(t *testing.T) {
+	logDir, err := os.MkdirTemp("", "ububu_logs_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(logDir)
+
+	writeFile(t, filepath.Join(logDir, "syslog"), make([]byte, 256*1024))
+	writeFile(t, filepath.Join(logDir, "auth.log"), make([]byte, 4096))
+	writeFile(t, filepath.Join(logDir, "syslog.1"), make([]byte, 8192))
+	writeFile(t, filepath.Join(logDir, "apache2", "access.log.2.gz"), make([]byte, 4096))
+	writeFile(t, filepath.Join(logDir, "journal", "system.journal"), make([]byte, 512*1024))
+
+	// Лог, в который во время замера активно пишут
+	runaway, err := os.Create(filepath.Join(logDir, "runaway.log"))
+	if err != nil {
+		t.Fatalf("Failed to create log: %v", err)
+	}
+	defer runaway.Close()
+
+	stop := make(chan struct{})
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		chunk := make([]byte, 64*1024)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				runaway.Write(chunk)
+				time.Sleep(5 * time.Millisecond)
+			}
+		}
+	}()
+
+	report := AnalyzeLogs(logDir, 100*1024, 300*time.Millisecond)
+	close(stop)
+	writer.Wait()
+
+	if len(report.Rotated) != 2 {
+		t.Errorf("Rotated = %+v, want syslog.1 and access.log.2.gz", report.Rotated)
+	}
+	for _, log := range append(report.Rotated, report.Oversized...) {
+		if strings.Contains(log.Path, "journal") {
+			t.Errorf("Journal files should be skipped: %s", log.Path)
+		}
+	}
+	if len(report.Oversized) == 0 || report.Oversized[0].Path != filepath.Join(logDir, "syslog") {
+		t.Errorf("Oversized = %+v, want syslog", report.Oversized)
+	}
+
+	if len(report.Runaway) != 1 || report.Runaway[0].Path != runaway.Name() {
+		t.Fatalf("Runaway = %+v, want runaway.log", report.Runaway)
+	}
+	// Файл открыт самим тестом
+	if len(report.Runaway[0].Writers) == 0 {
+		t.Error("Writers of the runaway log should include the test process")
+	}
+}
+
+func TestRemoveRotatedLogs(t *testing.T) {
+	logDir, err := os.MkdirTemp("", "ububu_logs_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(logDir)
+
+	oldLog := filepath.Join(logDir, "syslog.3.gz")
+	newLog := filepath.Join(logDir, "syslog.1")
+	writeFile(t, oldLog, make([]byte, 4096))
+	writeFile(t, newLog, make([]byte, 4096))
+	expected := allocatedSize(t, oldLog)
+	past := time.Now().Add(-10 * 24 * time.Hour)
+	os.Chtimes(oldLog, past, past)
+
+	report := AnalyzeLogs(logDir, defaultOversizedLogSize, 0)
+	freed := removeRotatedLogs(report.Rotated, 7*24*time.Hour)
+	if freed != expected {
+		t.Errorf("removeRotatedLogs() = %d, want %d", freed, expected)
+	}
+	if _, err := os.Stat(oldLog); !os.IsNotExist(err) {
+		t.Error("Old rotated log should have been deleted")
+	}
+	if _, err := os.Stat(newLog); err != nil {
+		t.Error("Recent rotated log should have been kept")
+	}
+}
+
+func TestJournaldDropIn(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_logs_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "journald.conf.d", "ububu.conf")
+	// Перезапуск journald в тестовом окружении может не сработать
+	writeJournaldLimit(path, 500*1024*1024)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Drop-in was not written: %v", err)
+	}
+	if !strings.Contains(string(content), "[Journal]\nSystemMaxUse=500M\n") {
+		t.Errorf("Unexpected drop-in content:\n%s", content)
+	}
+}
@@ -0,0 +1,364 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	meminfoPath    = "/proc/meminfo"
+	swapsPath      = "/proc/swaps"
+	vmstatPath     = "/proc/vmstat"
+	zswapParamsDir = "/sys/module/zswap/parameters"
+	thpDir         = "/sys/kernel/mm/transparent_hugepage"
+	sysClassBlock  = "/sys/class/block"
+	sysDevBlock    = "/sys/dev/block"
+	// lowMemory - на машинах с меньшим объемом памяти без swap легко упереться в OOM
+	lowMemory = 8 * 1024 * 1024 * 1024
+	// swapFullPercent - заполненность swap, при которой системе уже тесно
+	swapFullPercent = 80
+	// swapWearWarning и swapWearCritical - износ SSD, при котором swap на нем
+	// лучше перенести в zram
+	swapWearWarning  = 70
+	swapWearCritical = 90
+)
+
+// SwapDevice - раздел или файл подкачки из /proc/swaps
+type SwapDevice struct {
+	Path     string
+	Type     string
+	Size     int64
+	Used     int64
+	Priority int
+	// Disk - физический диск, на котором лежит swap, пусто если неизвестен
+	Disk       string
+	Rotational bool
+	// WearUsed - износ SSD в процентах из SMART, -1 если неизвестен
+	WearUsed int
+}
+
+// Zram возвращает true для сжатого swap в памяти
+func (s SwapDevice) Zram() bool {
+	return strings.HasPrefix(filepath.Base(s.Path), "zram")
+}
+
+// ZramDevice - сжатое блочное устройство в памяти
+type ZramDevice struct {
+	Name       string
+	Algorithm  string
+	DiskSize   int64
+	OrigData   int64
+	Compressed int64
+	MemUsed    int64
+}
+
+// MemoryStatus - память, подкачка и связанные с ними настройки ядра
+type MemoryStatus struct {
+	Total     int64
+	Available int64
+	SwapTotal int64
+	SwapFree  int64
+
+	HugePagesTotal int64
+	HugePagesFree  int64
+	HugePageSize   int64
+
+	Swaps []SwapDevice
+	Zram  []ZramDevice
+
+	ZswapEnabled    bool
+	ZswapCompressor string
+	THP             string
+	THPDefrag       string
+	// OOMKills - сколько процессов убил OOM killer с момента загрузки
+	OOMKills int
+}
+
+// UsedPercent возвращает долю занятой памяти
+func (s MemoryStatus) UsedPercent() int64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return (s.Total - s.Available) * 100 / s.Total
+}
+
+// SwapUsedPercent возвращает долю занятого swap
+func (s MemoryStatus) SwapUsedPercent() int64 {
+	if s.SwapTotal == 0 {
+		return 0
+	}
+	return (s.SwapTotal - s.SwapFree) * 100 / s.SwapTotal
+}
+
+// Findings возвращает проблемы конфигурации памяти и подкачки
+func (s MemoryStatus) Findings() []Finding {
+	var findings []Finding
+	add := func(severity Severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.SwapTotal == 0 && s.Total > 0 && s.Total < lowMemory {
+		add(SeverityWarning, "no swap with only %d MB RAM: add zram or a swap file", s.Total/1024/1024)
+	}
+	if used := s.SwapUsedPercent(); used >= swapFullPercent {
+		severity := SeverityWarning
+		if s.Available*10 < s.Total {
+			severity = SeverityCritical
+		}
+		add(severity, "swap %d%% full", used)
+	}
+
+	hasZram := false
+	for _, swap := range s.Swaps {
+		if swap.Zram() {
+			hasZram = true
+			continue
+		}
+		switch {
+		case swap.WearUsed >= swapWearCritical:
+			add(SeverityCritical, "swap %s is on %s with %d%% of write endurance used: move swap to zram", swap.Path, swap.Disk, swap.WearUsed)
+		case swap.WearUsed >= swapWearWarning:
+			add(SeverityWarning, "swap %s is on %s with %d%% of write endurance used: consider zram", swap.Path, swap.Disk, swap.WearUsed)
+		}
+	}
+	if hasZram && s.ZswapEnabled {
+		add(SeverityWarning, "zswap and zram swap are both enabled: pages are compressed twice, disable zswap")
+	}
+
+	if s.HugePagesTotal > 0 && s.HugePagesFree == s.HugePagesTotal {
+		add(SeverityWarning, "%d hugepages (%d MB) reserved but unused", s.HugePagesTotal, s.HugePagesTotal*s.HugePageSize/1024/1024)
+	}
+	if s.THP == "always" {
+		add(SeverityInfo, "transparent hugepages set to always: madvise avoids compaction stalls")
+	}
+	if s.OOMKills > 0 {
+		add(SeverityWarning, "%d processes killed by the OOM killer since boot", s.OOMKills)
+	}
+
+	return findings
+}
+
+// parseMeminfo читает /proc/meminfo. Значения в kB переводятся в байты,
+// счетчики без единиц (HugePages_Total) остаются как есть
+func parseMeminfo(path string) (map[string]int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 2 && fields[2] == "kB" {
+			value *= 1024
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = value
+	}
+	return values, scanner.Err()
+}
+
+// parseSwaps читает /proc/swaps: размеры в kB
+func parseSwaps(path string) []SwapDevice {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var swaps []SwapDevice
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] == "Filename" {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[2], 10, 64)
+		used, _ := strconv.ParseInt(fields[3], 10, 64)
+		priority, _ := strconv.Atoi(fields[4])
+		swaps = append(swaps, SwapDevice{
+			Path:     strings.ReplaceAll(fields[0], `\040`, " "),
+			Type:     fields[1],
+			Size:     size * 1024,
+			Used:     used * 1024,
+			Priority: priority,
+			WearUsed: -1,
+		})
+	}
+	return swaps
+}
+
+// selectedChoice возвращает выбранный вариант из строки вида "always [madvise] never"
+func selectedChoice(value string) string {
+	for _, choice := range strings.Fields(value) {
+		if strings.HasPrefix(choice, "[") && strings.HasSuffix(choice, "]") {
+			return strings.Trim(choice, "[]")
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// readZram находит настроенные устройства zram
+func readZram(sysRoot string) []ZramDevice {
+	entries, err := os.ReadDir(sysRoot)
+	if err != nil {
+		return nil
+	}
+
+	var devices []ZramDevice
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "zram") {
+			continue
+		}
+		dir := filepath.Join(sysRoot, entry.Name())
+		size, ok := readSysValue(filepath.Join(dir, "disksize"))
+		if !ok || size == 0 {
+			continue
+		}
+		device := ZramDevice{
+			Name:      entry.Name(),
+			Algorithm: selectedChoice(readSysString(filepath.Join(dir, "comp_algorithm"))),
+			DiskSize:  int64(size),
+		}
+		// mm_stat: orig_data_size compr_data_size mem_used_total ...
+		stats := strings.Fields(readSysString(filepath.Join(dir, "mm_stat")))
+		if len(stats) >= 3 {
+			device.OrigData, _ = strconv.ParseInt(stats[0], 10, 64)
+			device.Compressed, _ = strconv.ParseInt(stats[1], 10, 64)
+			device.MemUsed, _ = strconv.ParseInt(stats[2], 10, 64)
+		}
+		devices = append(devices, device)
+	}
+	return devices
+}
+
+// readVmstat возвращает счетчик из /proc/vmstat
+func readVmstat(path, key string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if name, value, ok := strings.Cut(scanner.Text(), " "); ok && name == key {
+			count, _ := strconv.Atoi(value)
+			return count
+		}
+	}
+	return 0
+}
+
+// diskForBlock поднимается от блочного устройства к физическому диску:
+// через slaves для LVM и dm-crypt и от раздела к диску
+func diskForBlock(classRoot, name string) string {
+	for depth := 0; depth < 4; depth++ {
+		slaves, err := os.ReadDir(filepath.Join(classRoot, name, "slaves"))
+		if err != nil || len(slaves) == 0 {
+			break
+		}
+		name = slaves[0].Name()
+	}
+	if _, err := os.Stat(filepath.Join(classRoot, name, "partition")); err == nil {
+		if target, err := filepath.EvalSymlinks(filepath.Join(classRoot, name)); err == nil {
+			name = filepath.Base(filepath.Dir(target))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(classRoot, name)); err != nil {
+		return ""
+	}
+	return name
+}
+
+// swapDisk определяет диск, на котором лежит swap. Для файла подкачки
+// диск ищется по устройству файловой системы
+func swapDisk(swap SwapDevice, classRoot, devRoot string) string {
+	if swap.Type == "partition" {
+		return diskForBlock(classRoot, filepath.Base(swap.Path))
+	}
+
+	var stat syscall.Stat_t
+	if err := syscall.Stat(swap.Path, &stat); err != nil {
+		return ""
+	}
+	dev := uint64(stat.Dev)
+	major := (dev>>8)&0xfff | (dev>>32)&^0xfff
+	minor := dev&0xff | (dev>>12)&^0xff
+	target, err := os.Readlink(filepath.Join(devRoot, fmt.Sprintf("%d:%d", major, minor)))
+	if err != nil {
+		return ""
+	}
+	return diskForBlock(classRoot, filepath.Base(target))
+}
+
+// ReadMemoryStatus собирает сведения о памяти, подкачке, zram, zswap,
+// hugepages и OOM. Износ SSD под swap берется из SMART, если есть smartctl
+func ReadMemoryStatus() (MemoryStatus, error) {
+	meminfo, err := parseMeminfo(meminfoPath)
+	if err != nil {
+		return MemoryStatus{}, err
+	}
+	if meminfo["MemTotal"] == 0 {
+		return MemoryStatus{}, fmt.Errorf("could not parse memory info")
+	}
+
+	status := MemoryStatus{
+		Total:          meminfo["MemTotal"],
+		Available:      meminfo["MemAvailable"],
+		SwapTotal:      meminfo["SwapTotal"],
+		SwapFree:       meminfo["SwapFree"],
+		HugePagesTotal: meminfo["HugePages_Total"],
+		HugePagesFree:  meminfo["HugePages_Free"],
+		HugePageSize:   meminfo["Hugepagesize"],
+		Swaps:          parseSwaps(swapsPath),
+		Zram:           readZram(sysBlockDir),
+		ZswapEnabled:   readSysString(filepath.Join(zswapParamsDir, "enabled")) == "Y",
+		THP:            selectedChoice(readSysString(filepath.Join(thpDir, "enabled"))),
+		THPDefrag:      selectedChoice(readSysString(filepath.Join(thpDir, "defrag"))),
+		OOMKills:       readVmstat(vmstatPath, "oom_kill"),
+	}
+	if status.ZswapEnabled {
+		status.ZswapCompressor = readSysString(filepath.Join(zswapParamsDir, "compressor"))
+	}
+
+	_, smartErr := exec.LookPath("smartctl")
+	wear := make(map[string]int)
+	for i := range status.Swaps {
+		swap := &status.Swaps[i]
+		if swap.Zram() {
+			continue
+		}
+		swap.Disk = swapDisk(*swap, sysClassBlock, sysDevBlock)
+		if swap.Disk == "" {
+			continue
+		}
+		swap.Rotational = readSysString(filepath.Join(sysBlockDir, swap.Disk, "queue/rotational")) == "1"
+		if swap.Rotational || smartErr != nil {
+			continue
+		}
+		if _, ok := wear[swap.Disk]; !ok {
+			wear[swap.Disk] = -1
+			if drive, err := readSmart("/dev/" + swap.Disk); err == nil {
+				wear[swap.Disk] = drive.WearUsed
+			}
+		}
+		swap.WearUsed = wear[swap.Disk]
+	}
+
+	return status, nil
+}
@@ -0,0 +1,181 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meminfo")
+	content := "MemTotal:        4000000 kB\nMemAvailable:    1000000 kB\nSwapTotal:       2097148 kB\n" +
+		"HugePages_Total:      16\nHugepagesize:       2048 kB\n"
+	os.WriteFile(path, []byte(content), 0644)
+
+	values, err := parseMeminfo(path)
+	if err != nil {
+		t.Fatalf("parseMeminfo() error: %v", err)
+	}
+	if values["MemTotal"] != 4000000*1024 || values["SwapTotal"] != 2097148*1024 {
+		t.Errorf("kB values should be converted to bytes, got %v", values)
+	}
+	if values["HugePages_Total"] != 16 || values["Hugepagesize"] != 2048*1024 {
+		t.Errorf("hugepage values = %v", values)
+	}
+}
+
+func TestParseSwaps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swaps")
+	content := "Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n" +
+		"/dev/nvme0n1p3                          partition\t8388604\t\t1048576\t\t-2\n" +
+		"/swap\\040file                           file\t\t2097148\t\t0\t\t-3\n" +
+		"/dev/zram0                              partition\t4194300\t\t512\t\t100\n"
+	os.WriteFile(path, []byte(content), 0644)
+
+	swaps := parseSwaps(path)
+	if len(swaps) != 3 {
+		t.Fatalf("parseSwaps() returned %d devices, want 3", len(swaps))
+	}
+	if swaps[0].Path != "/dev/nvme0n1p3" || swaps[0].Size != 8388604*1024 || swaps[0].Used != 1048576*1024 || swaps[0].Priority != -2 {
+		t.Errorf("swaps[0] = %+v", swaps[0])
+	}
+	if swaps[1].Path != "/swap file" || swaps[1].Type != "file" {
+		t.Errorf("escaped path = %+v", swaps[1])
+	}
+	if swaps[0].Zram() || !swaps[2].Zram() {
+		t.Error("only /dev/zram0 should be detected as zram")
+	}
+	if swaps[0].WearUsed != -1 {
+		t.Errorf("wear should be unknown until SMART is read, got %d", swaps[0].WearUsed)
+	}
+}
+
+func TestSelectedChoice(t *testing.T) {
+	tests := map[string]string{
+		"always [madvise] never":       "madvise",
+		"lzo lzo-rle lz4 [zstd]":       "zstd",
+		"[always] defer madvise never": "always",
+		"zstd":                         "zstd",
+	}
+	for value, want := range tests {
+		if got := selectedChoice(value); got != want {
+			t.Errorf("selectedChoice(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestReadZram(t *testing.T) {
+	root := t.TempDir()
+	writeSysFiles(t, filepath.Join(root, "zram0"), map[string]string{
+		"disksize":       "4294967296",
+		"comp_algorithm": "lzo lz4 [zstd]",
+		"mm_stat":        "1073741824 268435456 285212672 0 285212672 10 0 0 0",
+	})
+	// Ненастроенное устройство не учитывается
+	writeSysFiles(t, filepath.Join(root, "zram1"), map[string]string{"disksize": "0"})
+	writeSysFiles(t, filepath.Join(root, "sda"), map[string]string{"size": "100"})
+
+	devices := readZram(root)
+	if len(devices) != 1 {
+		t.Fatalf("readZram() returned %d devices, want 1", len(devices))
+	}
+	want := ZramDevice{Name: "zram0", Algorithm: "zstd", DiskSize: 4 << 30, OrigData: 1 << 30, Compressed: 256 << 20, MemUsed: 272 << 20}
+	if devices[0] != want {
+		t.Errorf("readZram() = %+v, want %+v", devices[0], want)
+	}
+}
+
+func TestReadVmstat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vmstat")
+	os.WriteFile(path, []byte("nr_free_pages 12345\noom_kill 3\npswpin 0\n"), 0644)
+
+	if got := readVmstat(path, "oom_kill"); got != 3 {
+		t.Errorf("readVmstat(oom_kill) = %d, want 3", got)
+	}
+	if got := readVmstat(path, "missing"); got != 0 {
+		t.Errorf("readVmstat(missing) = %d, want 0", got)
+	}
+}
+
+func TestDiskForBlock(t *testing.T) {
+	root := t.TempDir()
+	devices := filepath.Join(root, "devices")
+	class := filepath.Join(root, "class")
+	os.MkdirAll(class, 0755)
+
+	// nvme0n1 с разделом p3, на котором лежит dm-0 (LUKS)
+	writeSysFiles(t, filepath.Join(devices, "nvme0n1"), map[string]string{"size": "1000"})
+	writeSysFiles(t, filepath.Join(devices, "nvme0n1/nvme0n1p3"), map[string]string{"partition": "3"})
+	os.MkdirAll(filepath.Join(devices, "dm-0/slaves"), 0755)
+	os.Symlink(filepath.Join(devices, "nvme0n1/nvme0n1p3"), filepath.Join(devices, "dm-0/slaves/nvme0n1p3"))
+	for name, target := range map[string]string{
+		"nvme0n1":   "nvme0n1",
+		"nvme0n1p3": "nvme0n1/nvme0n1p3",
+		"dm-0":      "dm-0",
+	} {
+		os.Symlink(filepath.Join(devices, target), filepath.Join(class, name))
+	}
+
+	for name, want := range map[string]string{
+		"nvme0n1p3": "nvme0n1",
+		"dm-0":      "nvme0n1",
+		"nvme0n1":   "nvme0n1",
+		"sdz1":      "",
+	} {
+		if got := diskForBlock(class, name); got != want {
+			t.Errorf("diskForBlock(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestMemoryStatus_Findings(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+
+	messages := func(status MemoryStatus) string {
+		var all []string
+		for _, finding := range status.Findings() {
+			all = append(all, finding.String())
+		}
+		return strings.Join(all, "\n")
+	}
+
+	healthy := MemoryStatus{Total: 16 * gb, Available: 8 * gb, SwapTotal: 4 * gb, SwapFree: 4 * gb, THP: "madvise"}
+	if findings := healthy.Findings(); len(findings) != 0 {
+		t.Errorf("healthy config produced findings: %v", findings)
+	}
+
+	noSwap := MemoryStatus{Total: 4 * gb, Available: 2 * gb}
+	if got := messages(noSwap); !strings.Contains(got, "no swap") {
+		t.Errorf("low RAM without swap should be flagged, got %q", got)
+	}
+
+	worn := MemoryStatus{
+		Total: 16 * gb, Available: gb, SwapTotal: 8 * gb, SwapFree: gb,
+		Swaps: []SwapDevice{
+			{Path: "/dev/nvme0n1p3", Type: "partition", Disk: "nvme0n1", WearUsed: 93},
+			{Path: "/dev/zram0", Type: "partition", WearUsed: -1},
+		},
+		ZswapEnabled:   true,
+		HugePagesTotal: 512, HugePagesFree: 512, HugePageSize: 2 * 1024 * 1024,
+		THP:      "always",
+		OOMKills: 2,
+	}
+	findings := worn.Findings()
+	if worstSeverity(findings) != SeverityCritical {
+		t.Errorf("worn SSD swap should be critical, got %v", findings)
+	}
+	got := messages(worn)
+	for _, want := range []string{
+		"swap 87% full",
+		"/dev/nvme0n1p3 is on nvme0n1 with 93%",
+		"zswap and zram",
+		"512 hugepages (1024 MB) reserved but unused",
+		"transparent hugepages set to always",
+		"2 processes killed by the OOM killer",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Findings() missing %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+)
+
+const (
+	mountinfoPath = "/proc/self/mountinfo"
+	fstabPath     = "/etc/fstab"
+)
+
+// volatileFilesystems живут в памяти, их заполненность не говорит о диске
+var volatileFilesystems = map[string]bool{"tmpfs": true, "ramfs": true}
+
+// networkFilesystems обращаются к серверу, и statfs на недоступном сервере
+// зависает. Сюда же относятся все файловые системы FUSE, кроме fuseblk
+var networkFilesystems = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "smbfs": true,
+	"ceph": true, "glusterfs": true, "9p": true, "afs": true, "fuse": true,
+}
+
+func isNetworkFilesystem(fsType string) bool {
+	return networkFilesystems[fsType] || strings.HasPrefix(fsType, "fuse.")
+}
+
+// writableFilesystems - дисковые файловые системы, которые ядро переводит
+// в режим только для чтения при ошибках
+var writableFilesystems = map[string]bool{
+	"ext2": true, "ext3": true, "ext4": true, "xfs": true, "btrfs": true,
+	"f2fs": true, "jfs": true, "reiserfs": true, "vfat": true, "exfat": true, "ntfs3": true,
+}
+
+// MountInfo - запись из /proc/self/mountinfo
+type MountInfo struct {
+	// Device - номер устройства major:minor
+	Device     string
+	MountPoint string
+	FSType     string
+	Source     string
+	// ReadOnly - точка монтирования или суперблок в режиме только для чтения
+	ReadOnly bool
+}
+
+// FilesystemHealth - заполненность и состояние одной файловой системы
+type FilesystemHealth struct {
+	Mount       MountInfo
+	Total       uint64
+	Available   uint64
+	UsedPercent int
+	// Inodes - всего инодов; 0, если файловая система их не ограничивает (btrfs)
+	Inodes       uint64
+	InodePercent int
+	// RemountedRO - файловая система должна быть доступна для записи,
+	// но сейчас смонтирована только для чтения
+	RemountedRO bool
+}
+
+// parseMountinfo разбирает /proc/self/mountinfo:
+// id parent major:minor root mountpoint options [optional...] - fstype source superoptions
+func parseMountinfo(r io.Reader) []MountInfo {
+	var mounts []MountInfo
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		separator := -1
+		for i, field := range fields {
+			if field == "-" {
+				separator = i
+				break
+			}
+		}
+		if separator < 6 || len(fields) < separator+4 {
+			continue
+		}
+
+		mounts = append(mounts, MountInfo{
+			Device:     fields[2],
+			MountPoint: unescapeMountPath(fields[4]),
+			FSType:     fields[separator+1],
+			Source:     unescapeMountPath(fields[separator+2]),
+			ReadOnly:   hasMountOption(fields[5], "ro") || hasMountOption(fields[separator+3], "ro"),
+		})
+	}
+
+	return mounts
+}
+
+// diskMounts оставляет файловые системы с данными на локальном диске. Одна
+// файловая система, смонтированная несколько раз, учитывается по первой точке монтирования
+func diskMounts(mounts []MountInfo) []MountInfo {
+	var result []MountInfo
+	seen := make(map[string]bool)
+	for _, mount := range mounts {
+		if pseudoFilesystems[mount.FSType] || volatileFilesystems[mount.FSType] ||
+			isNetworkFilesystem(mount.FSType) || seen[mount.Device] {
+			continue
+		}
+		seen[mount.Device] = true
+		result = append(result, mount)
+	}
+	return result
+}
+
+func hasMountOption(options, option string) bool {
+	for _, value := range strings.Split(options, ",") {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFstab возвращает для каждой точки монтирования из fstab, должна ли
+// она монтироваться только для чтения
+func parseFstab(r io.Reader) map[string]bool {
+	readOnly := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		readOnly[unescapeMountPath(fields[1])] = hasMountOption(fields[3], "ro")
+	}
+
+	return readOnly
+}
+
+// remountedReadOnly определяет, что файловую систему перевели в режим только
+// для чтения, хотя по fstab она должна быть доступна для записи. Если в fstab
+// ее нет, подозрительным считается только режим ro у дисковой файловой системы
+func remountedReadOnly(mount MountInfo, fstab map[string]bool) bool {
+	if !mount.ReadOnly {
+		return false
+	}
+	if wantRO, listed := fstab[mount.MountPoint]; listed {
+		return !wantRO
+	}
+	return writableFilesystems[mount.FSType]
+}
+
+// statFilesystem заполняет размеры и иноды файловой системы через statfs
+func statFilesystem(mount MountInfo) (FilesystemHealth, error) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(mount.MountPoint, &fs); err != nil {
+		return FilesystemHealth{}, err
+	}
+
+	health := FilesystemHealth{
+		Mount:     mount,
+		Total:     fs.Blocks * uint64(fs.Bsize),
+		Available: fs.Bavail * uint64(fs.Bsize),
+		Inodes:    fs.Files,
+	}
+	// Как в df: занятое относительно доступного обычному пользователю
+	used := fs.Blocks - fs.Bfree
+	if usable := used + fs.Bavail; usable > 0 {
+		health.UsedPercent = int((used*100 + usable - 1) / usable)
+	}
+	if fs.Files > 0 {
+		health.InodePercent = int((fs.Files - fs.Ffree) * 100 / fs.Files)
+	}
+	return health, nil
+}
+
+// CheckFilesystems возвращает состояние всех смонтированных дисковых файловых систем
+func CheckFilesystems() ([]FilesystemHealth, error) {
+	file, err := os.Open(mountinfoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	mounts := diskMounts(parseMountinfo(file))
+
+	fstab := map[string]bool{}
+	if fstabFile, err := os.Open(fstabPath); err == nil {
+		fstab = parseFstab(fstabFile)
+		fstabFile.Close()
+	}
+
+	var result []FilesystemHealth
+	for _, mount := range mounts {
+		health, err := statFilesystem(mount)
+		if err != nil || health.Total == 0 {
+			continue // Например, отключенный сетевой ресурс
+		}
+		health.RemountedRO = remountedReadOnly(mount, fstab)
+		result = append(result, health)
+	}
+	return result, nil
+}
+
+// Status возвращает оценку файловой системы: заполненность блоков и инодов
+// и переход в режим только для чтения
+func (h FilesystemHealth) Status() string {
+	switch {
+	case h.RemountedRO:
+		return "⚠️ WARNING: remounted read-only"
+	case h.UsedPercent > 90:
+		return "⚠️ WARNING: Disk usage is high"
+	case h.InodePercent > 90:
+		return "⚠️ WARNING: Inodes are nearly exhausted"
+	case h.UsedPercent > 80:
+		return "⚠️ CAUTION: Disk usage is moderate"
+	case h.InodePercent > 80:
+		return "⚠️ CAUTION: Inode usage is moderate"
+	default:
+		return "✅ GOOD: Disk usage is normal"
+	}
+}
+
+// String описывает файловую систему одной строкой для отчета
+func (h FilesystemHealth) String() string {
+	line := fmt.Sprintf("%s • %s (%s) %d%% used, %d MB free",
+		h.Status(), h.Mount.MountPoint, h.Mount.FSType, h.UsedPercent, h.Available/1024/1024)
+	if h.Inodes > 0 {
+		line += fmt.Sprintf(", inodes %d%% used", h.InodePercent)
+	}
+	return line
+}
@@ -0,0 +1,90 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMountinfo = `22 1 8:2 / / rw,relatime shared:1 - ext4 /dev/sda2 rw,errors=remount-ro
+23 22 0:21 / /proc rw,nosuid,nodev,noexec,relatime shared:12 - proc proc rw
+24 22 0:5 / /dev/shm rw,nosuid,nodev shared:3 - tmpfs tmpfs rw
+25 22 8:3 / /mnt/data ro,relatime shared:30 - ext4 /dev/sda3 ro
+26 22 8:2 /srv /srv rw,relatime shared:1 - ext4 /dev/sda2 rw,errors=remount-ro
+27 22 7:1 / /snap/core/1 ro,nodev,relatime shared:40 - squashfs /dev/loop1 ro
+28 22 8:4 / /media/my\040disk rw,relatime shared:50 - vfat /dev/sdb1 rw
+29 22 0:60 / /mnt/nas rw,relatime shared:60 - nfs4 nas:/export rw
+30 22 0:61 / /home/user/remote rw,nosuid,nodev shared:61 - fuse.sshfs user@host:/ rw
+31 22 8:17 / /media/windows rw,relatime shared:62 - fuseblk /dev/sdc1 rw
+`
+
+func TestParseMountinfo(t *testing.T) {
+	mounts := parseMountinfo(strings.NewReader(testMountinfo))
+	if len(mounts) != 10 {
+		t.Fatalf("parseMountinfo() returned %d mounts, want 10", len(mounts))
+	}
+
+	root := mounts[0]
+	if root.MountPoint != "/" || root.FSType != "ext4" || root.Source != "/dev/sda2" || root.Device != "8:2" || root.ReadOnly {
+		t.Errorf("Unexpected root mount: %+v", root)
+	}
+	if !mounts[3].ReadOnly {
+		t.Error("/mnt/data should be read-only")
+	}
+	if mounts[6].MountPoint != "/media/my disk" {
+		t.Errorf("Escaped mount point = %q, want %q", mounts[6].MountPoint, "/media/my disk")
+	}
+
+	var points []string
+	for _, mount := range diskMounts(mounts) {
+		points = append(points, mount.MountPoint)
+	}
+	if got := strings.Join(points, " "); got != "/ /mnt/data /media/my disk /media/windows" {
+		t.Errorf("diskMounts() = %q, want local filesystems without bind mounts", got)
+	}
+}
+
+func TestRemountedReadOnly(t *testing.T) {
+	fstab := parseFstab(strings.NewReader(`# <file system> <mount point> <type> <options> <dump> <pass>
+UUID=1234 / ext4 errors=remount-ro 0 1
+UUID=5678 /mnt/archive ext4 ro,noatime 0 2
+`))
+
+	tests := []struct {
+		mount MountInfo
+		want  bool
+	}{
+		{MountInfo{MountPoint: "/", FSType: "ext4", ReadOnly: true}, true},
+		{MountInfo{MountPoint: "/", FSType: "ext4"}, false},
+		{MountInfo{MountPoint: "/mnt/archive", FSType: "ext4", ReadOnly: true}, false},
+		{MountInfo{MountPoint: "/mnt/usb", FSType: "vfat", ReadOnly: true}, true},
+		{MountInfo{MountPoint: "/mnt/cdrom", FSType: "iso9660", ReadOnly: true}, false},
+	}
+	for _, tt := range tests {
+		if got := remountedReadOnly(tt.mount, fstab); got != tt.want {
+			t.Errorf("remountedReadOnly(%+v) = %v, want %v", tt.mount, got, tt.want)
+		}
+	}
+}
+
+func TestFilesystemHealth_Status(t *testing.T) {
+	tests := []struct {
+		health FilesystemHealth
+		want   string
+	}{
+		{FilesystemHealth{UsedPercent: 50, InodePercent: 10}, "GOOD"},
+		{FilesystemHealth{UsedPercent: 85}, "CAUTION"},
+		{FilesystemHealth{UsedPercent: 95}, "WARNING"},
+		{FilesystemHealth{UsedPercent: 20, InodePercent: 97}, "Inodes are nearly exhausted"},
+		{FilesystemHealth{UsedPercent: 20, RemountedRO: true}, "read-only"},
+	}
+	for _, tt := range tests {
+		if got := tt.health.Status(); !strings.Contains(got, tt.want) {
+			t.Errorf("Status() for %+v = %q, want %q", tt.health, got, tt.want)
+		}
+	}
+
+	line := FilesystemHealth{Mount: MountInfo{MountPoint: "/home", FSType: "btrfs"}, UsedPercent: 40}.String()
+	if !strings.Contains(line, "/home (btrfs) 40% used") || strings.Contains(line, "inodes") {
+		t.Errorf("String() = %q, want no inode usage for btrfs", line)
+	}
+}
@@ -0,0 +1,316 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	netDevPath      = "/proc/net/dev"
+	netRoutePath    = "/proc/net/route"
+	netIPv6Route    = "/proc/net/ipv6_route"
+	sysClassNet     = "/sys/class/net"
+	resolvConfPath  = "/etc/resolv.conf"
+	resolvedStubDNS = "127.0.0.53"
+	// netErrorRatio и netDropRatio - доля ошибочных и отброшенных пакетов,
+	// при которой интерфейс считается проблемным
+	netErrorRatio = 0.01
+	netDropRatio  = 0.05
+)
+
+// InterfaceStats - счетчики сетевого интерфейса из /proc/net/dev с момента загрузки
+type InterfaceStats struct {
+	Name      string
+	State     string
+	RxBytes   int64
+	RxPackets int64
+	RxErrors  int64
+	RxDropped int64
+	TxBytes   int64
+	TxPackets int64
+	TxErrors  int64
+	TxDropped int64
+}
+
+// Findings возвращает проблемы интерфейса по счетчикам ошибок и отбрасываний
+func (s InterfaceStats) Findings() []Finding {
+	var findings []Finding
+	packets := s.RxPackets + s.TxPackets
+	if packets == 0 {
+		return nil
+	}
+
+	if errors := s.RxErrors + s.TxErrors; errors > 0 {
+		severity := SeverityInfo
+		if float64(errors)/float64(packets) >= netErrorRatio {
+			severity = SeverityWarning
+		}
+		findings = append(findings, Finding{Severity: severity, Message: fmt.Sprintf(
+			"%s: %d packet errors (rx %d, tx %d), check the cable, Wi-Fi signal or driver", s.Name, errors, s.RxErrors, s.TxErrors)})
+	}
+	// Отбрасывания случаются и в норме (неизвестные протоколы), важна только доля
+	if dropped := s.RxDropped + s.TxDropped; float64(dropped)/float64(packets) >= netDropRatio {
+		findings = append(findings, Finding{Severity: SeverityWarning, Message: fmt.Sprintf(
+			"%s: %.1f%% of packets dropped", s.Name, float64(dropped)*100/float64(packets))})
+	}
+	return findings
+}
+
+// DefaultRoute - маршрут по умолчанию
+type DefaultRoute struct {
+	Interface string
+	Gateway   string
+	IPv6      bool
+}
+
+// ResolverConfig - настройки DNS из resolv.conf
+type ResolverConfig struct {
+	Nameservers []string
+	Search      []string
+	// Target - куда указывает resolv.conf, если это символическая ссылка
+	Target string
+}
+
+// Stub возвращает true, если запросы идут через локальную заглушку systemd-resolved
+func (c ResolverConfig) Stub() bool {
+	for _, server := range c.Nameservers {
+		if server == resolvedStubDNS {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkStatus - локальная диагностика сети без обращения к внешним сервисам
+type NetworkStatus struct {
+	Interfaces []InterfaceStats
+	Routes     []DefaultRoute
+	Resolver   ResolverConfig
+	// ResolvedActive - состояние службы systemd-resolved, пусто если неизвестно
+	ResolvedActive string
+	// TimeSync - NTP и NTPSynchronized из timedatectl, пусто если недоступно
+	TimeSync map[string]string
+	// NMState и NMConnectivity - состояние NetworkManager, пусто если его нет
+	NMState        string
+	NMConnectivity string
+}
+
+// Findings возвращает проблемы сети
+func (s NetworkStatus) Findings() []Finding {
+	var findings []Finding
+	add := func(severity Severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	up := 0
+	for _, iface := range s.Interfaces {
+		// Туннели и PPP часто сообщают unknown, хотя работают
+		if iface.State == "up" || iface.State == "unknown" {
+			up++
+		}
+		findings = append(findings, iface.Findings()...)
+	}
+	if up == 0 {
+		add(SeverityCritical, "no network interface is up")
+	}
+	if len(s.Routes) == 0 {
+		add(SeverityCritical, "no default route: only the local network is reachable")
+	}
+
+	switch {
+	case len(s.Resolver.Nameservers) == 0:
+		add(SeverityCritical, "no nameservers in %s: name resolution will fail", resolvConfPath)
+	case s.Resolver.Stub() && s.ResolvedActive != "" && s.ResolvedActive != "active":
+		add(SeverityCritical, "%s points to systemd-resolved, but the service is %s", resolvConfPath, s.ResolvedActive)
+	}
+
+	if s.TimeSync != nil {
+		switch {
+		case s.TimeSync["NTP"] == "no":
+			add(SeverityWarning, "automatic time sync is disabled (timedatectl set-ntp true)")
+		case s.TimeSync["NTPSynchronized"] == "no":
+			add(SeverityWarning, "system clock is not synchronized, TLS and logs may show wrong times")
+		}
+	}
+
+	switch s.NMConnectivity {
+	case "none":
+		add(SeverityCritical, "NetworkManager reports no connectivity (%s)", s.NMState)
+	case "portal":
+		add(SeverityWarning, "NetworkManager detected a captive portal, log in through a browser")
+	case "limited":
+		add(SeverityWarning, "NetworkManager reports limited connectivity: connected, but the internet is unreachable")
+	}
+	return findings
+}
+
+// parseNetDev разбирает /proc/net/dev. Петлевой интерфейс пропускается
+func parseNetDev(path, sysRoot string) []InterfaceStats {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var interfaces []InterfaceStats
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		name, counters, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		fields := strings.Fields(counters)
+		if name == "lo" || len(fields) < 12 {
+			continue
+		}
+		value := func(i int) int64 {
+			n, _ := strconv.ParseInt(fields[i], 10, 64)
+			return n
+		}
+		// Поля приема: bytes packets errs drop fifo frame compressed multicast,
+		// затем передачи: bytes packets errs drop ...
+		interfaces = append(interfaces, InterfaceStats{
+			Name:      name,
+			State:     readSysString(filepath.Join(sysRoot, name, "operstate")),
+			RxBytes:   value(0),
+			RxPackets: value(1),
+			RxErrors:  value(2),
+			RxDropped: value(3),
+			TxBytes:   value(8),
+			TxPackets: value(9),
+			TxErrors:  value(10),
+			TxDropped: value(11),
+		})
+	}
+	return interfaces
+}
+
+// hexIPv4 переводит адрес из /proc/net/route (little-endian hex) в точечную запись
+func hexIPv4(value string) string {
+	n, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+// parseDefaultRoutes находит маршруты по умолчанию в /proc/net/route
+// и /proc/net/ipv6_route
+func parseDefaultRoutes(ipv4Path, ipv6Path string) []DefaultRoute {
+	var routes []DefaultRoute
+
+	if file, err := os.Open(ipv4Path); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+				continue
+			}
+			// RTF_UP - маршрут активен
+			if flags, err := strconv.ParseUint(fields[3], 16, 16); err != nil || flags&0x1 == 0 {
+				continue
+			}
+			routes = append(routes, DefaultRoute{Interface: fields[0], Gateway: hexIPv4(fields[2])})
+		}
+		file.Close()
+	}
+
+	if file, err := os.Open(ipv6Path); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			// dest dest_len src src_len next_hop metric refcnt use flags iface
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || fields[0] != strings.Repeat("0", 32) || fields[1] != "00" || fields[9] == "lo" {
+				continue
+			}
+			if flags, err := strconv.ParseUint(fields[8], 16, 32); err != nil || flags&0x1 == 0 {
+				continue
+			}
+			routes = append(routes, DefaultRoute{Interface: fields[9], IPv6: true})
+		}
+		file.Close()
+	}
+
+	return routes
+}
+
+// parseResolvConf читает серверы имен и домены поиска
+func parseResolvConf(path string) ResolverConfig {
+	var config ResolverConfig
+	if target, err := os.Readlink(path); err == nil {
+		config.Target = target
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return config
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			config.Nameservers = append(config.Nameservers, fields[1])
+		case "search", "domain":
+			config.Search = append(config.Search, fields[1:]...)
+		}
+	}
+	return config
+}
+
+// parseNMGeneral разбирает вывод nmcli -t -f STATE,CONNECTIVITY general
+func parseNMGeneral(output string) (string, string) {
+	state, connectivity, _ := strings.Cut(strings.TrimSpace(output), ":")
+	return state, connectivity
+}
+
+// localCommand запускает локальную утилиту с коротким таймаутом
+func localCommand(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(output), err
+}
+
+// CheckNetwork проверяет интерфейсы, маршрут по умолчанию, настройки DNS,
+// синхронизацию времени и состояние NetworkManager. Используются только
+// локальные источники: ничего не отправляется в сеть
+func CheckNetwork() NetworkStatus {
+	status := NetworkStatus{
+		Interfaces: parseNetDev(netDevPath, sysClassNet),
+		Routes:     parseDefaultRoutes(netRoutePath, netIPv6Route),
+		Resolver:   parseResolvConf(resolvConfPath),
+	}
+	sort.Slice(status.Interfaces, func(i, j int) bool {
+		return status.Interfaces[i].Name < status.Interfaces[j].Name
+	})
+
+	// is-active завершается с ненулевым кодом для неактивной службы, но печатает состояние
+	if output, _ := localCommand("systemctl", "is-active", "systemd-resolved"); strings.TrimSpace(output) != "" {
+		status.ResolvedActive = strings.TrimSpace(output)
+	}
+	if output, err := localCommand("timedatectl", "show", "--property=NTP", "--property=NTPSynchronized"); err == nil {
+		if units := parseSystemctlShow(strings.NewReader(output)); len(units) > 0 {
+			status.TimeSync = units[0]
+		}
+	}
+	if output, err := localCommand("nmcli", "-t", "-f", "STATE,CONNECTIVITY", "general"); err == nil {
+		status.NMState, status.NMConnectivity = parseNMGeneral(output)
+	}
+
+	return status
+}
@@ -0,0 +1,142 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseNetDev(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "dev")
+	content := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 62690914    7610    0    0    0     0          0         0 62690914    7610    0    0    0     0       0          0
+  eth0: 104857600  100000  1500   20    0     0          0         0 52428800   50000    0    0    0     0       0          0
+wlan0:        0       0    0    0    0     0          0         0        0       0    0    0    0     0       0          0
+`
+	os.WriteFile(path, []byte(content), 0644)
+	writeSysFiles(t, filepath.Join(root, "net/eth0"), map[string]string{"operstate": "up"})
+	writeSysFiles(t, filepath.Join(root, "net/wlan0"), map[string]string{"operstate": "down"})
+
+	interfaces := parseNetDev(path, filepath.Join(root, "net"))
+	if len(interfaces) != 2 {
+		t.Fatalf("parseNetDev() returned %d interfaces, want 2 (lo skipped)", len(interfaces))
+	}
+	eth := interfaces[0]
+	want := InterfaceStats{Name: "eth0", State: "up", RxBytes: 104857600, RxPackets: 100000, RxErrors: 1500, RxDropped: 20,
+		TxBytes: 52428800, TxPackets: 50000}
+	if eth != want {
+		t.Errorf("eth0 = %+v, want %+v", eth, want)
+	}
+	if interfaces[1].Name != "wlan0" || interfaces[1].State != "down" {
+		t.Errorf("wlan0 = %+v", interfaces[1])
+	}
+
+	// 1500 ошибок на 150000 пакетов - это 1%
+	findings := eth.Findings()
+	if len(findings) != 1 || findings[0].Severity != SeverityWarning || !strings.Contains(findings[0].Message, "1500 packet errors") {
+		t.Errorf("eth0 findings = %v", findings)
+	}
+	if findings := interfaces[1].Findings(); len(findings) != 0 {
+		t.Errorf("idle interface findings = %v", findings)
+	}
+}
+
+func TestParseDefaultRoutes(t *testing.T) {
+	root := t.TempDir()
+	ipv4 := filepath.Join(root, "route")
+	os.WriteFile(ipv4, []byte("Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"+
+		"eth0\t00000000\t010200C0\t0003\t0\t0\t0\t00000000\t0\t0\t0\n"+
+		"eth0\t000200C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n"), 0644)
+	ipv6 := filepath.Join(root, "ipv6_route")
+	zero := strings.Repeat("0", 32)
+	os.WriteFile(ipv6, []byte(
+		zero+" 00 "+zero+" 00 fe800000000000000000000000000001 00000400 00000001 00000000 00450003     wlan0\n"+
+			zero+" 00 "+zero+" 00 "+zero+" ffffffff 00000001 00000000 00200200       lo\n"+
+			"fe800000000000000000000000000000 40 "+zero+" 00 "+zero+" 00000100 00000001 00000000 00000001     wlan0\n"), 0644)
+
+	routes := parseDefaultRoutes(ipv4, ipv6)
+	if len(routes) != 2 {
+		t.Fatalf("parseDefaultRoutes() = %+v, want 2 routes", routes)
+	}
+	if routes[0] != (DefaultRoute{Interface: "eth0", Gateway: "192.0.2.1"}) {
+		t.Errorf("IPv4 route = %+v", routes[0])
+	}
+	if routes[1] != (DefaultRoute{Interface: "wlan0", IPv6: true}) {
+		t.Errorf("IPv6 route = %+v", routes[1])
+	}
+
+	if routes := parseDefaultRoutes(filepath.Join(root, "missing"), filepath.Join(root, "missing")); len(routes) != 0 {
+		t.Errorf("missing files returned routes: %+v", routes)
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	root := t.TempDir()
+	stub := filepath.Join(root, "stub-resolv.conf")
+	os.WriteFile(stub, []byte("# managed by systemd-resolved\nnameserver 127.0.0.53\noptions edns0 trust-ad\nsearch lan example.com\n"), 0644)
+	link := filepath.Join(root, "resolv.conf")
+	os.Symlink(stub, link)
+
+	config := parseResolvConf(link)
+	if len(config.Nameservers) != 1 || !config.Stub() {
+		t.Errorf("parseResolvConf() = %+v, want the resolved stub", config)
+	}
+	if strings.Join(config.Search, ",") != "lan,example.com" || config.Target != stub {
+		t.Errorf("search = %v, target = %q", config.Search, config.Target)
+	}
+}
+
+func TestParseNMGeneral(t *testing.T) {
+	state, connectivity := parseNMGeneral("connected (site only):limited\n")
+	if state != "connected (site only)" || connectivity != "limited" {
+		t.Errorf("parseNMGeneral() = %q, %q", state, connectivity)
+	}
+}
+
+func TestNetworkStatus_Findings(t *testing.T) {
+	healthy := NetworkStatus{
+		Interfaces:     []InterfaceStats{{Name: "eth0", State: "up", RxPackets: 1000, TxPackets: 1000}},
+		Routes:         []DefaultRoute{{Interface: "eth0", Gateway: "192.0.2.1"}},
+		Resolver:       ResolverConfig{Nameservers: []string{resolvedStubDNS}},
+		ResolvedActive: "active",
+		TimeSync:       map[string]string{"NTP": "yes", "NTPSynchronized": "yes"},
+		NMState:        "connected",
+		NMConnectivity: "full",
+	}
+	if findings := healthy.Findings(); len(findings) != 0 {
+		t.Errorf("healthy network produced findings: %v", findings)
+	}
+
+	broken := NetworkStatus{
+		Interfaces:     []InterfaceStats{{Name: "wlan0", State: "down"}},
+		Resolver:       ResolverConfig{Nameservers: []string{resolvedStubDNS}},
+		ResolvedActive: "inactive",
+		TimeSync:       map[string]string{"NTP": "yes", "NTPSynchronized": "no"},
+		NMState:        "disconnected",
+		NMConnectivity: "none",
+	}
+	var messages []string
+	for _, finding := range broken.Findings() {
+		messages = append(messages, finding.String())
+	}
+	got := strings.Join(messages, "\n")
+	for _, want := range []string{
+		"no network interface is up",
+		"no default route",
+		"systemd-resolved, but the service is inactive",
+		"not synchronized",
+		"no connectivity (disconnected)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Findings() missing %q in:\n%s", want, got)
+		}
+	}
+
+	if findings := (NetworkStatus{Interfaces: healthy.Interfaces, Routes: healthy.Routes}).Findings(); len(findings) != 1 ||
+		!strings.Contains(findings[0].Message, "no nameservers") {
+		t.Errorf("missing nameservers findings = %v", findings)
+	}
+}
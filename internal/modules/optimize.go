@@ -47,6 +47,12 @@ func (m *OptimizationModule) Execute(progressCallback func(progress float64, mes
 		progressCallback(0.9, "Network cache cleared")
 	}
 	
+	progressCallback(0.92, "Analyzing boot performance...")
+	
+	if err := m.analyzeBoot(progressCallback); err != nil {
+		progressCallback(0.98, fmt.Sprintf("Boot analysis failed: %v", err))
+	}
+	
 	progressCallback(1.0, "System optimization completed")
 	
 	return nil
@@ -125,6 +131,15 @@ func (m *OptimizationModule) optimizeMemory(progressCallback func(progress float
 }
 
 func (m *OptimizationModule) clearNetworkCache(progressCallback func(progress float64, message string)) error {
+	progressCallback(0.72, "Diagnosing network...")
+	
+	// Сначала смотрим, что со связью: перезапуск NetworkManager рвет соединения
+	// и нужен только если он сам сообщает о проблемах
+	network := CheckNetwork()
+	for _, finding := range network.Findings() {
+		progressCallback(0.74, "  "+finding.String())
+	}
+	
 	progressCallback(0.75, "Flushing DNS cache...")
 	
 	// Очищаем DNS кэш
@@ -137,6 +152,11 @@ func (m *OptimizationModule) clearNetworkCache(progressCallback func(progress fl
 		}
 	}
 	
+	if network.NMConnectivity == "full" {
+		progressCallback(0.87, "NetworkManager reports full connectivity, restart skipped")
+		return nil
+	}
+	
 	progressCallback(0.85, "Clearing network manager cache...")
 	
 	// Перезапускаем NetworkManager для очистки кэша
@@ -147,4 +167,33 @@ func (m *OptimizationModule) clearNetworkCache(progressCallback func(progress fl
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
+
+// analyzeBoot измеряет загрузку и предлагает, какие юниты отключить или
+// отложить. Сами юниты не трогаются: решение остается за пользователем
+func (m *OptimizationModule) analyzeBoot(progressCallback func(progress float64, message string)) error {
+	report, err := AnalyzeBoot()
+	if err != nil {
+		return err
+	}
+	
+	progressCallback(0.94, report.Summary())
+	
+	for _, change := range report.Changes() {
+		if change.Delta() > 0 {
+			progressCallback(0.95, fmt.Sprintf("  ⚠️ %s got slower: %s → %s",
+				change.Unit, formatBootDuration(change.Before), formatBootDuration(change.After)))
+		}
+	}
+	
+	suggestions := report.Suggestions()
+	if len(suggestions) == 0 {
+		progressCallback(0.98, "No boot units to disable or delay")
+		return nil
+	}
+	for _, suggestion := range suggestions {
+		progressCallback(0.98, "  💡 "+suggestion.String())
+	}
+	
+	return nil
+}
@@ -0,0 +1,292 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dpkgStatusPath = "/var/lib/dpkg/status"
+
+// dpkgPackage - запись о пакете из /var/lib/dpkg/status
+type dpkgPackage struct {
+	Name      string
+	Status    string // например "install ok installed" или "deinstall ok config-files"
+	Section   string
+	Priority  string
+	Essential bool
+	// InstalledSize - размер установленных файлов в байтах
+	InstalledSize int64
+	// Depends - зависимости, включая Pre-Depends и Recommends.
+	// Каждый элемент - список альтернатив через "|"
+	Depends   [][]string
+	Provides  []string
+	Conffiles []string
+}
+
+// installed возвращает true для полностью установленных пакетов
+func (p dpkgPackage) installed() bool {
+	return strings.HasSuffix(p.Status, " installed")
+}
+
+// residual возвращает true для удаленных пакетов, от которых остались
+// только файлы конфигурации (состояние rc в dpkg -l)
+func (p dpkgPackage) residual() bool {
+	return strings.HasSuffix(p.Status, " config-files")
+}
+
+// parseDpkgStatus разбирает файл статуса dpkg
+func parseDpkgStatus(r io.Reader) ([]dpkgPackage, error) {
+	var packages []dpkgPackage
+	var current dpkgPackage
+	var field string
+
+	flush := func() {
+		if current.Name != "" {
+			packages = append(packages, current)
+		}
+		current = dpkgPackage{}
+		field = ""
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+
+		// Строки продолжения многострочных полей начинаются с пробела
+		if line[0] == ' ' || line[0] == '\t' {
+			if field == "Conffiles" {
+				if fields := strings.Fields(line); len(fields) > 0 {
+					current.Conffiles = append(current.Conffiles, fields[0])
+				}
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = name
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "Package":
+			current.Name = value
+		case "Status":
+			current.Status = value
+		case "Section":
+			current.Section = value
+		case "Priority":
+			current.Priority = value
+		case "Essential":
+			current.Essential = value == "yes"
+		case "Installed-Size":
+			if size, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.InstalledSize = size * 1024
+			}
+		case "Depends", "Pre-Depends", "Recommends":
+			current.Depends = append(current.Depends, parseDependencies(value)...)
+		case "Provides":
+			for _, alternatives := range parseDependencies(value) {
+				current.Provides = append(current.Provides, alternatives...)
+			}
+		}
+	}
+	flush()
+
+	return packages, scanner.Err()
+}
+
+// parseDependencies разбирает поле зависимостей вида
+// "libc6 (>= 2.34), libfoo1 | libbar1, python3:any"
+func parseDependencies(value string) [][]string {
+	var result [][]string
+	for _, group := range strings.Split(value, ",") {
+		var alternatives []string
+		for _, dep := range strings.Split(group, "|") {
+			dep = strings.TrimSpace(dep)
+			if i := strings.IndexAny(dep, " ("); i >= 0 {
+				dep = dep[:i]
+			}
+			if i := strings.Index(dep, ":"); i >= 0 {
+				dep = dep[:i] // Квалификатор архитектуры
+			}
+			if dep != "" {
+				alternatives = append(alternatives, dep)
+			}
+		}
+		if len(alternatives) > 0 {
+			result = append(result, alternatives)
+		}
+	}
+	return result
+}
+
+// residualPackages возвращает пакеты в состоянии rc
+func residualPackages(packages []dpkgPackage) []dpkgPackage {
+	var residual []dpkgPackage
+	for _, pkg := range packages {
+		if pkg.residual() {
+			residual = append(residual, pkg)
+		}
+	}
+	return residual
+}
+
+// orphanLibraries ищет установленные библиотеки, от которых не зависит ни один
+// установленный пакет (как deborphan). Recommends тоже считаются зависимостью
+func orphanLibraries(packages []dpkgPackage) []dpkgPackage {
+	// Имена, которые кому-то нужны: сами пакеты и виртуальные через Provides
+	needed := make(map[string]bool)
+	for _, pkg := range packages {
+		if !pkg.installed() {
+			continue
+		}
+		for _, alternatives := range pkg.Depends {
+			for _, dep := range alternatives {
+				if dep != pkg.Name {
+					needed[dep] = true
+				}
+			}
+		}
+	}
+
+	var orphans []dpkgPackage
+	for _, pkg := range packages {
+		if !pkg.installed() || pkg.Essential || pkg.Priority == "required" || pkg.Priority == "important" {
+			continue
+		}
+		if !isLibrarySection(pkg.Section) || needed[pkg.Name] {
+			continue
+		}
+		providesNeeded := false
+		for _, virtual := range pkg.Provides {
+			if needed[virtual] {
+				providesNeeded = true
+				break
+			}
+		}
+		if !providesNeeded {
+			orphans = append(orphans, pkg)
+		}
+	}
+
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].InstalledSize > orphans[j].InstalledSize })
+	return orphans
+}
+
+// isLibrarySection учитывает разделы вида "libs", "oldlibs" и "universe/libs"
+func isLibrarySection(section string) bool {
+	if i := strings.LastIndex(section, "/"); i >= 0 {
+		section = section[i+1:]
+	}
+	return section == "libs" || section == "oldlibs"
+}
+
+// conffilesSize считает место, которое занимают оставшиеся файлы конфигурации
+func conffilesSize(pkg dpkgPackage) int64 {
+	var size int64
+	for _, path := range pkg.Conffiles {
+		if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() {
+			size += fileUsage(info).Allocated
+		}
+	}
+	return size
+}
+
+func readDpkgStatus() ([]dpkgPackage, error) {
+	file, err := os.Open(dpkgStatusPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseDpkgStatus(file)
+}
+
+// packagePurgeCandidates возвращает пакеты для удаления с их размерами: только
+// те пакеты в состоянии rc и библиотеки-сироты, что явно выбраны в PurgePackages
+func (m *CleanupModule) packagePurgeCandidates(packages []dpkgPackage) map[string]int64 {
+	candidates := make(map[string]int64)
+	for _, pkg := range residualPackages(packages) {
+		if containsString(m.PurgePackages, pkg.Name) {
+			candidates[pkg.Name] = conffilesSize(pkg)
+		}
+	}
+	for _, pkg := range orphanLibraries(packages) {
+		if containsString(m.PurgePackages, pkg.Name) {
+			candidates[pkg.Name] = pkg.InstalledSize
+		}
+	}
+	return candidates
+}
+
+func (m *CleanupModule) previewPackages() []CleanupItem {
+	packages, err := readDpkgStatus()
+	if err != nil {
+		return nil
+	}
+
+	var items []CleanupItem
+	for _, pkg := range residualPackages(packages) {
+		items = append(items, CleanupItem{Category: "Residual package configs", Path: pkg.Name, Size: conffilesSize(pkg), Optional: true})
+	}
+	for _, pkg := range orphanLibraries(packages) {
+		items = append(items, CleanupItem{Category: "Orphan libraries", Path: pkg.Name, Size: pkg.InstalledSize, Optional: true})
+	}
+	return items
+}
+
+// purgePackages удаляет выбранные пакеты в состоянии rc и библиотеки-сироты.
+// Возвращает размер пакетов, которые действительно исчезли из базы dpkg
+func (m *CleanupModule) purgePackages() int64 {
+	packages, err := readDpkgStatus()
+	if err != nil {
+		return 0
+	}
+	candidates := m.packagePurgeCandidates(packages)
+	if len(candidates) == 0 {
+		return 0
+	}
+
+	names := make([]string, 0, len(candidates))
+	for name := range candidates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	args := append([]string{"purge", "-y"}, names...)
+	exec.CommandContext(ctx, "apt-get", args...).Run() // Без root завершится ошибкой
+
+	after, err := readDpkgStatus()
+	if err != nil {
+		return 0
+	}
+	remaining := make(map[string]bool)
+	for _, pkg := range after {
+		if pkg.installed() || pkg.residual() {
+			remaining[pkg.Name] = true
+		}
+	}
+
+	var freed int64
+	for name, size := range candidates {
+		if !remaining[name] {
+			freed += size
+		}
+	}
+	return freed
+}
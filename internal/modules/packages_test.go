@@ -0,0 +1,141 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleDpkgStatus = `Package: vim
+Status: install ok installed
+Priority: optional
+Section: editors
+Installed-Size: 4000
+Depends: vim-common (= 2:9.1), libgpm2 | libgpm-dummy, libc6:any (>= 2.34)
+Recommends: libsodium23
+Description: Vi IMproved
+ Long description line
+ .
+
+Package: libgpm2
+Status: install ok installed
+Priority: optional
+Section: libs
+Installed-Size: 60
+
+Package: libsodium23
+Status: install ok installed
+Section: libs
+Installed-Size: 400
+
+Package: libold1
+Status: install ok installed
+Priority: optional
+Section: oldlibs
+Installed-Size: 2048
+
+Package: libvirtual-impl
+Status: install ok installed
+Section: universe/libs
+Installed-Size: 10
+Provides: vim-common
+
+Package: libc6
+Status: install ok installed
+Priority: required
+Section: libs
+Installed-Size: 13000
+
+Package: oldapp
+Status: deinstall ok config-files
+Priority: optional
+Section: utils
+Installed-Size: 500
+Conffiles:
+ /etc/oldapp/oldapp.conf 0123456789abcdef
+ /etc/oldapp/extra.conf 0123456789abcdef obsolete
+`
+
+func TestParseDpkgStatus(t *testing.T) {
+	packages, err := parseDpkgStatus(strings.NewReader(sampleDpkgStatus))
+	if err != nil {
+		t.Fatalf("parseDpkgStatus() returned error: %v", err)
+	}
+	if len(packages) != 7 {
+		t.Fatalf("parseDpkgStatus() returned %d packages, want 7", len(packages))
+	}
+
+	vim := packages[0]
+	if vim.InstalledSize != 4000*1024 || !vim.installed() {
+		t.Errorf("Unexpected vim package: %+v", vim)
+	}
+	expected := [][]string{{"vim-common"}, {"libgpm2", "libgpm-dummy"}, {"libc6"}, {"libsodium23"}}
+	if !reflect.DeepEqual(vim.Depends, expected) {
+		t.Errorf("Depends = %v, want %v", vim.Depends, expected)
+	}
+
+	oldapp := packages[6]
+	if !oldapp.residual() || oldapp.installed() {
+		t.Errorf("oldapp should be in rc state: %+v", oldapp)
+	}
+	if !reflect.DeepEqual(oldapp.Conffiles, []string{"/etc/oldapp/oldapp.conf", "/etc/oldapp/extra.conf"}) {
+		t.Errorf("Conffiles = %v", oldapp.Conffiles)
+	}
+}
+
+func TestOrphanLibraries(t *testing.T) {
+	packages, _ := parseDpkgStatus(strings.NewReader(sampleDpkgStatus))
+
+	orphans := orphanLibraries(packages)
+	var names []string
+	for _, pkg := range orphans {
+		names = append(names, pkg.Name)
+	}
+	// libgpm2 нужен vim, libsodium23 рекомендован, libvirtual-impl дает
+	// виртуальный пакет, libc6 обязателен
+	if !reflect.DeepEqual(names, []string{"libold1"}) {
+		t.Errorf("orphanLibraries() = %v, want [libold1]", names)
+	}
+
+	residual := residualPackages(packages)
+	if len(residual) != 1 || residual[0].Name != "oldapp" {
+		t.Errorf("residualPackages() = %+v, want oldapp", residual)
+	}
+}
+
+func TestPackagePurgeCandidates(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_packages_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	conffile := filepath.Join(tempDir, "app.conf")
+	writeFile(t, conffile, make([]byte, 5000))
+
+	packages := []dpkgPackage{
+		{Name: "oldapp", Status: "deinstall ok config-files", Conffiles: []string{conffile, "/missing.conf"}},
+		{Name: "libold1", Status: "install ok installed", Section: "oldlibs", InstalledSize: 2048 * 1024},
+		{Name: "libunused2", Status: "install ok installed", Section: "libs", InstalledSize: 100 * 1024},
+	}
+
+	module := &CleanupModule{PurgePackages: []string{"oldapp", "libold1"}}
+	candidates := module.packagePurgeCandidates(packages)
+
+	// Удаляется только явно выбранное
+	if len(candidates) != 2 {
+		t.Fatalf("packagePurgeCandidates() = %v, want oldapp and libold1", candidates)
+	}
+	if candidates["oldapp"] != allocatedSize(t, conffile) {
+		t.Errorf("oldapp size = %d, want %d", candidates["oldapp"], allocatedSize(t, conffile))
+	}
+	if candidates["libold1"] != 2048*1024 {
+		t.Errorf("libold1 size = %d, want %d", candidates["libold1"], 2048*1024)
+	}
+
+	if candidates := (&CleanupModule{}).packagePurgeCandidates(packages); len(candidates) != 0 {
+		t.Errorf("packagePurgeCandidates() without a selection = %v, want none", candidates)
+	}
+}
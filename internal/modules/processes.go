@@ -0,0 +1,381 @@
+package modules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	procDir       = "/proc"
+	pressureDir   = "/proc/pressure"
+	cpuOnlinePath = "/sys/devices/system/cpu/online"
+	loadAvgPath   = "/proc/loadavg"
+	// clockTicks - USER_HZ, в чем /proc/<pid>/stat считает время процессора
+	clockTicks = 100
+	// topConsumers - сколько процессов показывать в каждом топе
+	topConsumers = 3
+	// lineWidth - до скольких символов обрезаются командные строки и журнал в отчете
+	lineWidth = 60
+)
+
+// processSampleInterval и processSamples - процессы снимаются processSamples раз
+// с интервалом processSampleInterval. По первому и последнему снимку считаются
+// загрузка процессора и ввод-вывод, по всем - зависшие процессы
+var (
+	processSampleInterval = 500 * time.Millisecond
+	processSamples        = 5
+)
+
+// ProcessInfo - состояние процесса из /proc/<pid>
+type ProcessInfo struct {
+	PID     int
+	PPID    int
+	Command string
+	// State - состояние из /proc/<pid>/stat: R, S, D, Z...
+	State byte
+	// CPUTicks - время процессора в тиках USER_HZ с запуска процесса
+	CPUTicks uint64
+	// RSS - резидентная память в байтах
+	RSS int64
+	// StartTicks - время запуска в тиках с загрузки, вместе с PID отличает
+	// процесс от другого, получившего тот же PID
+	StartTicks uint64
+	// IOBytes - прочитано и записано на диск с запуска процесса. Чужие
+	// процессы без прав root не видны, для них 0
+	IOBytes uint64
+}
+
+// ProcessUsage - потребление процесса за интервал измерения
+type ProcessUsage struct {
+	ProcessInfo
+	// CPUPercent - загрузка в процентах одного ядра
+	CPUPercent float64
+	// IORate - байт в секунду
+	IORate float64
+}
+
+// PSI - средняя доля времени, когда задачи простаивали в ожидании ресурса
+// (Pressure Stall Information), в процентах
+type PSI struct {
+	SomeAvg10 float64
+	SomeAvg60 float64
+	// Full* - простаивали все задачи сразу; для cpu ядро не всегда это сообщает
+	FullAvg10 float64
+	FullAvg60 float64
+}
+
+// psiLimits - при какой доле простоя за минуту давление считается проблемой
+var psiLimits = map[string]struct{ someWarning, fullCritical float64 }{
+	"cpu":    {someWarning: 50, fullCritical: 0},
+	"memory": {someWarning: 10, fullCritical: 5},
+	"io":     {someWarning: 30, fullCritical: 10},
+}
+
+// parseCPUList разбирает список процессоров вида "0-3,6,8-11" и возвращает их число
+func parseCPUList(list string) int {
+	count := 0
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				continue
+			}
+		}
+		if end >= start {
+			count += end - start + 1
+		}
+	}
+	return count
+}
+
+// onlineCPUs возвращает число включенных процессоров
+func onlineCPUs() int {
+	if count := parseCPUList(readSysString(cpuOnlinePath)); count > 0 {
+		return count
+	}
+	return 1
+}
+
+// parsePSI разбирает файл из /proc/pressure
+func parsePSI(r io.Reader) (PSI, error) {
+	var psi PSI
+	found := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		values := make(map[string]float64)
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				values[key] = parsed
+			}
+		}
+		switch fields[0] {
+		case "some":
+			psi.SomeAvg10, psi.SomeAvg60 = values["avg10"], values["avg60"]
+			found = true
+		case "full":
+			psi.FullAvg10, psi.FullAvg60 = values["avg10"], values["avg60"]
+		}
+	}
+
+	if !found {
+		return psi, fmt.Errorf("no pressure data")
+	}
+	return psi, scanner.Err()
+}
+
+// ReadPressure читает PSI для cpu, memory и io. Ядра без PSI возвращают ошибку
+func ReadPressure() (map[string]PSI, error) {
+	pressure := make(map[string]PSI)
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		file, err := os.Open(filepath.Join(pressureDir, resource))
+		if err != nil {
+			return nil, err
+		}
+		psi, err := parsePSI(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		pressure[resource] = psi
+	}
+	return pressure, nil
+}
+
+// pressureFindings сравнивает давление за минуту с порогами psiLimits
+func pressureFindings(pressure map[string]PSI) []Finding {
+	var findings []Finding
+	for _, resource := range []string{"cpu", "memory", "io"} {
+		psi, ok := pressure[resource]
+		if !ok {
+			continue
+		}
+		limits := psiLimits[resource]
+		switch {
+		case limits.fullCritical > 0 && psi.FullAvg60 >= limits.fullCritical:
+			findings = append(findings, Finding{Severity: SeverityCritical,
+				Message: fmt.Sprintf("all tasks stalled on %s %.1f%% of the last minute", resource, psi.FullAvg60)})
+		case psi.SomeAvg60 >= limits.someWarning:
+			findings = append(findings, Finding{Severity: SeverityWarning,
+				Message: fmt.Sprintf("tasks waited for %s %.1f%% of the last minute", resource, psi.SomeAvg60)})
+		}
+	}
+	return findings
+}
+
+// parseProcStat разбирает /proc/<pid>/stat. Имя процесса в скобках может
+// содержать пробелы и скобки, поэтому поля считаются от последней ')'
+func parseProcStat(data string) (ProcessInfo, bool) {
+	nameStart := strings.IndexByte(data, '(')
+	nameEnd := strings.LastIndexByte(data, ')')
+	if nameStart < 0 || nameEnd < nameStart {
+		return ProcessInfo{}, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(data[:nameStart]))
+	if err != nil {
+		return ProcessInfo{}, false
+	}
+
+	// state ppid pgrp session tty_nr tpgid flags minflt cminflt majflt cmajflt utime stime ... starttime vsize rss
+	fields := strings.Fields(data[nameEnd+1:])
+	if len(fields) < 22 || len(fields[0]) == 0 {
+		return ProcessInfo{}, false
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	start, _ := strconv.ParseUint(fields[19], 10, 64)
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+
+	return ProcessInfo{
+		PID:        pid,
+		PPID:       ppid,
+		Command:    data[nameStart+1 : nameEnd],
+		State:      fields[0][0],
+		CPUTicks:   utime + stime,
+		RSS:        rss * int64(os.Getpagesize()),
+		StartTicks: start,
+	}, true
+}
+
+// processCommand возвращает командную строку процесса. У потоков ядра
+// она пустая, тогда используется имя в квадратных скобках
+func processCommand(pidDir, comm string) string {
+	data, err := os.ReadFile(filepath.Join(pidDir, "cmdline"))
+	if err != nil || len(data) == 0 {
+		return "[" + comm + "]"
+	}
+	return strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+}
+
+// processIOBytes читает read_bytes и write_bytes из /proc/<pid>/io
+func processIOBytes(pidDir string) uint64 {
+	data, err := os.ReadFile(filepath.Join(pidDir, "io"))
+	if err != nil {
+		return 0
+	}
+	var total uint64
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok || (key != "read_bytes" && key != "write_bytes") {
+			continue
+		}
+		if parsed, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64); err == nil {
+			total += parsed
+		}
+	}
+	return total
+}
+
+// readProcesses читает все процессы из root (/proc)
+func readProcesses(root string) map[int]ProcessInfo {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	processes := make(map[int]ProcessInfo)
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		pidDir := filepath.Join(root, entry.Name())
+		data, err := os.ReadFile(filepath.Join(pidDir, "stat"))
+		if err != nil {
+			continue // Процесс уже завершился
+		}
+		info, ok := parseProcStat(string(data))
+		if !ok {
+			continue
+		}
+		info.Command = processCommand(pidDir, info.Command)
+		info.IOBytes = processIOBytes(pidDir)
+		processes[info.PID] = info
+	}
+	return processes
+}
+
+// processUsage считает потребление процессов между двумя снимками. Процессы,
+// которых нет в первом снимке, учитываются с момента запуска внутри интервала
+func processUsage(before, after map[int]ProcessInfo, interval time.Duration) []ProcessUsage {
+	seconds := interval.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+
+	usage := make([]ProcessUsage, 0, len(after))
+	for pid, info := range after {
+		previous := before[pid]
+		current := ProcessUsage{ProcessInfo: info}
+		if info.CPUTicks >= previous.CPUTicks {
+			current.CPUPercent = float64(info.CPUTicks-previous.CPUTicks) / clockTicks / seconds * 100
+		}
+		if info.IOBytes >= previous.IOBytes {
+			current.IORate = float64(info.IOBytes-previous.IOBytes) / seconds
+		}
+		usage = append(usage, current)
+	}
+	return usage
+}
+
+// topBy возвращает до n процессов с наибольшим ненулевым значением key
+func topBy(usage []ProcessUsage, n int, key func(ProcessUsage) float64) []ProcessUsage {
+	sorted := make([]ProcessUsage, 0, len(usage))
+	for _, process := range usage {
+		if key(process) > 0 {
+			sorted = append(sorted, process)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if key(sorted[i]) != key(sorted[j]) {
+			return key(sorted[i]) > key(sorted[j])
+		}
+		return sorted[i].PID < sorted[j].PID
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// shorten обрезает командную строку или строку журнала для отчета
+func shorten(text string) string {
+	runes := []rune(text)
+	if len(runes) <= lineWidth {
+		return text
+	}
+	return string(runes[:lineWidth-1]) + "…"
+}
+
+// ProcessSample - несколько снимков процессов, сделанных с равным интервалом
+type ProcessSample struct {
+	Snapshots []map[int]ProcessInfo
+	Interval  time.Duration
+}
+
+// SampleProcesses снимает процессы processSamples раз с интервалом processSampleInterval
+func SampleProcesses() ProcessSample {
+	sample := ProcessSample{Interval: processSampleInterval}
+	for i := 0; i < processSamples; i++ {
+		if i > 0 {
+			time.Sleep(processSampleInterval)
+		}
+		sample.Snapshots = append(sample.Snapshots, readProcesses(procDir))
+	}
+	return sample
+}
+
+// Latest возвращает последний снимок
+func (s ProcessSample) Latest() map[int]ProcessInfo {
+	if len(s.Snapshots) == 0 {
+		return nil
+	}
+	return s.Snapshots[len(s.Snapshots)-1]
+}
+
+// Usage считает потребление процессов от первого снимка до последнего
+func (s ProcessSample) Usage() []ProcessUsage {
+	if len(s.Snapshots) == 0 {
+		return nil
+	}
+	window := s.Interval * time.Duration(len(s.Snapshots)-1)
+	return processUsage(s.Snapshots[0], s.Latest(), window)
+}
+
+// topConsumerLines описывает самых активных потребителей процессора, памяти и диска
+func topConsumerLines(usage []ProcessUsage) []string {
+	var lines []string
+	for _, process := range topBy(usage, topConsumers, func(p ProcessUsage) float64 { return p.CPUPercent }) {
+		lines = append(lines, fmt.Sprintf("🔝 CPU %.0f%%: %s (pid %d)", process.CPUPercent, shorten(process.Command), process.PID))
+	}
+	for _, process := range topBy(usage, topConsumers, func(p ProcessUsage) float64 { return float64(p.RSS) }) {
+		lines = append(lines, fmt.Sprintf("🔝 Memory %d MB: %s (pid %d)", process.RSS/1024/1024, shorten(process.Command), process.PID))
+	}
+	for _, process := range topBy(usage, topConsumers, func(p ProcessUsage) float64 { return p.IORate }) {
+		lines = append(lines, fmt.Sprintf("🔝 Disk I/O %d KB/s: %s (pid %d)", int64(process.IORate)/1024, shorten(process.Command), process.PID))
+	}
+	return lines
+}
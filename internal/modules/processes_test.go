@@ -0,0 +1,125 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCPUList(t *testing.T) {
+	tests := map[string]int{
+		"0":          1,
+		"0-7":        8,
+		"0-3,6,8-11": 9,
+		"":           0,
+		"0-3\n":      4,
+	}
+	for list, want := range tests {
+		if got := parseCPUList(list); got != want {
+			t.Errorf("parseCPUList(%q) = %d, want %d", list, got, want)
+		}
+	}
+}
+
+func TestParsePSI(t *testing.T) {
+	psi, err := parsePSI(strings.NewReader("some avg10=12.50 avg60=20.10 avg300=5.00 total=123\nfull avg10=3.00 avg60=6.25 avg300=1.00 total=45\n"))
+	if err != nil {
+		t.Fatalf("parsePSI() returned error: %v", err)
+	}
+	if psi.SomeAvg10 != 12.5 || psi.SomeAvg60 != 20.1 || psi.FullAvg60 != 6.25 {
+		t.Errorf("Unexpected PSI: %+v", psi)
+	}
+
+	if _, err := parsePSI(strings.NewReader("")); err == nil {
+		t.Error("parsePSI() should fail without data")
+	}
+
+	findings := pressureFindings(map[string]PSI{
+		"cpu":    {SomeAvg60: 10},
+		"memory": psi,
+		"io":     {SomeAvg60: 35},
+	})
+	if len(findings) != 2 {
+		t.Fatalf("pressureFindings() = %v, want memory and io", findings)
+	}
+	if findings[0].Severity != SeverityCritical || !strings.Contains(findings[0].Message, "memory") {
+		t.Errorf("Memory full stall should be critical, got %v", findings[0])
+	}
+	if findings[1].Severity != SeverityWarning || !strings.Contains(findings[1].Message, "io") {
+		t.Errorf("I/O pressure should be a warning, got %v", findings[1])
+	}
+}
+
+func TestParseProcStat(t *testing.T) {
+	stat := "4242 (Web Content (x)) S 1000 4242 4242 0 -1 4194304 81 0 0 0 250 50 0 0 20 0 1 0 282236 2703360 1000 18446744073709551615\n"
+	info, ok := parseProcStat(stat)
+	if !ok {
+		t.Fatal("parseProcStat() failed")
+	}
+	if info.PID != 4242 || info.PPID != 1000 || info.Command != "Web Content (x)" || info.State != 'S' {
+		t.Errorf("Unexpected process: %+v", info)
+	}
+	if info.CPUTicks != 300 || info.RSS != 1000*int64(os.Getpagesize()) || info.StartTicks != 282236 {
+		t.Errorf("CPUTicks = %d, RSS = %d, StartTicks = %d", info.CPUTicks, info.RSS, info.StartTicks)
+	}
+
+	if _, ok := parseProcStat("garbage"); ok {
+		t.Error("parseProcStat() should reject malformed data")
+	}
+}
+
+func TestReadProcesses(t *testing.T) {
+	root := t.TempDir()
+	writeSysFiles(t, filepath.Join(root, "100"), map[string]string{
+		"stat":    "100 (python3) R 1 100 100 0 -1 0 0 0 0 0 10 5 0 0 20 0 1 0 1 1 2048 0",
+		"cmdline": "python3\x00train.py\x00--epochs\x0010",
+		"io":      "rchar: 1\nread_bytes: 4096\nwrite_bytes: 8192\ncancelled_write_bytes: 0",
+	})
+	writeSysFiles(t, filepath.Join(root, "2"), map[string]string{
+		"stat": "2 (kthreadd) S 0 0 0 0 -1 0 0 0 0 0 0 0 0 0 20 0 1 0 1 0 0 0",
+	})
+	os.WriteFile(filepath.Join(root, "2", "cmdline"), nil, 0644)
+	os.MkdirAll(filepath.Join(root, "self"), 0755)
+
+	processes := readProcesses(root)
+	if len(processes) != 2 {
+		t.Fatalf("readProcesses() returned %d processes, want 2", len(processes))
+	}
+	if got := processes[100]; got.Command != "python3 train.py --epochs 10" || got.IOBytes != 12288 {
+		t.Errorf("Unexpected process: %+v", got)
+	}
+	if got := processes[2].Command; got != "[kthreadd]" {
+		t.Errorf("Kernel thread command = %q, want [kthreadd]", got)
+	}
+}
+
+func TestProcessUsage_Top(t *testing.T) {
+	before := map[int]ProcessInfo{
+		1: {PID: 1, Command: "busy", CPUTicks: 100, IOBytes: 0},
+		2: {PID: 2, Command: "idle", CPUTicks: 500, RSS: 900 * 1024 * 1024},
+	}
+	after := map[int]ProcessInfo{
+		1: {PID: 1, Command: "busy", CPUTicks: 250, IOBytes: 4 * 1024 * 1024},
+		2: {PID: 2, Command: "idle", CPUTicks: 500, RSS: 900 * 1024 * 1024},
+		3: {PID: 3, Command: "new", CPUTicks: 20},
+	}
+
+	usage := processUsage(before, after, 2*time.Second)
+	top := topBy(usage, 2, func(p ProcessUsage) float64 { return p.CPUPercent })
+	if len(top) != 2 || top[0].PID != 1 || top[0].CPUPercent != 75 || top[1].PID != 3 {
+		t.Errorf("Top CPU = %+v, want busy at 75%% then new", top)
+	}
+
+	lines := strings.Join(topConsumerLines(usage), "\n")
+	for _, want := range []string{"CPU 75%: busy (pid 1)", "Memory 900 MB: idle (pid 2)", "Disk I/O 2048 KB/s: busy (pid 1)"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("topConsumerLines() = %q, want %q", lines, want)
+		}
+	}
+
+	if got := shorten(strings.Repeat("x", 100)); len([]rune(got)) != lineWidth {
+		t.Errorf("shorten() length = %d, want %d", len([]rune(got)), lineWidth)
+	}
+}
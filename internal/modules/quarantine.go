@@ -0,0 +1,300 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultQuarantineRetention = 7 * 24 * time.Hour
+
+// Quarantine - каталог, куда отмеченные пользователем файлы переносятся
+// вместо немедленного удаления. Пока срок хранения не истек, их можно вернуть
+// на место, после этого их окончательно удаляет CleanupModule
+type Quarantine struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// QuarantineEntry - один перенесенный в карантин файл или каталог
+type QuarantineEntry struct {
+	ID       string    `json:"id"`
+	Original string    `json:"original"`
+	Size     int64     `json:"size"`
+	Added    time.Time `json:"added"`
+	// LinkedTo - если на месте оригинала оставлена жесткая ссылка на этот файл
+	LinkedTo string `json:"linked_to,omitempty"`
+}
+
+// NewQuarantine создает карантин в каталоге dir
+func NewQuarantine(dir string) *Quarantine {
+	return &Quarantine{Dir: dir}
+}
+
+// DefaultQuarantine возвращает карантин текущего пользователя
+// в $XDG_DATA_HOME/ububu/quarantine
+func DefaultQuarantine() (*Quarantine, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	return quarantineFor(homeDir), nil
+}
+
+// quarantineFor возвращает карантин пользователя с домашним каталогом homeDir
+func quarantineFor(homeDir string) *Quarantine {
+	dataHome := homeEnvPath(homeDir, "XDG_DATA_HOME", filepath.Join(homeDir, ".local/share"))
+	return NewQuarantine(filepath.Join(dataHome, "ububu/quarantine"))
+}
+
+func (q *Quarantine) itemsDir() string {
+	return filepath.Join(q.Dir, "items")
+}
+
+func (q *Quarantine) manifestPath() string {
+	return filepath.Join(q.Dir, "manifest.json")
+}
+
+// validQuarantineID проверяет, что идентификатор из манифеста - имя файла
+// внутри items, а не путь: манифест лежит в каталоге пользователя и может быть подменен
+func validQuarantineID(id string) bool {
+	return id != "" && id != "." && id != ".." && filepath.Base(id) == id
+}
+
+// Add переносит path в карантин. Перенос возможен только в пределах одной
+// файловой системы, чтобы он был мгновенным и не требовал места
+func (q *Quarantine) Add(path string) (QuarantineEntry, error) {
+	return q.add(path, "")
+}
+
+// ReplaceWithLink переносит path в карантин и оставляет на его месте жесткую
+// ссылку на target. Так дубликат перестает занимать место, но остается доступен
+func (q *Quarantine) ReplaceWithLink(path, target string) (QuarantineEntry, error) {
+	return q.add(path, target)
+}
+
+func (q *Quarantine) add(path, linkTo string) (QuarantineEntry, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return QuarantineEntry{}, err
+	}
+	dir := filepath.Clean(q.Dir)
+	if path == dir || isSubpath(path, dir) || isSubpath(dir, path) {
+		return QuarantineEntry{}, fmt.Errorf("cannot quarantine %s: it contains or is inside the quarantine", path)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.checkDirs(); err != nil {
+		return QuarantineEntry{}, err
+	}
+	if err := os.MkdirAll(q.itemsDir(), 0700); err != nil {
+		return QuarantineEntry{}, err
+	}
+
+	entry := QuarantineEntry{
+		ID:       fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(path)),
+		Original: path,
+		Size:     measurePath(path).Allocated,
+		Added:    time.Now(),
+		LinkedTo: linkTo,
+	}
+
+	if err := os.Rename(path, filepath.Join(q.itemsDir(), entry.ID)); err != nil {
+		if linkErr, ok := err.(*os.LinkError); ok && linkErr.Err == syscall.EXDEV {
+			return QuarantineEntry{}, fmt.Errorf("cannot quarantine %s: it is on a different filesystem than %s", path, q.Dir)
+		}
+		return QuarantineEntry{}, err
+	}
+
+	if linkTo != "" {
+		if err := os.Link(linkTo, path); err != nil {
+			// Возвращаем файл на место, чтобы не потерять его
+			os.Rename(filepath.Join(q.itemsDir(), entry.ID), path)
+			return QuarantineEntry{}, fmt.Errorf("cannot link %s to %s: %v", path, linkTo, err)
+		}
+	}
+
+	entries, err := q.load()
+	if err != nil {
+		return QuarantineEntry{}, err
+	}
+	if err := q.save(append(entries, entry)); err != nil {
+		return QuarantineEntry{}, err
+	}
+
+	return entry, nil
+}
+
+// List возвращает содержимое карантина
+func (q *Quarantine) List() ([]QuarantineEntry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.load()
+}
+
+// Restore возвращает запись из карантина на прежнее место
+func (q *Quarantine) Restore(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.load()
+	if err != nil {
+		return err
+	}
+
+	for i, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+
+		if entry.LinkedTo != "" && sameFile(entry.Original, entry.LinkedTo) {
+			// Убираем оставленную вместо файла жесткую ссылку, но только если
+			// пользователь не положил на ее место другой файл
+			os.Remove(entry.Original)
+		}
+		if _, err := os.Lstat(entry.Original); err == nil {
+			return fmt.Errorf("cannot restore %s: path already exists", entry.Original)
+		}
+		if err := os.MkdirAll(filepath.Dir(entry.Original), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(q.itemsDir(), entry.ID), entry.Original); err != nil {
+			return err
+		}
+
+		return q.save(append(entries[:i], entries[i+1:]...))
+	}
+
+	return fmt.Errorf("quarantine entry %s not found", id)
+}
+
+// sameFile возвращает true, если оба пути указывают на один и тот же файл
+func sameFile(a, b string) bool {
+	infoA, errA := os.Lstat(a)
+	infoB, errB := os.Lstat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// Purge окончательно удаляет записи старше olderThan и возвращает освобожденное место
+func (q *Quarantine) Purge(olderThan time.Duration) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.load()
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var kept []QuarantineEntry
+	var freed int64
+
+	for _, entry := range entries {
+		if entry.Added.After(cutoff) {
+			kept = append(kept, entry)
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(q.itemsDir(), entry.ID)); err != nil {
+			kept = append(kept, entry)
+			continue
+		}
+		freed += entry.Size
+	}
+
+	return freed, q.save(kept)
+}
+
+// checkDirs отказывается работать с каталогами карантина, подмененными
+// символическими ссылками: иначе удаление ушло бы за пределы карантина
+func (q *Quarantine) checkDirs() error {
+	for _, dir := range []string{q.Dir, q.itemsDir()} {
+		if info, err := os.Lstat(dir); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to use quarantine: %s is a symlink", dir)
+		}
+	}
+	return nil
+}
+
+func (q *Quarantine) load() ([]QuarantineEntry, error) {
+	if err := q.checkDirs(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(q.manifestPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []QuarantineEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("corrupted quarantine manifest: %v", err)
+	}
+	for _, entry := range entries {
+		if !validQuarantineID(entry.ID) {
+			return nil, fmt.Errorf("corrupted quarantine manifest: invalid entry id %q", entry.ID)
+		}
+	}
+	return entries, nil
+}
+
+func (q *Quarantine) save(entries []QuarantineEntry) error {
+	if err := os.MkdirAll(q.Dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Пишем через временный файл, чтобы не потерять манифест при сбое
+	tmp := q.manifestPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.manifestPath())
+}
+
+func (m *CleanupModule) quarantineRetention() time.Duration {
+	if m.QuarantineRetention > 0 {
+		return m.QuarantineRetention
+	}
+	return defaultQuarantineRetention
+}
+
+func (m *CleanupModule) previewQuarantine(homeDir string) []CleanupItem {
+	if !isCurrentHome(homeDir) {
+		return nil
+	}
+	entries, err := quarantineFor(homeDir).List()
+	if err != nil {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-m.quarantineRetention())
+	var items []CleanupItem
+	for _, entry := range entries {
+		if entry.Added.Before(cutoff) {
+			items = append(items, CleanupItem{Category: "Expired quarantine", Path: entry.Original, Size: entry.Size})
+		}
+	}
+	return items
+}
+
+// purgeQuarantine очищает только собственный карантин. Манифест чужого
+// карантина пишет его владелец, и root не должен удалять файлы по его указке:
+// пользователи очищают свой карантин сами, запуская очистку от своего имени
+func (m *CleanupModule) purgeQuarantine(homeDir string) (int64, error) {
+	if !isCurrentHome(homeDir) {
+		return 0, nil
+	}
+	return quarantineFor(homeDir).Purge(m.quarantineRetention())
+}
@@ -0,0 +1,234 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuarantine_AddRestore(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_quarantine_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	quarantine := NewQuarantine(filepath.Join(tempDir, "quarantine"))
+	file := filepath.Join(tempDir, "data", "big.iso")
+	os.MkdirAll(filepath.Dir(file), 0755)
+	os.WriteFile(file, make([]byte, 8192), 0644)
+
+	entry, err := quarantine.Add(file)
+	if err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+	if entry.Original != file || entry.Size == 0 {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("File should have been moved out of its original place")
+	}
+
+	entries, err := quarantine.List()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("List() = %v, %v; want one entry", entries, err)
+	}
+
+	if err := quarantine.Restore(entry.ID); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Error("File should have been restored")
+	}
+	if entries, _ := quarantine.List(); len(entries) != 0 {
+		t.Errorf("Quarantine should be empty after restore, got %d entries", len(entries))
+	}
+	if err := quarantine.Restore(entry.ID); err == nil {
+		t.Error("Restore() of unknown entry should fail")
+	}
+}
+
+func TestQuarantine_RefusesItself(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_quarantine_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	quarantine := NewQuarantine(filepath.Join(tempDir, "quarantine"))
+	os.MkdirAll(quarantine.Dir, 0755)
+
+	// Нельзя поместить в карантин каталог, который его содержит
+	if _, err := quarantine.Add(tempDir); err == nil {
+		t.Error("Add() of a parent of the quarantine should fail")
+	}
+	if _, err := quarantine.Add(quarantine.Dir); err == nil {
+		t.Error("Add() of the quarantine itself should fail")
+	}
+}
+
+func TestQuarantine_ReplaceWithLink(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_quarantine_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	quarantine := NewQuarantine(filepath.Join(tempDir, "quarantine"))
+	keep := filepath.Join(tempDir, "keep.jpg")
+	duplicate := filepath.Join(tempDir, "copy.jpg")
+	os.WriteFile(keep, []byte("photo"), 0644)
+	os.WriteFile(duplicate, []byte("photo"), 0644)
+
+	entry, err := quarantine.ReplaceWithLink(duplicate, keep)
+	if err != nil {
+		t.Skipf("Hardlinks not supported: %v", err)
+	}
+
+	keepInfo, _ := os.Stat(keep)
+	dupInfo, err := os.Stat(duplicate)
+	if err != nil || !os.SameFile(keepInfo, dupInfo) {
+		t.Error("Duplicate should have been replaced with a hardlink to the kept file")
+	}
+
+	// При восстановлении ссылка заменяется исходным файлом
+	if err := quarantine.Restore(entry.ID); err != nil {
+		t.Fatalf("Restore() returned error: %v", err)
+	}
+	dupInfo, _ = os.Stat(duplicate)
+	if os.SameFile(keepInfo, dupInfo) {
+		t.Error("Restored file should no longer be a hardlink")
+	}
+}
+
+func TestQuarantine_Purge(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_quarantine_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	quarantine := NewQuarantine(filepath.Join(tempDir, "quarantine"))
+	oldFile := filepath.Join(tempDir, "old.log")
+	newFile := filepath.Join(tempDir, "new.log")
+	os.WriteFile(oldFile, make([]byte, 4096), 0644)
+	os.WriteFile(newFile, make([]byte, 4096), 0644)
+
+	oldEntry, _ := quarantine.Add(oldFile)
+	quarantine.Add(newFile)
+
+	// Делаем первую запись старой
+	entries, _ := quarantine.List()
+	entries[0].Added = time.Now().Add(-10 * 24 * time.Hour)
+	quarantine.save(entries)
+
+	freed, err := quarantine.Purge(7 * 24 * time.Hour)
+	if err != nil {
+		t.Fatalf("Purge() returned error: %v", err)
+	}
+	if freed != oldEntry.Size {
+		t.Errorf("Purge() = %d, want %d", freed, oldEntry.Size)
+	}
+	if _, err := os.Stat(filepath.Join(quarantine.itemsDir(), oldEntry.ID)); !os.IsNotExist(err) {
+		t.Error("Expired entry should have been deleted")
+	}
+	if entries, _ := quarantine.List(); len(entries) != 1 || entries[0].Original != newFile {
+		t.Errorf("Only the fresh entry should remain, got %+v", entries)
+	}
+}
+
+func TestQuarantine_RestoreKeepsReplacedFile(t *testing.T) {
+	tempDir := t.TempDir()
+	quarantine := NewQuarantine(filepath.Join(tempDir, "quarantine"))
+	keep := filepath.Join(tempDir, "keep.jpg")
+	duplicate := filepath.Join(tempDir, "copy.jpg")
+	os.WriteFile(keep, []byte("photo"), 0644)
+	os.WriteFile(duplicate, []byte("photo"), 0644)
+
+	entry, err := quarantine.ReplaceWithLink(duplicate, keep)
+	if err != nil {
+		t.Skipf("Hardlinks not supported: %v", err)
+	}
+
+	// Пользователь заменил ссылку собственным файлом
+	os.Remove(duplicate)
+	os.WriteFile(duplicate, []byte("new work"), 0644)
+
+	if err := quarantine.Restore(entry.ID); err == nil {
+		t.Error("Restore() should refuse to overwrite a file that is no longer the hardlink")
+	}
+	if data, _ := os.ReadFile(duplicate); string(data) != "new work" {
+		t.Errorf("Replaced file was destroyed, content = %q", data)
+	}
+}
+
+func TestQuarantine_RejectsTraversalIDs(t *testing.T) {
+	tempDir := t.TempDir()
+	quarantine := NewQuarantine(filepath.Join(tempDir, "quarantine"))
+	victim := filepath.Join(tempDir, "victim")
+	os.MkdirAll(victim, 0755)
+
+	for _, id := range []string{"../../victim", "..", "", "items/../../victim"} {
+		quarantine.save([]QuarantineEntry{{ID: id, Original: victim, Added: time.Now().Add(-365 * 24 * time.Hour)}})
+
+		if _, err := quarantine.Purge(time.Hour); err == nil {
+			t.Errorf("Purge() accepted entry id %q", id)
+		}
+		if err := quarantine.Restore(id); err == nil {
+			t.Errorf("Restore() accepted entry id %q", id)
+		}
+		if _, err := os.Stat(victim); err != nil {
+			t.Fatalf("entry id %q removed a path outside the quarantine", id)
+		}
+	}
+}
+
+func TestQuarantine_RefusesSymlinkedDirs(t *testing.T) {
+	tempDir := t.TempDir()
+	target := filepath.Join(tempDir, "elsewhere")
+	os.MkdirAll(target, 0755)
+
+	linked := filepath.Join(tempDir, "linked")
+	os.Symlink(target, linked)
+	if _, err := NewQuarantine(linked).List(); err == nil {
+		t.Error("List() should refuse a quarantine that is a symlink")
+	}
+
+	dir := filepath.Join(tempDir, "quarantine")
+	os.MkdirAll(dir, 0700)
+	os.Symlink(target, filepath.Join(dir, "items"))
+	file := filepath.Join(tempDir, "file.txt")
+	os.WriteFile(file, []byte("data"), 0644)
+	if _, err := NewQuarantine(dir).Add(file); err == nil {
+		t.Error("Add() should refuse an items dir that is a symlink")
+	}
+	if _, err := NewQuarantine(dir).Purge(0); err == nil {
+		t.Error("Purge() should refuse an items dir that is a symlink")
+	}
+}
+
+func TestCleanupModule_PurgeQuarantineOnlyOwnHome(t *testing.T) {
+	otherHome := t.TempDir()
+	quarantine := quarantineFor(otherHome)
+	file := filepath.Join(otherHome, "file.txt")
+	os.WriteFile(file, make([]byte, 4096), 0644)
+	entry, err := quarantine.Add(file)
+	if err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+	entries, _ := quarantine.List()
+	entries[0].Added = time.Now().Add(-30 * 24 * time.Hour)
+	quarantine.save(entries)
+
+	module := &CleanupModule{}
+	if items := module.previewQuarantine(otherHome); len(items) != 0 {
+		t.Errorf("previewQuarantine() listed another user's quarantine: %+v", items)
+	}
+	if freed, _ := module.purgeQuarantine(otherHome); freed != 0 {
+		t.Errorf("purgeQuarantine() freed %d bytes from another user's quarantine", freed)
+	}
+	if _, err := os.Stat(filepath.Join(quarantine.itemsDir(), entry.ID)); err != nil {
+		t.Error("another user's quarantine should be left untouched")
+	}
+}
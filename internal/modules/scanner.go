@@ -0,0 +1,346 @@
+package modules
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DiskUsage - объем дерева каталогов
+type DiskUsage struct {
+	Apparent  int64 // сумма размеров файлов
+	Allocated int64 // реально занятые блоки, жесткие ссылки учтены один раз
+	Files     int64
+}
+
+func (u *DiskUsage) add(other DiskUsage) {
+	u.Apparent += other.Apparent
+	u.Allocated += other.Allocated
+	u.Files += other.Files
+}
+
+// ScanProgress - промежуточное состояние сканирования
+type ScanProgress struct {
+	Files int64
+	Bytes int64
+}
+
+// Scanner параллельно считает место, занимаемое деревьями каталогов.
+// Сканирование не выходит за пределы файловой системы корня, а результаты
+// по каждому каталогу кэшируются, чтобы предпросмотр и удаление не обходили
+// одно и то же дерево дважды
+type Scanner struct {
+	// Workers - сколько каталогов обходится параллельно (по умолчанию число CPU)
+	Workers int
+	// OnProgress вызывается периодически во время сканирования
+	OnProgress func(progress ScanProgress)
+	// OnFile вызывается для каждого учтенного файла. Вызовы идут из разных
+	// воркеров одновременно, а для каталогов из кэша не происходят вовсе
+	OnFile func(path string, usage DiskUsage)
+
+	mu    sync.Mutex
+	cache map[string]DiskUsage
+}
+
+// NewScanner создает сканер с пустым кэшем
+func NewScanner() *Scanner {
+	return &Scanner{cache: make(map[string]DiskUsage)}
+}
+
+// scanState - общее состояние одного вызова Scan
+type scanState struct {
+	ctx     context.Context
+	rootDev uint64
+	onFile  func(path string, usage DiskUsage)
+
+	jobs    chan string
+	pending sync.WaitGroup
+
+	mu   sync.Mutex
+	own  map[string]DiskUsage // размер файлов непосредственно в каталоге
+	seen map[inodeKey]bool
+
+	files atomic.Int64
+	bytes atomic.Int64
+
+	errOnce sync.Once
+	err     error
+}
+
+// Scan возвращает объем дерева root. Если контекст отменен, возвращается его ошибка,
+// а частичные результаты не кэшируются
+func (s *Scanner) Scan(ctx context.Context, root string) (DiskUsage, error) {
+	root = filepath.Clean(root)
+	if usage, ok := s.Cached(root); ok {
+		return usage, nil
+	}
+
+	info, err := os.Lstat(root)
+	if err != nil {
+		return DiskUsage{}, err
+	}
+	if !info.IsDir() {
+		usage := fileUsage(info)
+		s.store(map[string]DiskUsage{root: usage})
+		return usage, nil
+	}
+
+	state := &scanState{
+		ctx:     ctx,
+		rootDev: deviceOf(info),
+		onFile:  s.OnFile,
+		jobs:    make(chan string),
+		own:     make(map[string]DiskUsage),
+		seen:    make(map[inodeKey]bool),
+	}
+
+	done := make(chan struct{})
+	var reporter sync.WaitGroup
+	if s.OnProgress != nil {
+		reporter.Add(1)
+		go func() {
+			defer reporter.Done()
+			s.reportProgress(state, done)
+		}()
+	}
+
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	var workersDone sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workersDone.Add(1)
+		go func() {
+			defer workersDone.Done()
+			for dir := range state.jobs {
+				state.walk(dir)
+				state.pending.Done()
+			}
+		}()
+	}
+
+	state.pending.Add(1)
+	state.jobs <- root
+	state.pending.Wait()
+	close(state.jobs)
+	workersDone.Wait()
+	close(done)
+	reporter.Wait()
+
+	if s.OnProgress != nil {
+		s.OnProgress(ScanProgress{Files: state.files.Load(), Bytes: state.bytes.Load()})
+	}
+
+	if state.err != nil {
+		return DiskUsage{}, state.err
+	}
+
+	totals := aggregateUsage(root, state.own)
+	s.store(totals)
+	return totals[root], nil
+}
+
+// Cached возвращает результат из кэша, если каталог уже сканировался
+func (s *Scanner) Cached(path string) (DiskUsage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, ok := s.cache[filepath.Clean(path)]
+	return usage, ok
+}
+
+// Invalidate сбрасывает кэш для path, его подкаталогов и родителей.
+// Вызывается после изменения дерева на диске
+func (s *Scanner) Invalidate(path string) {
+	path = filepath.Clean(path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for cached := range s.cache {
+		if cached == path || isSubpath(cached, path) || isSubpath(path, cached) {
+			delete(s.cache, cached)
+		}
+	}
+}
+
+// Reset очищает кэш целиком, чтобы размеры не устаревали между запусками
+func (s *Scanner) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = make(map[string]DiskUsage)
+}
+
+func (s *Scanner) store(totals map[string]DiskUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cache == nil {
+		s.cache = make(map[string]DiskUsage)
+	}
+	for path, usage := range totals {
+		s.cache[path] = usage
+	}
+}
+
+func (s *Scanner) reportProgress(state *scanState, done chan struct{}) {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.OnProgress(ScanProgress{Files: state.files.Load(), Bytes: state.bytes.Load()})
+		}
+	}
+}
+
+// walk обходит поддерево dir. Подкаталоги отдаются свободным воркерам,
+// если такие есть, иначе обходятся в этом же воркере
+func (st *scanState) walk(dir string) {
+	own := make(map[string]DiskUsage)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := st.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil // Игнорируем ошибки доступа
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if deviceOf(info) != st.rootDev {
+				return filepath.SkipDir // Не переходим в другие файловые системы
+			}
+			if _, ok := own[path]; !ok {
+				own[path] = DiskUsage{}
+			}
+			if path != dir && st.handOff(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if deviceOf(info) != st.rootDev || !st.firstLink(info) {
+			return nil
+		}
+		usage := fileUsage(info)
+		parent := filepath.Dir(path)
+		current := own[parent]
+		current.add(usage)
+		own[parent] = current
+		if st.onFile != nil {
+			st.onFile(path, usage)
+		}
+
+		st.files.Add(1)
+		st.bytes.Add(usage.Allocated)
+		return nil
+	})
+
+	if err != nil {
+		st.errOnce.Do(func() { st.err = err })
+	}
+
+	st.mu.Lock()
+	for path, usage := range own {
+		current := st.own[path]
+		current.add(usage)
+		st.own[path] = current
+	}
+	st.mu.Unlock()
+}
+
+// handOff пытается передать каталог свободному воркеру
+func (st *scanState) handOff(dir string) bool {
+	st.pending.Add(1)
+	select {
+	case st.jobs <- dir:
+		return true
+	default:
+		st.pending.Done()
+		return false
+	}
+}
+
+// firstLink возвращает false, если файл с этим inode уже посчитан
+func (st *scanState) firstLink(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink <= 1 {
+		return true
+	}
+
+	key := inodeKey{dev: uint64(stat.Dev), ino: stat.Ino}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.seen[key] {
+		return false
+	}
+	st.seen[key] = true
+	return true
+}
+
+// aggregateUsage складывает размеры подкаталогов в родительские
+func aggregateUsage(root string, own map[string]DiskUsage) map[string]DiskUsage {
+	paths := make([]string, 0, len(own))
+	for path := range own {
+		paths = append(paths, path)
+	}
+	// Путь потомка всегда длиннее пути предка, поэтому потомки обрабатываются первыми
+	sort.Slice(paths, func(i, j int) bool { return len(paths[i]) > len(paths[j]) })
+
+	totals := make(map[string]DiskUsage, len(own))
+	for path, usage := range own {
+		totals[path] = usage
+	}
+	for _, path := range paths {
+		if path == root {
+			continue
+		}
+		parent := filepath.Dir(path)
+		total := totals[parent]
+		total.add(totals[path])
+		totals[parent] = total
+	}
+	return totals
+}
+
+// fileUsage возвращает объем одного файла
+func fileUsage(info os.FileInfo) DiskUsage {
+	usage := DiskUsage{Apparent: info.Size(), Allocated: info.Size(), Files: 1}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		// st_blocks всегда считается в 512-байтных блоках
+		usage.Allocated = stat.Blocks * 512
+	}
+	return usage
+}
+
+func deviceOf(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev)
+	}
+	return 0
+}
+
+// isSubpath возвращает true, если path лежит внутри dir
+func isSubpath(path, dir string) bool {
+	if dir == "/" {
+		return path != "/"
+	}
+	return strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
@@ -0,0 +1,159 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// makeTree создает дерево каталогов с файлами по 4 КБ и возвращает число файлов
+func makeTree(t *testing.T, root string, depth, width int) int {
+	t.Helper()
+	count := 0
+	for i := 0; i < width; i++ {
+		file := filepath.Join(root, fmt.Sprintf("file%d.dat", i))
+		if err := os.WriteFile(file, make([]byte, 4096), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		count++
+		if depth > 0 {
+			dir := filepath.Join(root, fmt.Sprintf("dir%d", i))
+			os.MkdirAll(dir, 0755)
+			count += makeTree(t, dir, depth-1, width)
+		}
+	}
+	return count
+}
+
+func TestScanner_Scan(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_scan_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := makeTree(t, root, 3, 3)
+
+	// Результат не должен зависеть от числа воркеров
+	for _, workers := range []int{1, 8} {
+		scanner := &Scanner{Workers: workers}
+		usage, err := scanner.Scan(context.Background(), root)
+		if err != nil {
+			t.Fatalf("Scan() returned error: %v", err)
+		}
+		if usage.Files != int64(files) {
+			t.Errorf("workers=%d: Files = %d, want %d", workers, usage.Files, files)
+		}
+		if usage.Apparent != int64(files)*4096 {
+			t.Errorf("workers=%d: Apparent = %d, want %d", workers, usage.Apparent, int64(files)*4096)
+		}
+
+		// Подкаталоги тоже кэшируются и согласованы с корнем
+		sub, ok := scanner.Cached(filepath.Join(root, "dir0"))
+		if !ok {
+			t.Errorf("workers=%d: subdirectory should be cached", workers)
+		}
+		if sub.Files*3+3 != usage.Files {
+			t.Errorf("workers=%d: subdirectory files = %d, inconsistent with root %d", workers, sub.Files, usage.Files)
+		}
+	}
+}
+
+func TestScanner_CacheAndInvalidate(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_scan_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	makeTree(t, root, 1, 2)
+	scanner := NewScanner()
+	first, _ := scanner.Scan(context.Background(), root)
+
+	// Новый файл не виден, пока кэш не сброшен
+	os.WriteFile(filepath.Join(root, "dir1", "new.dat"), make([]byte, 4096), 0644)
+	cached, _ := scanner.Scan(context.Background(), root)
+	if cached != first {
+		t.Errorf("Second scan should be served from cache: %+v != %+v", cached, first)
+	}
+
+	scanner.Invalidate(filepath.Join(root, "dir1"))
+	if _, ok := scanner.Cached(root); ok {
+		t.Error("Invalidate should drop parent directories from cache")
+	}
+	if _, ok := scanner.Cached(filepath.Join(root, "dir0")); !ok {
+		t.Error("Invalidate should keep unrelated directories in cache")
+	}
+
+	fresh, _ := scanner.Scan(context.Background(), root)
+	if fresh.Files != first.Files+1 {
+		t.Errorf("Files after invalidate = %d, want %d", fresh.Files, first.Files+1)
+	}
+
+	// Reset забывает все каталоги, даже не затронутые Invalidate
+	os.WriteFile(filepath.Join(root, "dir0", "new.dat"), make([]byte, 4096), 0644)
+	scanner.Reset()
+	if _, ok := scanner.Cached(filepath.Join(root, "dir0")); ok {
+		t.Error("Reset should drop every cached directory")
+	}
+	if again, _ := scanner.Scan(context.Background(), root); again.Files != first.Files+2 {
+		t.Errorf("Files after reset = %d, want %d", again.Files, first.Files+2)
+	}
+}
+
+func TestScanner_Cancelled(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_scan_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	makeTree(t, root, 2, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scanner := NewScanner()
+	if _, err := scanner.Scan(ctx, root); err != context.Canceled {
+		t.Errorf("Scan() with cancelled context returned %v, want context.Canceled", err)
+	}
+	if _, ok := scanner.Cached(root); ok {
+		t.Error("Partial results should not be cached")
+	}
+}
+
+func TestScanner_Progress(t *testing.T) {
+	root, err := os.MkdirTemp("", "ububu_scan_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := makeTree(t, root, 2, 2)
+
+	var calls atomic.Int64
+	var last ScanProgress
+	scanner := NewScanner()
+	scanner.OnProgress = func(progress ScanProgress) {
+		calls.Add(1)
+		last = progress
+	}
+	scanner.Scan(context.Background(), root)
+
+	if calls.Load() == 0 {
+		t.Error("OnProgress was never called")
+	}
+	if last.Files != int64(files) {
+		t.Errorf("Final progress files = %d, want %d", last.Files, files)
+	}
+}
+
+func TestScanner_NonExistent(t *testing.T) {
+	scanner := NewScanner()
+	if _, err := scanner.Scan(context.Background(), "/non/existent/directory"); !os.IsNotExist(err) {
+		t.Errorf("Scan() of missing path returned %v, want not-exist error", err)
+	}
+}
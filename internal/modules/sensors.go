@@ -0,0 +1,241 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	hwmonDir   = "/sys/class/hwmon"
+	thermalDir = "/sys/class/thermal"
+	// sensorCritMargin - за сколько градусов до критической температуры
+	// датчик считается перегретым, если его порог max не ниже критического
+	sensorCritMargin = 10
+)
+
+// Виды датчиков
+const (
+	SensorTemp = "temp"
+	SensorFan  = "fan"
+)
+
+// sensorGroups сопоставляет драйверы hwmon с видом устройства
+var sensorGroups = map[string]string{
+	"coretemp": "CPU", "k10temp": "CPU", "zenpower": "CPU", "cpu_thermal": "CPU",
+	"nvme": "NVMe", "drivetemp": "Disk",
+	"amdgpu": "GPU", "radeon": "GPU", "nouveau": "GPU", "i915": "GPU", "xe": "GPU",
+}
+
+// Sensor - показание одного датчика
+type Sensor struct {
+	// Chip - драйвер hwmon или тип thermal zone, например coretemp или acpitz
+	Chip string
+	// Device - устройство, к которому относится датчик, например nvme0
+	Device string
+	Label  string
+	Kind   string
+	// Value - градусы Цельсия или обороты в минуту
+	Value float64
+	// Max и Crit - пороги датчика, 0 если неизвестны. Для вентиляторов Max - минимальные обороты
+	Max  float64
+	Crit float64
+}
+
+// Name возвращает понятное имя датчика, например "CPU Package id 0"
+func (s Sensor) Name() string {
+	group, known := sensorGroups[s.Chip]
+	if !known {
+		return s.Chip + " " + s.Label
+	}
+	// Дисков и видеокарт может быть несколько, различаем их по устройству
+	if s.Device != "" && group != "CPU" {
+		group += " " + s.Device
+	}
+	return group + " " + s.Label
+}
+
+// Severity оценивает показание по собственным порогам датчика
+func (s Sensor) Severity() Severity {
+	if s.Kind == SensorFan {
+		if s.Max > 0 && s.Value < s.Max {
+			return SeverityWarning
+		}
+		return SeverityInfo
+	}
+
+	switch {
+	case s.Crit > 0 && s.Value >= s.Crit:
+		return SeverityCritical
+	case s.Max > 0 && (s.Crit == 0 || s.Max < s.Crit) && s.Value >= s.Max:
+		return SeverityWarning
+	case s.Crit > 0 && s.Value >= s.Crit-sensorCritMargin:
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+// String описывает датчик одной строкой для отчета
+func (s Sensor) String() string {
+	status := "✅"
+	switch s.Severity() {
+	case SeverityCritical:
+		status = "🔥"
+	case SeverityWarning:
+		status = "⚠️"
+	}
+
+	if s.Kind == SensorFan {
+		line := fmt.Sprintf("%s %s: %.0f RPM", status, s.Name(), s.Value)
+		if s.Max > 0 {
+			line += fmt.Sprintf(" (min %.0f)", s.Max)
+		}
+		return line
+	}
+
+	line := fmt.Sprintf("%s %s: %.0f°C", status, s.Name(), s.Value)
+	var limits []string
+	if s.Max > 0 {
+		limits = append(limits, fmt.Sprintf("high %.0f°C", s.Max))
+	}
+	if s.Crit > 0 {
+		limits = append(limits, fmt.Sprintf("crit %.0f°C", s.Crit))
+	}
+	if len(limits) > 0 {
+		line += " (" + strings.Join(limits, ", ") + ")"
+	}
+	return line
+}
+
+// readSysValue читает число из файла sysfs
+func readSysValue(path string) (float64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	return value, err == nil
+}
+
+func readSysString(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readHwmon читает датчики температуры и вентиляторы всех устройств hwmon
+func readHwmon(root string) []Sensor {
+	chips, _ := filepath.Glob(filepath.Join(root, "hwmon*"))
+
+	var sensors []Sensor
+	for _, chip := range chips {
+		name := readSysString(filepath.Join(chip, "name"))
+		device := ""
+		if target, err := os.Readlink(filepath.Join(chip, "device")); err == nil {
+			device = filepath.Base(target)
+		}
+
+		inputs, _ := filepath.Glob(filepath.Join(chip, "*_input"))
+		sort.Strings(inputs)
+		for _, input := range inputs {
+			prefix := strings.TrimSuffix(filepath.Base(input), "_input")
+			kind := strings.TrimRight(prefix, "0123456789")
+			if kind != SensorTemp && kind != SensorFan {
+				continue
+			}
+			value, ok := readSysValue(input)
+			if !ok {
+				continue
+			}
+
+			sensor := Sensor{Chip: name, Device: device, Kind: kind, Value: value, Label: prefix}
+			if label := readSysString(filepath.Join(chip, prefix+"_label")); label != "" {
+				sensor.Label = label
+			}
+			if kind == SensorTemp {
+				// Температуры в sysfs хранятся в миллиградусах
+				sensor.Value /= 1000
+				if max, ok := readSysValue(filepath.Join(chip, prefix+"_max")); ok {
+					sensor.Max = max / 1000
+				}
+				if crit, ok := readSysValue(filepath.Join(chip, prefix+"_crit")); ok {
+					sensor.Crit = crit / 1000
+				}
+			} else if min, ok := readSysValue(filepath.Join(chip, prefix+"_min")); ok {
+				sensor.Max = min
+			}
+			sensors = append(sensors, sensor)
+		}
+	}
+
+	return sensors
+}
+
+// readThermalZones читает thermal zones. Порогами служат точки срабатывания
+// типа hot и critical
+func readThermalZones(root string) []Sensor {
+	zones, _ := filepath.Glob(filepath.Join(root, "thermal_zone*"))
+	sort.Strings(zones)
+
+	var sensors []Sensor
+	for _, zone := range zones {
+		temp, ok := readSysValue(filepath.Join(zone, "temp"))
+		if !ok {
+			continue
+		}
+		zoneType := readSysString(filepath.Join(zone, "type"))
+		sensor := Sensor{Chip: zoneType, Label: filepath.Base(zone), Kind: SensorTemp, Value: temp / 1000}
+
+		trips, _ := filepath.Glob(filepath.Join(zone, "trip_point_*_type"))
+		for _, trip := range trips {
+			limit, ok := readSysValue(strings.TrimSuffix(trip, "_type") + "_temp")
+			if !ok || limit <= 0 {
+				continue
+			}
+			switch readSysString(trip) {
+			case "critical":
+				sensor.Crit = limit / 1000
+			case "hot":
+				sensor.Max = limit / 1000
+			}
+		}
+		sensors = append(sensors, sensor)
+	}
+
+	return sensors
+}
+
+// ReadSensors собирает все датчики hwmon и thermal zones. Зоны, которые уже
+// видны через hwmon (например acpitz), не дублируются
+func ReadSensors() []Sensor {
+	sensors := readHwmon(hwmonDir)
+
+	chips := make(map[string]bool)
+	for _, sensor := range sensors {
+		chips[sensor.Chip] = true
+	}
+	for _, zone := range readThermalZones(thermalDir) {
+		if !chips[zone.Chip] {
+			sensors = append(sensors, zone)
+		}
+	}
+
+	return sensors
+}
+
+// hottestSensor возвращает самый горячий датчик температуры
+func hottestSensor(sensors []Sensor) (Sensor, bool) {
+	var hottest Sensor
+	found := false
+	for _, sensor := range sensors {
+		if sensor.Kind == SensorTemp && (!found || sensor.Value > hottest.Value) {
+			hottest, found = sensor, true
+		}
+	}
+	return hottest, found
+}
@@ -0,0 +1,109 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSysFiles создает файлы sysfs с заданным содержимым
+func writeSysFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	os.MkdirAll(dir, 0755)
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestReadHwmon(t *testing.T) {
+	root := t.TempDir()
+	writeSysFiles(t, filepath.Join(root, "hwmon0"), map[string]string{
+		"name":        "coretemp",
+		"temp1_input": "95000",
+		"temp1_label": "Package id 0",
+		"temp1_max":   "100000",
+		"temp1_crit":  "100000",
+		"temp2_input": "48000",
+		"temp2_label": "Core 0",
+		"temp2_max":   "100000",
+		"temp2_crit":  "100000",
+	})
+	writeSysFiles(t, filepath.Join(root, "hwmon1"), map[string]string{
+		"name":        "nvme",
+		"temp1_input": "72850",
+		"temp1_label": "Composite",
+		"temp1_max":   "70850",
+		"temp1_crit":  "84850",
+	})
+	os.Symlink("../../nvme0", filepath.Join(root, "hwmon1", "device"))
+	writeSysFiles(t, filepath.Join(root, "hwmon2"), map[string]string{
+		"name":       "thinkpad",
+		"fan1_input": "300",
+		"fan1_min":   "1200",
+		"in0_input":  "12000",
+	})
+
+	sensors := readHwmon(root)
+	if len(sensors) != 4 {
+		t.Fatalf("readHwmon() returned %d sensors, want 4: %+v", len(sensors), sensors)
+	}
+
+	pkg := sensors[0]
+	if pkg.Name() != "CPU Package id 0" || pkg.Value != 95 || pkg.Crit != 100 {
+		t.Errorf("Unexpected CPU sensor: %+v", pkg)
+	}
+	if pkg.Severity() != SeverityWarning {
+		t.Errorf("CPU at 95°C with crit 100°C should be a warning, got %v", pkg.Severity())
+	}
+	if sensors[1].Severity() != SeverityInfo {
+		t.Errorf("Core at 48°C should be fine, got %v", sensors[1].Severity())
+	}
+
+	nvme := sensors[2]
+	if nvme.Name() != "NVMe nvme0 Composite" || nvme.Severity() != SeverityWarning {
+		t.Errorf("NVMe sensor %q above its max should be a warning, got %v", nvme.Name(), nvme.Severity())
+	}
+
+	fan := sensors[3]
+	if fan.Kind != SensorFan || fan.Value != 300 || fan.Severity() != SeverityWarning {
+		t.Errorf("Fan below its minimum should be a warning: %+v", fan)
+	}
+	if line := fan.String(); !strings.Contains(line, "300 RPM (min 1200)") {
+		t.Errorf("Fan String() = %q", line)
+	}
+}
+
+func TestReadThermalZones(t *testing.T) {
+	root := t.TempDir()
+	writeSysFiles(t, filepath.Join(root, "thermal_zone0"), map[string]string{
+		"type":              "x86_pkg_temp",
+		"temp":              "105000",
+		"trip_point_0_type": "passive",
+		"trip_point_0_temp": "90000",
+		"trip_point_1_type": "critical",
+		"trip_point_1_temp": "105000",
+	})
+	writeSysFiles(t, filepath.Join(root, "thermal_zone1"), map[string]string{
+		"type": "iwlwifi_1",
+		"temp": "41000",
+	})
+
+	zones := readThermalZones(root)
+	if len(zones) != 2 {
+		t.Fatalf("readThermalZones() returned %d zones, want 2", len(zones))
+	}
+	if zones[0].Crit != 105 || zones[0].Severity() != SeverityCritical {
+		t.Errorf("Zone at its critical trip point should be critical: %+v", zones[0])
+	}
+	if zones[1].Severity() != SeverityInfo {
+		t.Errorf("Zone without thresholds should not be judged: %+v", zones[1])
+	}
+
+	hottest, ok := hottestSensor(zones)
+	if !ok || hottest.Chip != "x86_pkg_temp" {
+		t.Errorf("hottestSensor() = %+v, %v", hottest, ok)
+	}
+}
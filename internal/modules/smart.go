@@ -0,0 +1,290 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const sysBlockDir = "/sys/block"
+
+// DriveHealth - состояние накопителя по данным SMART
+type DriveHealth struct {
+	// Device - путь к устройству, например /dev/nvme0n1
+	Device   string
+	Model    string
+	Protocol string // ATA, NVMe или SCSI
+	// StatusKnown - smartctl сообщил общую оценку SMART
+	StatusKnown bool
+	Passed      bool
+	// ReallocatedSectors, PendingSectors и UncorrectableSectors - атрибуты 5, 197 и 198 (ATA)
+	ReallocatedSectors   int64
+	PendingSectors       int64
+	UncorrectableSectors int64
+	// MediaErrors - неисправимые ошибки данных (NVMe)
+	MediaErrors int64
+	// CriticalWarning - битовая маска критических предупреждений NVMe
+	CriticalWarning int
+	// AvailableSpare и SpareThreshold - резерв NVMe в процентах
+	AvailableSpare int
+	SpareThreshold int
+	// WearUsed - израсходованный ресурс записи в процентах, -1 если неизвестен
+	WearUsed     int
+	PowerOnHours int64
+	// Temperature - температура в градусах, 0 если неизвестна
+	Temperature int
+}
+
+// smartctlOutput - нужная часть вывода smartctl --json
+type smartctlOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String string `json:"string"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	Device struct {
+		Name     string `json:"name"`
+		Protocol string `json:"protocol"`
+	} `json:"device"`
+	ModelName   string `json:"model_name"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime struct {
+		Hours int64 `json:"hours"`
+	} `json:"power_on_time"`
+	ATASmartAttributes struct {
+		Table []struct {
+			ID    int `json:"id"`
+			Value int `json:"value"`
+			Raw   struct {
+				Value int64 `json:"value"`
+			} `json:"raw"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+	NVMeLog *struct {
+		CriticalWarning         int   `json:"critical_warning"`
+		AvailableSpare          int   `json:"available_spare"`
+		AvailableSpareThreshold int   `json:"available_spare_threshold"`
+		PercentageUsed          int   `json:"percentage_used"`
+		MediaErrors             int64 `json:"media_errors"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// Атрибуты ATA, нормализованное значение которых - остаток ресурса в процентах
+var ataWearAttributes = map[int]bool{
+	177: true, // Wear_Leveling_Count
+	202: true, // Percent_Lifetime_Remain
+	231: true, // SSD_Life_Left
+	233: true, // Media_Wearout_Indicator
+}
+
+// parseSmartctl разбирает вывод smartctl --json
+func parseSmartctl(data []byte) (DriveHealth, error) {
+	var output smartctlOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return DriveHealth{}, err
+	}
+	// Биты 0 и 1 кода выхода: ошибка командной строки или устройство не открылось.
+	// Остальные биты сообщают о проблемах диска, данные при этом есть
+	if output.Smartctl.ExitStatus&3 != 0 {
+		message := "smartctl failed"
+		if len(output.Smartctl.Messages) > 0 {
+			message = output.Smartctl.Messages[0].String
+		}
+		return DriveHealth{}, fmt.Errorf("%s", message)
+	}
+
+	drive := DriveHealth{
+		Device:       output.Device.Name,
+		Model:        output.ModelName,
+		Protocol:     output.Device.Protocol,
+		WearUsed:     -1,
+		PowerOnHours: output.PowerOnTime.Hours,
+		Temperature:  output.Temperature.Current,
+	}
+	if output.SmartStatus != nil {
+		drive.StatusKnown = true
+		drive.Passed = output.SmartStatus.Passed
+	}
+
+	for _, attr := range output.ATASmartAttributes.Table {
+		switch {
+		case attr.ID == 5:
+			drive.ReallocatedSectors = attr.Raw.Value
+		case attr.ID == 197:
+			drive.PendingSectors = attr.Raw.Value
+		case attr.ID == 198:
+			drive.UncorrectableSectors = attr.Raw.Value
+		case ataWearAttributes[attr.ID] && attr.Value <= 100:
+			drive.WearUsed = 100 - attr.Value
+		}
+	}
+
+	if nvme := output.NVMeLog; nvme != nil {
+		drive.CriticalWarning = nvme.CriticalWarning
+		drive.AvailableSpare = nvme.AvailableSpare
+		drive.SpareThreshold = nvme.AvailableSpareThreshold
+		drive.WearUsed = nvme.PercentageUsed
+		drive.MediaErrors = nvme.MediaErrors
+	}
+
+	return drive, nil
+}
+
+// Findings оценивает атрибуты SMART и возвращает проблемы накопителя
+func (d DriveHealth) Findings() []Finding {
+	var findings []Finding
+	add := func(severity Severity, format string, args ...interface{}) {
+		findings = append(findings, Finding{Severity: severity, Message: d.Name() + ": " + fmt.Sprintf(format, args...)})
+	}
+
+	if d.StatusKnown && !d.Passed {
+		add(SeverityCritical, "SMART overall health test FAILED, back up your data")
+	}
+	if d.CriticalWarning != 0 {
+		add(SeverityCritical, "NVMe critical warning 0x%02x", d.CriticalWarning)
+	}
+	if d.PendingSectors > 0 {
+		add(SeverityCritical, "%d sectors pending reallocation", d.PendingSectors)
+	}
+	if d.UncorrectableSectors > 0 {
+		add(SeverityCritical, "%d uncorrectable sectors", d.UncorrectableSectors)
+	}
+	if d.MediaErrors > 0 {
+		add(SeverityCritical, "%d media errors", d.MediaErrors)
+	}
+	if d.ReallocatedSectors > 0 {
+		add(SeverityWarning, "%d reallocated sectors", d.ReallocatedSectors)
+	}
+	if d.SpareThreshold > 0 && d.AvailableSpare < d.SpareThreshold {
+		add(SeverityCritical, "spare capacity %d%% is below threshold %d%%", d.AvailableSpare, d.SpareThreshold)
+	}
+	switch {
+	case d.WearUsed >= 90:
+		add(SeverityCritical, "%d%% of rated write endurance used", d.WearUsed)
+	case d.WearUsed >= 80:
+		add(SeverityWarning, "%d%% of rated write endurance used", d.WearUsed)
+	}
+	switch {
+	case d.Temperature >= 70:
+		add(SeverityCritical, "temperature %d°C", d.Temperature)
+	case d.Temperature >= 60:
+		add(SeverityWarning, "temperature %d°C", d.Temperature)
+	}
+
+	return findings
+}
+
+// Name возвращает короткое имя устройства, например nvme0n1
+func (d DriveHealth) Name() string {
+	return filepath.Base(d.Device)
+}
+
+// String описывает накопитель одной строкой для отчета
+func (d DriveHealth) String() string {
+	status := "✅ GOOD"
+	if findings := d.Findings(); len(findings) > 0 {
+		status = worstSeverity(findings).String()
+	}
+
+	line := fmt.Sprintf("%s: %s", status, d.Name())
+	if d.Model != "" {
+		line += " (" + d.Model + ")"
+	}
+	if d.PowerOnHours > 0 {
+		line += fmt.Sprintf(" • %d h powered on", d.PowerOnHours)
+	}
+	if d.Temperature > 0 {
+		line += fmt.Sprintf(" • %d°C", d.Temperature)
+	}
+	if d.WearUsed >= 0 {
+		line += fmt.Sprintf(" • wear %d%%", d.WearUsed)
+	}
+	return line
+}
+
+// blockDevices возвращает физические накопители из sysRoot (/sys/block):
+// виртуальные устройства (loop, zram, device-mapper) и оптические приводы пропускаются
+func blockDevices(sysRoot string) []string {
+	entries, err := os.ReadDir(sysRoot)
+	if err != nil {
+		return nil
+	}
+
+	var devices []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, "sr") {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(sysRoot, name))
+		if err == nil && strings.Contains(target, "/virtual/") {
+			continue
+		}
+		devices = append(devices, "/dev/"+name)
+	}
+
+	sort.Strings(devices)
+	return devices
+}
+
+// readSmart запускает smartctl для устройства. Без прав root пробует sudo
+// без запроса пароля
+func readSmart(device string) (DriveHealth, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	args := []string{"--json", "-a", device}
+	cmd := exec.CommandContext(ctx, "smartctl", args...)
+	if os.Geteuid() != 0 {
+		cmd = exec.CommandContext(ctx, "sudo", append([]string{"-n", "smartctl"}, args...)...)
+	}
+	// smartctl завершается с ненулевым кодом и при найденных проблемах
+	output, err := cmd.Output()
+	if len(output) == 0 {
+		if err == nil {
+			err = fmt.Errorf("empty smartctl output")
+		}
+		return DriveHealth{}, err
+	}
+
+	drive, err := parseSmartctl(output)
+	if err != nil {
+		return DriveHealth{}, err
+	}
+	if drive.Device == "" {
+		drive.Device = device
+	}
+	return drive, nil
+}
+
+// CheckDrives читает SMART всех физических накопителей. Возвращает ошибку,
+// если smartctl не установлен или без прав root не удалось прочитать ни один диск
+func CheckDrives() ([]DriveHealth, error) {
+	if _, err := exec.LookPath("smartctl"); err != nil {
+		return nil, fmt.Errorf("smartctl not installed (apt install smartmontools)")
+	}
+
+	devices := blockDevices(sysBlockDir)
+	var drives []DriveHealth
+	for _, device := range devices {
+		if drive, err := readSmart(device); err == nil {
+			drives = append(drives, drive)
+		}
+	}
+	if len(drives) == 0 && len(devices) > 0 && os.Geteuid() != 0 {
+		return nil, fmt.Errorf("reading SMART data requires root")
+	}
+	return drives, nil
+}
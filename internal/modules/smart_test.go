@@ -0,0 +1,114 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSmartATA = `{
+  "smartctl": {"exit_status": 0},
+  "device": {"name": "/dev/sda", "protocol": "ATA"},
+  "model_name": "Samsung SSD 860 EVO 500GB",
+  "smart_status": {"passed": true},
+  "temperature": {"current": 35},
+  "power_on_time": {"hours": 21034},
+  "ata_smart_attributes": {"table": [
+    {"id": 5, "name": "Reallocated_Sector_Ct", "value": 99, "raw": {"value": 12}},
+    {"id": 9, "name": "Power_On_Hours", "value": 95, "raw": {"value": 21034}},
+    {"id": 177, "name": "Wear_Leveling_Count", "value": 15, "raw": {"value": 1500}},
+    {"id": 197, "name": "Current_Pending_Sector", "value": 100, "raw": {"value": 0}}
+  ]}
+}`
+
+const testSmartNVMe = `{
+  "smartctl": {"exit_status": 8},
+  "device": {"name": "/dev/nvme0", "protocol": "NVMe"},
+  "model_name": "WDC PC SN530",
+  "smart_status": {"passed": false},
+  "temperature": {"current": 64},
+  "power_on_time": {"hours": 812},
+  "nvme_smart_health_information_log": {
+    "critical_warning": 4,
+    "available_spare": 5,
+    "available_spare_threshold": 10,
+    "percentage_used": 3,
+    "media_errors": 2
+  }
+}`
+
+func TestParseSmartctl_ATA(t *testing.T) {
+	drive, err := parseSmartctl([]byte(testSmartATA))
+	if err != nil {
+		t.Fatalf("parseSmartctl() returned error: %v", err)
+	}
+	if drive.Name() != "sda" || !drive.StatusKnown || !drive.Passed || drive.PowerOnHours != 21034 || drive.Temperature != 35 {
+		t.Errorf("Unexpected drive: %+v", drive)
+	}
+	if drive.ReallocatedSectors != 12 || drive.PendingSectors != 0 {
+		t.Errorf("Sectors = %d reallocated, %d pending; want 12, 0", drive.ReallocatedSectors, drive.PendingSectors)
+	}
+	if drive.WearUsed != 85 {
+		t.Errorf("WearUsed = %d, want 85", drive.WearUsed)
+	}
+
+	findings := drive.Findings()
+	if len(findings) != 2 || worstSeverity(findings) != SeverityWarning {
+		t.Errorf("Findings() = %v, want reallocated sectors and wear warnings", findings)
+	}
+	if line := drive.String(); !strings.Contains(line, "WARNING: sda (Samsung SSD 860 EVO 500GB)") || !strings.Contains(line, "wear 85%") {
+		t.Errorf("String() = %q", line)
+	}
+}
+
+func TestParseSmartctl_NVMe(t *testing.T) {
+	drive, err := parseSmartctl([]byte(testSmartNVMe))
+	if err != nil {
+		t.Fatalf("parseSmartctl() returned error: %v", err)
+	}
+	if drive.Passed || drive.MediaErrors != 2 || drive.WearUsed != 3 || drive.CriticalWarning != 4 {
+		t.Errorf("Unexpected drive: %+v", drive)
+	}
+
+	var messages []string
+	for _, finding := range drive.Findings() {
+		if finding.Severity == SeverityCritical {
+			messages = append(messages, finding.Message)
+		}
+	}
+	got := strings.Join(messages, "; ")
+	for _, want := range []string{"FAILED", "critical warning 0x04", "2 media errors", "below threshold"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Critical findings %q should mention %q", got, want)
+		}
+	}
+}
+
+func TestParseSmartctl_OpenFailed(t *testing.T) {
+	data := `{"smartctl": {"exit_status": 2, "messages": [{"string": "Permission denied", "severity": "error"}]}}`
+	if _, err := parseSmartctl([]byte(data)); err == nil || !strings.Contains(err.Error(), "Permission denied") {
+		t.Errorf("parseSmartctl() error = %v, want smartctl message", err)
+	}
+}
+
+func TestBlockDevices(t *testing.T) {
+	sysRoot := t.TempDir()
+	links := map[string]string{
+		"sda":     "../devices/pci0000:00/0000:00:17.0/ata1/host0/target0:0:0/0:0:0:0/block/sda",
+		"nvme0n1": "../devices/pci0000:00/0000:00:1d.0/0000:3d:00.0/nvme/nvme0/nvme0n1",
+		"loop0":   "../devices/virtual/block/loop0",
+		"zram0":   "../devices/virtual/block/zram0",
+		"sr0":     "../devices/pci0000:00/0000:00:17.0/ata2/host1/target1:0:0/1:0:0:0/block/sr0",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(sysRoot, name)); err != nil {
+			t.Fatalf("Failed to create link: %v", err)
+		}
+	}
+
+	devices := blockDevices(sysRoot)
+	if got := strings.Join(devices, " "); got != "/dev/nvme0n1 /dev/sda" {
+		t.Errorf("blockDevices() = %q, want only physical drives", got)
+	}
+}
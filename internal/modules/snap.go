@@ -0,0 +1,138 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const snapStoreDir = "/var/lib/snapd/snaps"
+
+// snapRevision - одна установленная ревизия snap-пакета
+type snapRevision struct {
+	Name     string
+	Revision int
+	Disabled bool
+}
+
+// parseSnapList разбирает вывод `snap list --all`
+func parseSnapList(output string) []snapRevision {
+	var revisions []snapRevision
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines[1:] { // Пропускаем заголовок
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		revision, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		// Notes всегда последняя колонка, "disabled" стоит в ней через запятую
+		notes := fields[len(fields)-1]
+		revisions = append(revisions, snapRevision{
+			Name:     fields[0],
+			Revision: revision,
+			Disabled: strings.Contains(notes, "disabled"),
+		})
+	}
+
+	return revisions
+}
+
+// selectSnapRemovals выбирает отключенные ревизии к удалению, оставляя
+// для каждого пакета retain самых новых отключенных ревизий для отката
+func selectSnapRemovals(revisions []snapRevision, retain int) []snapRevision {
+	disabled := make(map[string][]snapRevision)
+	for _, rev := range revisions {
+		if rev.Disabled {
+			disabled[rev.Name] = append(disabled[rev.Name], rev)
+		}
+	}
+
+	var removals []snapRevision
+	for _, revs := range disabled {
+		sort.Slice(revs, func(i, j int) bool { return revs[i].Revision > revs[j].Revision })
+		if len(revs) > retain {
+			removals = append(removals, revs[retain:]...)
+		}
+	}
+
+	sort.Slice(removals, func(i, j int) bool {
+		if removals[i].Name != removals[j].Name {
+			return removals[i].Name < removals[j].Name
+		}
+		return removals[i].Revision < removals[j].Revision
+	})
+	return removals
+}
+
+// snapRevisionSize возвращает размер файла ревизии в хранилище snapd
+func snapRevisionSize(rev snapRevision) int64 {
+	info, err := os.Stat(filepath.Join(snapStoreDir, fmt.Sprintf("%s_%d.snap", rev.Name, rev.Revision)))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func (m *CleanupModule) disabledSnapRevisions() ([]snapRevision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "snap", "list", "--all").Output()
+	if err != nil {
+		return nil, err
+	}
+	return selectSnapRemovals(parseSnapList(string(output)), m.SnapRetain), nil
+}
+
+func (m *CleanupModule) previewSnapRevisions() []CleanupItem {
+	removals, err := m.disabledSnapRevisions()
+	if err != nil {
+		return nil
+	}
+
+	var items []CleanupItem
+	for _, rev := range removals {
+		items = append(items, CleanupItem{
+			Category: "Disabled snap revision",
+			Path:     fmt.Sprintf("%s (rev %d)", rev.Name, rev.Revision),
+			Size:     snapRevisionSize(rev),
+		})
+	}
+	return items
+}
+
+func (m *CleanupModule) cleanSnapRevisions(report func(message string)) (int64, error) {
+	removals, err := m.disabledSnapRevisions()
+	if err != nil {
+		return 0, err
+	}
+
+	var totalFreed int64
+	for _, rev := range removals {
+		size := snapRevisionSize(rev)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		cmd := exec.CommandContext(ctx, "snap", "remove", rev.Name, fmt.Sprintf("--revision=%d", rev.Revision))
+		err := cmd.Run()
+		cancel()
+		if err != nil {
+			report(fmt.Sprintf("%s (rev %d): removal failed: %v", rev.Name, rev.Revision, err))
+			continue
+		}
+
+		totalFreed += size
+		report(fmt.Sprintf("%s (rev %d): %d MB freed", rev.Name, rev.Revision, size/1024/1024))
+	}
+
+	return totalFreed, nil
+}
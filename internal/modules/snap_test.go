@@ -0,0 +1,77 @@
+package modules
+
+import (
+	"testing"
+)
+
+const sampleSnapList = `Name               Version          Rev    Tracking         Publisher   Notes
+core20             20230207         1822   latest/stable    canonical✓  base,disabled
+core20             20230308         1852   latest/stable    canonical✓  base
+firefox            110.0-3          2356   latest/stable/…  mozilla✓    disabled
+firefox            111.0-1          2391   latest/stable/…  mozilla✓    disabled
+firefox            112.0-2          2432   latest/stable/…  mozilla✓    -
+snapd              2.58.3           18357  latest/stable    canonical✓  snapd
+`
+
+func TestParseSnapList(t *testing.T) {
+	revisions := parseSnapList(sampleSnapList)
+	if len(revisions) != 6 {
+		t.Fatalf("Expected 6 revisions, got %d", len(revisions))
+	}
+
+	disabled := 0
+	for _, rev := range revisions {
+		if rev.Disabled {
+			disabled++
+		}
+	}
+	if disabled != 3 {
+		t.Errorf("Expected 3 disabled revisions, got %d", disabled)
+	}
+
+	if revisions[0].Name != "core20" || revisions[0].Revision != 1822 {
+		t.Errorf("Unexpected first revision: %+v", revisions[0])
+	}
+}
+
+func TestSelectSnapRemovals(t *testing.T) {
+	revisions := parseSnapList(sampleSnapList)
+
+	tests := []struct {
+		retain   int
+		expected []snapRevision
+	}{
+		{
+			retain: 0,
+			expected: []snapRevision{
+				{Name: "core20", Revision: 1822, Disabled: true},
+				{Name: "firefox", Revision: 2356, Disabled: true},
+				{Name: "firefox", Revision: 2391, Disabled: true},
+			},
+		},
+		{
+			// Оставляем самую новую отключенную ревизию для отката
+			retain: 1,
+			expected: []snapRevision{
+				{Name: "firefox", Revision: 2356, Disabled: true},
+			},
+		},
+		{
+			retain:   2,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		removals := selectSnapRemovals(revisions, tt.retain)
+		if len(removals) != len(tt.expected) {
+			t.Errorf("retain=%d: expected %d removals, got %v", tt.retain, len(tt.expected), removals)
+			continue
+		}
+		for i := range removals {
+			if removals[i] != tt.expected[i] {
+				t.Errorf("retain=%d: removal[%d] = %+v, want %+v", tt.retain, i, removals[i], tt.expected[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stateDir возвращает каталог истории запусков текущего пользователя:
+// $XDG_STATE_HOME/ububu
+func stateDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(envPath("XDG_STATE_HOME", filepath.Join(homeDir, ".local/state")), "ububu"), nil
+}
+
+// loadState читает JSON-файл истории в v. Отсутствующий файл - не ошибка,
+// v тогда остается нетронутым
+func loadState(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("corrupted %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// saveState записывает v в JSON-файл истории
+func saveState(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Пишем через временный файл, чтобы не потерять историю при сбое
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
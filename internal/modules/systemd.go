@@ -0,0 +1,255 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// unitRestartLoop - после стольких автоматических перезапусков служба
+	// считается зацикленной
+	unitRestartLoop = 3
+	// unitRestartRecent - служба с перезапусками считается зацикленной, только если
+	// последний раз запустилась недавно: старые перезапуски давно стабильной
+	// службы остаются в NRestarts до перезагрузки
+	unitRestartRecent = time.Hour
+	// timerGrace - насколько таймер может опоздать, прежде чем это считается проблемой
+	timerGrace = time.Hour
+	// unitLogLines - сколько последних строк журнала показывать для проблемного юнита
+	unitLogLines = 3
+)
+
+// Виды проблем с юнитами systemd
+const (
+	UnitFailed      = "failed"
+	UnitRestartLoop = "restart loop"
+	UnitMasked      = "masked"
+	UnitTimerLate   = "timer overdue"
+)
+
+// unitProperties - свойства, которые запрашиваются у systemctl show
+var unitProperties = []string{
+	"Id", "Description", "LoadState", "ActiveState", "SubState", "Result",
+	"NRestarts", "ActiveEnterTimestamp", "NextElapseUSecRealtime", "LastTriggerUSec", "Unit",
+}
+
+// UnitProblem - юнит systemd, требующий внимания
+type UnitProblem struct {
+	Unit        string
+	Description string
+	Kind        string
+	Detail      string
+	// Target - юнит, перезапуск которого решает проблему. Для опоздавшего
+	// таймера это запускаемая им служба: перезапуск самого таймера не
+	// выполняет пропущенную задачу
+	Target string
+	// Logs - последние строки журнала юнита
+	Logs []string
+}
+
+// Severity оценивает проблему: замаскированный юнит - обычно решение
+// администратора, остальное мешает работе системы
+func (p UnitProblem) Severity() Severity {
+	switch p.Kind {
+	case UnitMasked:
+		return SeverityInfo
+	case UnitFailed:
+		return SeverityCritical
+	default:
+		return SeverityWarning
+	}
+}
+
+// Finding описывает проблему для отчета о здоровье системы
+func (p UnitProblem) Finding() Finding {
+	message := fmt.Sprintf("%s %s", p.Unit, p.Kind)
+	if p.Detail != "" {
+		message += " (" + p.Detail + ")"
+	}
+	return Finding{Severity: p.Severity(), Message: message}
+}
+
+// Restartable возвращает true, если проблему может решить перезапуск Target
+func (p UnitProblem) Restartable() bool {
+	return p.Kind != UnitMasked && p.Target != ""
+}
+
+// timerService возвращает службу, которую запускает таймер: из свойства Unit
+// или, если его нет, одноименную службу
+func timerService(unit map[string]string) string {
+	if service := unit["Unit"]; service != "" {
+		return service
+	}
+	return strings.TrimSuffix(unit["Id"], ".timer") + ".service"
+}
+
+// parseSystemctlShow разбирает вывод systemctl show для нескольких юнитов:
+// блоки свойств key=value, разделенные пустыми строками
+func parseSystemctlShow(r io.Reader) []map[string]string {
+	var units []map[string]string
+	current := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(current) > 0 {
+				units = append(units, current)
+				current = make(map[string]string)
+			}
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			current[key] = value
+		}
+	}
+	if len(current) > 0 {
+		units = append(units, current)
+	}
+
+	return units
+}
+
+// parseSystemdTime разбирает время в формате systemctl show:
+// "Sat 2024-01-06 00:00:00 UTC" или "@1704499200" при --timestamp=unix
+func parseSystemdTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "n/a" || value == "0" {
+		return time.Time{}, false
+	}
+	if seconds, ok := strings.CutPrefix(value, "@"); ok {
+		unix, err := strconv.ParseInt(seconds, 10, 64)
+		return time.Unix(unix, 0), err == nil
+	}
+	for _, layout := range []string{"Mon 2006-01-02 15:04:05 MST", "Mon 2006-01-02 15:04:05"} {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// recentlyStarted сообщает, что юнит перешел в активное состояние
+// меньше unitRestartRecent назад
+func recentlyStarted(unit map[string]string, now time.Time) bool {
+	entered, ok := parseSystemdTime(unit["ActiveEnterTimestamp"])
+	return ok && now.Sub(entered) < unitRestartRecent
+}
+
+// unitProblems находит среди юнитов упавшие, зацикленные, замаскированные
+// и опоздавшие таймеры
+func unitProblems(units []map[string]string, now time.Time) []UnitProblem {
+	var problems []UnitProblem
+	for _, unit := range units {
+		problem := UnitProblem{Unit: unit["Id"], Description: unit["Description"], Target: unit["Id"]}
+		restarts, _ := strconv.Atoi(unit["NRestarts"])
+
+		switch {
+		case unit["LoadState"] == "masked":
+			problem.Kind = UnitMasked
+		case unit["ActiveState"] == "failed":
+			problem.Kind = UnitFailed
+			if result := unit["Result"]; result != "" && result != "success" {
+				problem.Detail = "result: " + result
+			}
+		case restarts >= unitRestartLoop && (unit["SubState"] == "auto-restart" || recentlyStarted(unit, now)):
+			problem.Kind = UnitRestartLoop
+			problem.Detail = fmt.Sprintf("restarted %d times", restarts)
+		case strings.HasSuffix(unit["Id"], ".timer") && unit["ActiveState"] == "active":
+			next, ok := parseSystemdTime(unit["NextElapseUSecRealtime"])
+			if !ok || now.Sub(next) < timerGrace {
+				continue
+			}
+			problem.Kind = UnitTimerLate
+			problem.Target = timerService(unit)
+			problem.Detail = "was due " + next.Format("2006-01-02 15:04")
+			if last, ok := parseSystemdTime(unit["LastTriggerUSec"]); ok {
+				problem.Detail += ", last fired " + last.Format("2006-01-02 15:04")
+			}
+		default:
+			continue
+		}
+		problems = append(problems, problem)
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Severity() != problems[j].Severity() {
+			return problems[i].Severity() > problems[j].Severity()
+		}
+		return problems[i].Unit < problems[j].Unit
+	})
+	return problems
+}
+
+// UnitLogs возвращает последние строки журнала юнита
+func UnitLogs(unit string, lines int) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "journalctl", "-u", unit, "-n", strconv.Itoa(lines),
+		"--no-pager", "-o", "cat").Output()
+	if err != nil {
+		return nil
+	}
+	var logs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "-- ") {
+			logs = append(logs, line)
+		}
+	}
+	return logs
+}
+
+// CheckUnits опрашивает systemd и возвращает проблемные юниты с выдержками из журнала
+func CheckUnits() ([]UnitProblem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	args := []string{"show", "--all", "--property=" + strings.Join(unitProperties, ","), "*"}
+	output, err := exec.CommandContext(ctx, "systemctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl failed: %v", err)
+	}
+
+	problems := unitProblems(parseSystemctlShow(strings.NewReader(string(output))), time.Now())
+	for i := range problems {
+		if problems[i].Kind != UnitMasked {
+			problems[i].Logs = UnitLogs(problems[i].Unit, unitLogLines)
+		}
+	}
+	return problems, nil
+}
+
+// RestartUnit перезапускает юнит и сбрасывает у него состояние failed.
+// Без прав root пробует sudo без запроса пароля
+func RestartUnit(unit string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	run := func(args ...string) error {
+		cmd := exec.CommandContext(ctx, "systemctl", args...)
+		if os.Geteuid() != 0 {
+			cmd = exec.CommandContext(ctx, "sudo", append([]string{"-n", "systemctl"}, args...)...)
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			if message := strings.TrimSpace(string(output)); message != "" {
+				return fmt.Errorf("%s", message)
+			}
+			return err
+		}
+		return nil
+	}
+
+	// Без reset-failed systemd может отказать в запуске после серии падений
+	run("reset-failed", unit)
+	return run("restart", unit)
+}
@@ -0,0 +1,134 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSystemctlShow = `Id=nginx.service
+Description=A high performance web server
+LoadState=loaded
+ActiveState=failed
+SubState=failed
+Result=exit-code
+NRestarts=0
+
+Id=flaky.service
+Description=Flaky worker
+LoadState=loaded
+ActiveState=activating
+SubState=auto-restart
+Result=exit-code
+NRestarts=7
+
+Id=bluetooth.service
+Description=bluetooth.service
+LoadState=masked
+ActiveState=inactive
+SubState=dead
+NRestarts=0
+
+Id=backup.timer
+Description=Nightly backup
+LoadState=loaded
+ActiveState=active
+SubState=waiting
+NextElapseUSecRealtime=@1704067200
+LastTriggerUSec=@1703980800
+Unit=restic-backup.service
+
+Id=fstrim.timer
+Description=Discard unused blocks once a week
+LoadState=loaded
+ActiveState=active
+SubState=waiting
+NextElapseUSecRealtime=@1704240000
+LastTriggerUSec=n/a
+
+Id=cron.service
+Description=Regular background program processing daemon
+LoadState=loaded
+ActiveState=active
+SubState=running
+NRestarts=1
+
+Id=postgresql.service
+Description=PostgreSQL database server
+LoadState=loaded
+ActiveState=active
+SubState=running
+NRestarts=4
+ActiveEnterTimestamp=@1703548800
+
+Id=worker.service
+Description=Queue worker
+LoadState=loaded
+ActiveState=active
+SubState=running
+NRestarts=5
+ActiveEnterTimestamp=@1704153000
+`
+
+func TestParseSystemctlShow(t *testing.T) {
+	units := parseSystemctlShow(strings.NewReader(testSystemctlShow))
+	if len(units) != 8 {
+		t.Fatalf("parseSystemctlShow() returned %d units, want 8", len(units))
+	}
+	if units[0]["Id"] != "nginx.service" || units[1]["NRestarts"] != "7" {
+		t.Errorf("Unexpected units: %v, %v", units[0], units[1])
+	}
+}
+
+func TestUnitProblems(t *testing.T) {
+	units := parseSystemctlShow(strings.NewReader(testSystemctlShow))
+	// 2024-01-02 00:00 UTC: backup.timer просрочен на сутки, fstrim.timer еще впереди.
+	// postgresql.service перезапускался, но работает уже неделю, а worker.service
+	// снова запустился 10 минут назад
+	now := time.Unix(1704153600, 0)
+
+	problems := unitProblems(units, now)
+	var got []string
+	for _, problem := range problems {
+		got = append(got, problem.Unit+" "+problem.Kind)
+	}
+	want := "nginx.service failed, backup.timer timer overdue, flaky.service restart loop, worker.service restart loop, bluetooth.service masked"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("unitProblems() = %q, want %q", strings.Join(got, ", "), want)
+	}
+
+	if finding := problems[0].Finding(); finding.Severity != SeverityCritical || !strings.Contains(finding.Message, "result: exit-code") {
+		t.Errorf("Failed unit finding = %+v", finding)
+	}
+	if !strings.Contains(problems[2].Detail, "restarted 7 times") {
+		t.Errorf("Restart loop detail = %q", problems[2].Detail)
+	}
+	if problems[4].Restartable() || problems[4].Severity() != SeverityInfo {
+		t.Error("Masked unit should be informational and not restartable")
+	}
+	// Опоздавший таймер решается запуском его службы, остальное - перезапуском самого юнита
+	if !problems[1].Restartable() || problems[1].Target != "restic-backup.service" {
+		t.Errorf("Overdue timer target = %q, want restic-backup.service", problems[1].Target)
+	}
+	if problems[0].Target != "nginx.service" {
+		t.Errorf("Failed unit target = %q, want nginx.service", problems[0].Target)
+	}
+	if service := timerService(map[string]string{"Id": "logrotate.timer"}); service != "logrotate.service" {
+		t.Errorf("timerService() without Unit = %q, want logrotate.service", service)
+	}
+}
+
+func TestParseSystemdTime(t *testing.T) {
+	if parsed, ok := parseSystemdTime("@1704067200"); !ok || parsed.Unix() != 1704067200 {
+		t.Errorf("parseSystemdTime(@unix) = %v, %v", parsed, ok)
+	}
+	local := time.Date(2024, 1, 6, 3, 15, 0, 0, time.Local)
+	if parsed, ok := parseSystemdTime(local.Format("Mon 2006-01-02 15:04:05 MST")); !ok || !parsed.Equal(local) {
+		t.Errorf("parseSystemdTime(formatted) = %v, %v; want %v", parsed, ok, local)
+	}
+	for _, value := range []string{"", "n/a", "0"} {
+		if _, ok := parseSystemdTime(value); ok {
+			t.Errorf("parseSystemdTime(%q) should fail", value)
+		}
+	}
+}
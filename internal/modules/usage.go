@@ -0,0 +1,183 @@
+package modules
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CleanupResult - итог одной категории очистки
+type CleanupResult struct {
+	Category string
+	// User - чьи файлы очищались (пусто для общесистемных шагов)
+	User string
+	// Deleted - объем удаленных данных по занятым блокам
+	Deleted int64
+	// Reclaimed - насколько на самом деле выросло свободное место на файловых
+	// системах. Может быть меньше Deleted, если файлы держат открытыми или на
+	// них остались жесткие ссылки
+	Reclaimed int64
+}
+
+// inodeKey однозначно определяет файл в системе
+type inodeKey struct {
+	dev uint64
+	ino uint64
+}
+
+// measurePath считает объем дерева без кэширования. Жесткие ссылки учитываются
+// один раз, разреженные файлы - по занятым блокам, а не по видимому размеру
+func measurePath(path string) DiskUsage {
+	usage, _ := NewScanner().Scan(context.Background(), path)
+	return usage
+}
+
+// removeMeasured удаляет path и возвращает, сколько занятого места исчезло.
+// Размер до удаления берется из кэша сканера, если предпросмотр уже его посчитал.
+// Если recreate, каталог создается заново пустым
+func (m *CleanupModule) removeMeasured(path string, recreate bool) int64 {
+	before, _ := m.getDirSize(path)
+	if before == 0 {
+		return 0
+	}
+
+	original, statErr := os.Lstat(path)
+	os.RemoveAll(path)
+	if recreate {
+		os.MkdirAll(path, 0755)
+		if statErr == nil {
+			restoreOwner(path, original)
+		}
+	}
+	m.diskScanner().Invalidate(path)
+
+	// Часть файлов могла не удалиться из-за прав доступа
+	after, _ := m.getDirSize(path)
+	return before - after
+}
+
+// pruneByAccessTime удаляет файлы в root, к которым не обращались дольше maxAge,
+// и возвращает освобожденный объем
+func pruneByAccessTime(root string, maxAge time.Duration) int64 {
+	cutoff := time.Now().Add(-maxAge)
+	var freed int64
+
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return nil
+		}
+		if time.Unix(stat.Atim.Unix()).After(cutoff) {
+			return nil
+		}
+		if os.Remove(path) == nil {
+			freed += stat.Blocks * 512
+		}
+		return nil
+	})
+
+	return freed
+}
+
+// fsSnapshot - свободное место на каждой файловой системе
+type fsSnapshot map[uint64]int64
+
+// pseudoFilesystems не хранят пользовательских данных на диске
+var pseudoFilesystems = map[string]bool{
+	"proc": true, "sysfs": true, "devtmpfs": true, "devpts": true, "cgroup": true,
+	"cgroup2": true, "securityfs": true, "debugfs": true, "tracefs": true, "pstore": true,
+	"bpf": true, "configfs": true, "fusectl": true, "mqueue": true, "hugetlbfs": true,
+	"autofs": true, "binfmt_misc": true, "efivarfs": true, "nsfs": true, "squashfs": true,
+	"rpc_pipefs": true, "overlay": true,
+}
+
+// takeFSSnapshot запоминает свободное место на всех смонтированных файловых
+// системах. Одна файловая система, смонтированная несколько раз, учитывается один раз
+func takeFSSnapshot() fsSnapshot {
+	snapshot := make(fsSnapshot)
+
+	file, err := os.Open("/proc/self/mounts")
+	if err != nil {
+		return snapshot
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || pseudoFilesystems[fields[2]] {
+			continue
+		}
+
+		mountPoint := unescapeMountPath(fields[1])
+		info, err := os.Stat(mountPoint)
+		if err != nil {
+			continue
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			continue
+		}
+		dev := uint64(stat.Dev)
+		if _, ok := snapshot[dev]; ok {
+			continue
+		}
+
+		var fs syscall.Statfs_t
+		if err := syscall.Statfs(mountPoint, &fs); err != nil {
+			continue
+		}
+		snapshot[dev] = int64(fs.Bfree) * int64(fs.Bsize)
+	}
+
+	return snapshot
+}
+
+// reclaimedSince возвращает, насколько выросло свободное место с момента снимка.
+// Файловые системы, где место уменьшилось из-за посторонней записи, не учитываются
+func (before fsSnapshot) reclaimedSince(after fsSnapshot) int64 {
+	var reclaimed int64
+	for dev, free := range after {
+		if prev, ok := before[dev]; ok && free > prev {
+			reclaimed += free - prev
+		}
+	}
+	return reclaimed
+}
+
+// unescapeMountPath раскодирует пробелы и спецсимволы (\040 и т.п.) в путях из /proc/*/mounts
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			code := path[i+1 : i+4]
+			var value byte
+			valid := true
+			for _, c := range code {
+				if c < '0' || c > '7' {
+					valid = false
+					break
+				}
+				value = value*8 + byte(c-'0')
+			}
+			if valid {
+				b.WriteByte(value)
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,155 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func allocatedSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+	return info.Sys().(*syscall.Stat_t).Blocks * 512
+}
+
+func TestMeasurePath_Hardlinks(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_usage_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	original := filepath.Join(tempDir, "original.bin")
+	if err := os.WriteFile(original, make([]byte, 64*1024), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.Link(original, filepath.Join(tempDir, "link.bin")); err != nil {
+		t.Skipf("Hardlinks not supported: %v", err)
+	}
+
+	usage := measurePath(tempDir)
+
+	// Жесткая ссылка не должна удваивать размер
+	if usage.Apparent != 64*1024 {
+		t.Errorf("Apparent = %d, want %d", usage.Apparent, 64*1024)
+	}
+	if expected := allocatedSize(t, original); usage.Allocated != expected {
+		t.Errorf("Allocated = %d, want %d", usage.Allocated, expected)
+	}
+}
+
+func TestMeasurePath_SparseFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_usage_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	sparse := filepath.Join(tempDir, "sparse.img")
+	file, err := os.Create(sparse)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	file.Truncate(100 * 1024 * 1024)
+	file.Close()
+
+	usage := measurePath(tempDir)
+	if usage.Apparent != 100*1024*1024 {
+		t.Errorf("Apparent = %d, want %d", usage.Apparent, 100*1024*1024)
+	}
+	// Пустой разреженный файл почти не занимает места на диске
+	if usage.Allocated >= usage.Apparent {
+		t.Errorf("Allocated (%d) should be less than apparent size (%d) for a sparse file", usage.Allocated, usage.Apparent)
+	}
+}
+
+func TestRemoveMeasured(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_usage_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cacheDir := filepath.Join(tempDir, "cache")
+	os.MkdirAll(cacheDir, 0755)
+	file := filepath.Join(cacheDir, "data.bin")
+	os.WriteFile(file, make([]byte, 10000), 0644)
+	expected := allocatedSize(t, file)
+
+	module := &CleanupModule{}
+	freed := module.removeMeasured(cacheDir, true)
+	if freed != expected {
+		t.Errorf("removeMeasured() = %d, want %d", freed, expected)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("File should have been deleted")
+	}
+	if info, err := os.Stat(cacheDir); err != nil || !info.IsDir() {
+		t.Error("Directory should have been recreated")
+	}
+
+	if freed := module.removeMeasured(filepath.Join(tempDir, "missing"), false); freed != 0 {
+		t.Errorf("removeMeasured() for missing path = %d, want 0", freed)
+	}
+}
+
+func TestPruneByAccessTime(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "ububu_usage_test")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	oldFile := filepath.Join(tempDir, "old.tmp")
+	newFile := filepath.Join(tempDir, "new.tmp")
+	os.WriteFile(oldFile, make([]byte, 5000), 0644)
+	os.WriteFile(newFile, make([]byte, 5000), 0644)
+	expected := allocatedSize(t, oldFile)
+
+	past := time.Now().Add(-10 * 24 * time.Hour)
+	os.Chtimes(oldFile, past, past)
+
+	freed := pruneByAccessTime(tempDir, 7*24*time.Hour)
+	if freed != expected {
+		t.Errorf("pruneByAccessTime() = %d, want %d", freed, expected)
+	}
+	if _, err := os.Stat(oldFile); !os.IsNotExist(err) {
+		t.Error("Old file should have been deleted")
+	}
+	if _, err := os.Stat(newFile); err != nil {
+		t.Error("Recently accessed file should have been kept")
+	}
+}
+
+func TestFSSnapshot_ReclaimedSince(t *testing.T) {
+	before := fsSnapshot{1: 1000, 2: 5000, 3: 700}
+	after := fsSnapshot{1: 1500, 2: 4000, 4: 100}
+
+	// Рост на первой ФС учитывается, падение на второй и новые/пропавшие ФС - нет
+	if got := before.reclaimedSince(after); got != 500 {
+		t.Errorf("reclaimedSince() = %d, want 500", got)
+	}
+
+	if snapshot := takeFSSnapshot(); len(snapshot) == 0 {
+		t.Log("No filesystems found in /proc/self/mounts")
+	}
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	tests := map[string]string{
+		"/":                      "/",
+		`/media/user/My\040Disk`: "/media/user/My Disk",
+		`/mnt/tab\011name`:       "/mnt/tab\tname",
+		`/mnt/not\escape`:        `/mnt/not\escape`,
+	}
+	for input, expected := range tests {
+		if got := unescapeMountPath(input); got != expected {
+			t.Errorf("unescapeMountPath(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package modules
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	passwdPath        = "/etc/passwd"
+	defaultMinUserUID = 1000
+	defaultMaxUserUID = 59999
+)
+
+// SystemUser - пользователь, чьи файлы очищаются
+type SystemUser struct {
+	Name string
+	UID  int
+	GID  int
+	Home string
+}
+
+// parsePasswd возвращает обычных пользователей из /etc/passwd: UID в диапазоне
+// [minUID, maxUID] и оболочка, под которой можно войти
+func parsePasswd(r io.Reader, minUID, maxUID int) []SystemUser {
+	var users []SystemUser
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// name:password:uid:gid:gecos:home:shell
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil || uid < minUID || uid > maxUID {
+			continue
+		}
+		gid, err := strconv.Atoi(fields[3])
+		if err != nil {
+			continue
+		}
+		shell := filepath.Base(fields[6])
+		if shell == "nologin" || shell == "false" || fields[5] == "" {
+			continue
+		}
+
+		users = append(users, SystemUser{Name: fields[0], UID: uid, GID: gid, Home: fields[5]})
+	}
+
+	return users
+}
+
+// currentUser возвращает пользователя, от имени которого запущен ububu
+func currentUser() (SystemUser, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return SystemUser{}, err
+	}
+
+	current := SystemUser{Name: strconv.Itoa(os.Getuid()), UID: os.Getuid(), GID: os.Getgid(), Home: homeDir}
+	if info, err := user.Current(); err == nil {
+		current.Name = info.Username
+	}
+	return current, nil
+}
+
+func (m *CleanupModule) uidRange() (int, int) {
+	minUID, maxUID := defaultMinUserUID, defaultMaxUserUID
+	if m.MinUserUID > 0 {
+		minUID = m.MinUserUID
+	}
+	if m.MaxUserUID > 0 {
+		maxUID = m.MaxUserUID
+	}
+	return minUID, maxUID
+}
+
+// cleanupUsers возвращает пользователей для очистки. Без прав root это только
+// текущий пользователь. От root - он сам и все обычные пользователи системы,
+// иначе очистка трогала бы только /root
+func (m *CleanupModule) cleanupUsers() ([]SystemUser, error) {
+	current, err := currentUser()
+	if err != nil {
+		return nil, err
+	}
+	if os.Geteuid() != 0 || m.CurrentUserOnly {
+		return []SystemUser{current}, nil
+	}
+
+	file, err := os.Open(passwdPath)
+	if err != nil {
+		return []SystemUser{current}, nil
+	}
+	defer file.Close()
+
+	users := []SystemUser{current}
+	seen := map[string]bool{filepath.Clean(current.Home): true}
+	minUID, maxUID := m.uidRange()
+	for _, candidate := range parsePasswd(file, minUID, maxUID) {
+		home := filepath.Clean(candidate.Home)
+		if seen[home] {
+			continue
+		}
+		// Домашний каталог должен существовать и принадлежать пользователю
+		if owner, ok := ownerOf(home); !ok || owner != candidate.UID {
+			continue
+		}
+		seen[home] = true
+		users = append(users, candidate)
+	}
+	return users, nil
+}
+
+// ownerOf возвращает UID владельца path
+func ownerOf(path string) (int, bool) {
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return 0, false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return int(stat.Uid), true
+}
+
+// isCurrentHome возвращает true, если homeDir - домашний каталог
+// пользователя, запустившего ububu
+func isCurrentHome(homeDir string) bool {
+	current, err := os.UserHomeDir()
+	return err == nil && filepath.Clean(current) == filepath.Clean(homeDir)
+}
+
+// homeEnvPath работает как envPath, но переменные окружения процесса
+// относятся только к текущему пользователю, а не к чужим домашним каталогам
+func homeEnvPath(homeDir, key, fallback string) string {
+	if isCurrentHome(homeDir) {
+		return envPath(key, fallback)
+	}
+	return fallback
+}
+
+// runAs настраивает команду на запуск от имени пользователя, если это не
+// текущий пользователь, чтобы инструменты чистили его кэши, а не кэши root
+func runAs(cmd *exec.Cmd, account SystemUser) {
+	if account.UID == os.Geteuid() {
+		return
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(account.UID), Gid: uint32(account.GID)},
+	}
+	cmd.Dir = account.Home
+	cmd.Env = []string{
+		"HOME=" + account.Home,
+		"USER=" + account.Name,
+		"LOGNAME=" + account.Name,
+		"PATH=" + os.Getenv("PATH"),
+	}
+}
+
+// restoreOwner возвращает заново созданному каталогу владельца и права,
+// которые были у удаленного. Без этого от root в домах пользователей
+// появлялись бы каталоги, принадлежащие root
+func restoreOwner(path string, original os.FileInfo) {
+	os.Chmod(path, original.Mode().Perm())
+	if stat, ok := original.Sys().(*syscall.Stat_t); ok {
+		os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	}
+}
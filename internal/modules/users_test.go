@@ -0,0 +1,86 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePasswd(t *testing.T) {
+	passwd := `root:x:0:0:root:/root:/bin/bash
+daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin
+# comment
+alice:x:1000:1000:Alice,,,:/home/alice:/bin/bash
+bob:x:1001:1001::/home/bob:/usr/bin/zsh
+svc:x:1002:1002::/srv/svc:/bin/false
+nobody:x:65534:65534:nobody:/nonexistent:/usr/sbin/nologin
+broken:x:abc:1000::/home/broken:/bin/sh
+`
+	users := parsePasswd(strings.NewReader(passwd), 1000, 59999)
+	if len(users) != 2 {
+		t.Fatalf("parsePasswd() = %+v, want alice and bob", users)
+	}
+	if users[0] != (SystemUser{Name: "alice", UID: 1000, GID: 1000, Home: "/home/alice"}) {
+		t.Errorf("Unexpected first user: %+v", users[0])
+	}
+	if users[1].Name != "bob" || users[1].Home != "/home/bob" {
+		t.Errorf("Unexpected second user: %+v", users[1])
+	}
+
+	if users := parsePasswd(strings.NewReader(passwd), 1001, 1001); len(users) != 1 || users[0].Name != "bob" {
+		t.Errorf("parsePasswd() with narrow range = %+v, want only bob", users)
+	}
+}
+
+func TestCleanupModule_CleanupUsers_CurrentUserOnly(t *testing.T) {
+	module := &CleanupModule{CurrentUserOnly: true}
+	users, err := module.cleanupUsers()
+	if err != nil {
+		t.Fatalf("cleanupUsers() returned error: %v", err)
+	}
+	homeDir, _ := os.UserHomeDir()
+	if len(users) != 1 || users[0].Home != homeDir || users[0].UID != os.Getuid() {
+		t.Errorf("cleanupUsers() = %+v, want only the current user", users)
+	}
+}
+
+func TestHomeEnvPath(t *testing.T) {
+	original := os.Getenv("UBUBU_TEST_CACHE")
+	os.Setenv("UBUBU_TEST_CACHE", "/custom/cache")
+	defer os.Setenv("UBUBU_TEST_CACHE", original)
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	if got := homeEnvPath(homeDir, "UBUBU_TEST_CACHE", "/fallback"); got != "/custom/cache" {
+		t.Errorf("homeEnvPath() for current home = %q, want /custom/cache", got)
+	}
+	if got := homeEnvPath("/home/someone-else", "UBUBU_TEST_CACHE", "/fallback"); got != "/fallback" {
+		t.Errorf("homeEnvPath() for another home = %q, want /fallback", got)
+	}
+}
+
+func TestRemoveMeasured_KeepsMode(t *testing.T) {
+	tempDir := t.TempDir()
+	cacheDir := filepath.Join(tempDir, "cache")
+	os.MkdirAll(cacheDir, 0700)
+	os.WriteFile(filepath.Join(cacheDir, "blob"), make([]byte, 8192), 0644)
+
+	module := &CleanupModule{}
+	if freed := module.removeMeasured(cacheDir, true); freed == 0 {
+		t.Error("removeMeasured() should report freed space")
+	}
+
+	info, err := os.Stat(cacheDir)
+	if err != nil {
+		t.Fatalf("Directory should have been recreated: %v", err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("Recreated directory mode = %v, want 0700", info.Mode().Perm())
+	}
+	if owner, ok := ownerOf(cacheDir); !ok || owner != os.Getuid() {
+		t.Errorf("Recreated directory owner = %d, want %d", owner, os.Getuid())
+	}
+}